@@ -0,0 +1,17 @@
+package auth
+
+import (
+	"context"
+	"microd-api/internal/models"
+)
+
+type contextKey struct{}
+
+func WithUser(ctx context.Context, user models.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+func UserFromContext(ctx context.Context) (models.User, bool) {
+	user, ok := ctx.Value(contextKey{}).(models.User)
+	return user, ok
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"microd-api/internal/utils"
+	"net/http"
+	"strings"
+)
+
+var errMissingToken error = utils.NewError(utils.ErrUnauthorized, "missing bearer token")
+
+type UserLoader interface {
+	GetUserByID(ctx context.Context, id int64) (models.User, error)
+}
+
+// Middleware authenticates requests carrying a bearer access token and stores
+// the token's user in the request context. The user is reloaded on every
+// request so that role changes take effect before the token expires.
+func Middleware(tokens *TokenManager, users UserLoader) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			scheme, token, ok := strings.Cut(header, " ")
+			if !ok || !strings.EqualFold(scheme, "Bearer") || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				utils.RespondWithProblem(w, r, errMissingToken)
+				return
+			}
+
+			claims, err := tokens.ParseAccessToken(strings.TrimSpace(token))
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				utils.RespondWithProblem(w, r, ErrInvalidToken)
+				return
+			}
+
+			userID, _ := claims.UserID()
+			user, err := users.GetUserByID(r.Context(), userID)
+			if errors.Is(err, repository.ErrNotFound) {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				utils.RespondWithProblem(w, r, ErrInvalidToken)
+				return
+			}
+			if err != nil {
+				utils.RespondWithProblem(w, r, fmt.Errorf("error loading user %d: %w", userID, err))
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+		})
+	}
+}
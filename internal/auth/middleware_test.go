@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type stubUserLoader map[int64]models.User
+
+// brokenUserID makes stubUserLoader fail as if the database were unavailable.
+const brokenUserID = 3
+
+func (s stubUserLoader) GetUserByID(ctx context.Context, id int64) (models.User, error) {
+	if id == brokenUserID {
+		return models.User{}, errors.New("database is locked")
+	}
+	user, ok := s[id]
+	if !ok {
+		return models.User{}, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	manager := NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	users := stubUserLoader{1: {ID: 1, Name: "Ada", Role: models.RoleAdmin}}
+
+	handler := Middleware(manager, users)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, ok := UserFromContext(r.Context())
+		if !ok {
+			t.Error("expected user in request context")
+		}
+		w.Write([]byte(user.Name))
+	}))
+
+	valid, _ := manager.IssueTokens(models.User{ID: 1})
+	unknown, _ := manager.IssueTokens(models.User{ID: 2})
+	broken, _ := manager.IssueTokens(models.User{ID: brokenUserID})
+
+	tests := []struct {
+		name   string
+		header string
+		code   int
+	}{
+		{"ValidToken", "Bearer " + valid.AccessToken, http.StatusOK},
+		{"LowercaseScheme", "bearer " + valid.AccessToken, http.StatusOK},
+		{"MissingHeader", "", http.StatusUnauthorized},
+		{"WrongScheme", "Basic " + valid.AccessToken, http.StatusUnauthorized},
+		{"RefreshToken", "Bearer " + valid.RefreshToken, http.StatusUnauthorized},
+		{"UnknownUser", "Bearer " + unknown.AccessToken, http.StatusUnauthorized},
+		{"LoaderFailure", "Bearer " + broken.AccessToken, http.StatusInternalServerError},
+		{"Garbage", "Bearer garbage", http.StatusUnauthorized},
+	}
+
+	t.Run("InvalidTokenChallenge", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", "Bearer garbage")
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, req)
+		if got := rr.Header().Get("WWW-Authenticate"); got != `Bearer error="invalid_token"` {
+			t.Errorf("unexpected WWW-Authenticate header: %q", got)
+		}
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.code {
+				t.Errorf("expected status code %d, got %d", tt.code, rr.Code)
+			}
+			if tt.code == http.StatusOK && rr.Body.String() != "Ada" {
+				t.Errorf("expected handler to see user 'Ada', got %q", rr.Body.String())
+			}
+			if tt.code == http.StatusUnauthorized && rr.Header().Get("WWW-Authenticate") == "" {
+				t.Errorf("expected WWW-Authenticate header on 401")
+			}
+			if tt.code == http.StatusInternalServerError && rr.Header().Get("WWW-Authenticate") != "" {
+				t.Errorf("expected no WWW-Authenticate header when loading the user fails")
+			}
+			if tt.code != http.StatusOK && rr.Header().Get("Content-Type") != "application/problem+json" {
+				t.Errorf("expected a problem+json %d, got %q", tt.code, rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"microd-api/internal/utils"
+)
+
+var (
+	ErrUnauthenticated = utils.ErrUnauthorized
+	ErrForbidden       = utils.ErrForbidden
+)
+
+func RequireUser(ctx context.Context) (models.User, error) {
+	user, ok := UserFromContext(ctx)
+	if !ok {
+		return models.User{}, ErrUnauthenticated
+	}
+	return user, nil
+}
+
+func RequireAdmin(ctx context.Context) error {
+	user, err := RequireUser(ctx)
+	if err != nil {
+		return err
+	}
+	if !user.IsAdmin() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// CanManageAPI reports whether user may create, modify or delete api: admins
+// can manage every entry, everyone else only the entries owned by their team.
+func CanManageAPI(user models.User, api models.API) bool {
+	if user.IsAdmin() {
+		return true
+	}
+	return user.TeamID != 0 && user.TeamID == api.TeamID
+}
+
+// AuthorizeAPIWrite checks that the user in ctx may manage every one of apis,
+// which lets callers pass both the stored and the incoming version of an
+// entry so that moving an API between teams requires membership of both.
+func AuthorizeAPIWrite(ctx context.Context, apis ...models.API) error {
+	user, err := RequireUser(ctx)
+	if err != nil {
+		return err
+	}
+	for _, api := range apis {
+		if !CanManageAPI(user, api) {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"testing"
+)
+
+func TestCanManageAPI(t *testing.T) {
+	tests := []struct {
+		name string
+		user models.User
+		api  models.API
+		want bool
+	}{
+		{"Admin", models.User{Role: models.RoleAdmin}, models.API{TeamID: 1}, true},
+		{"AdminWithoutTeam", models.User{Role: models.RoleAdmin}, models.API{}, true},
+		{"TeamMember", models.User{TeamID: 1}, models.API{TeamID: 1}, true},
+		{"OtherTeam", models.User{TeamID: 2}, models.API{TeamID: 1}, false},
+		{"NoTeam", models.User{}, models.API{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CanManageAPI(tt.user, tt.api); got != tt.want {
+				t.Errorf("CanManageAPI() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeAPIWrite(t *testing.T) {
+	ctx := context.Background()
+	member := WithUser(ctx, models.User{ID: 1, TeamID: 1})
+
+	if err := AuthorizeAPIWrite(ctx, models.API{TeamID: 1}); err != ErrUnauthenticated {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+	if err := AuthorizeAPIWrite(member, models.API{TeamID: 1}); err != nil {
+		t.Errorf("expected member to be authorized, got %v", err)
+	}
+	if err := AuthorizeAPIWrite(member, models.API{TeamID: 1}, models.API{TeamID: 2}); err != ErrForbidden {
+		t.Errorf("expected ErrForbidden when any API belongs to another team, got %v", err)
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	ctx := context.Background()
+
+	if err := RequireAdmin(ctx); err != ErrUnauthenticated {
+		t.Errorf("expected ErrUnauthenticated, got %v", err)
+	}
+	if err := RequireAdmin(WithUser(ctx, models.User{ID: 1})); err != ErrForbidden {
+		t.Errorf("expected ErrForbidden, got %v", err)
+	}
+	if err := RequireAdmin(WithUser(ctx, models.User{ID: 1, Role: models.RoleAdmin})); err != nil {
+		t.Errorf("expected admin to pass, got %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"microd-api/internal/models"
+	"microd-api/internal/utils"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	accessTokenType  = "access"
+	refreshTokenType = "refresh"
+)
+
+var ErrInvalidToken error = utils.NewError(utils.ErrUnauthorized, "invalid or expired token")
+
+type TokenPair struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type Claims struct {
+	Type string `json:"typ"`
+	Role int64  `json:"role"`
+	jwt.RegisteredClaims
+}
+
+func (c Claims) UserID() (int64, error) {
+	return strconv.ParseInt(c.Subject, 10, 64)
+}
+
+type TokenManager struct {
+	secret     []byte
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+func NewTokenManager(secret []byte, accessTTL, refreshTTL time.Duration) *TokenManager {
+	return &TokenManager{
+		secret:     secret,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+		now:        time.Now,
+	}
+}
+
+// IssueTokens signs a short-lived access token and a long-lived refresh token
+// for user. Every refresh token carries a random ID so that rotating it always
+// yields a different value, even within the same second.
+func (m *TokenManager) IssueTokens(user models.User) (TokenPair, error) {
+	access, err := m.sign(user, accessTokenType, m.accessTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	refresh, err := m.sign(user, refreshTokenType, m.refreshTTL)
+	if err != nil {
+		return TokenPair{}, err
+	}
+
+	return TokenPair{
+		AccessToken:  access,
+		RefreshToken: refresh,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(m.accessTTL.Seconds()),
+	}, nil
+}
+
+func (m *TokenManager) ParseAccessToken(token string) (Claims, error) {
+	return m.parse(token, accessTokenType)
+}
+
+func (m *TokenManager) ParseRefreshToken(token string) (Claims, error) {
+	return m.parse(token, refreshTokenType)
+}
+
+func (m *TokenManager) sign(user models.User, tokenType string, ttl time.Duration) (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("generating token ID: %w", err)
+	}
+
+	now := m.now()
+	claims := Claims{
+		Type: tokenType,
+		Role: user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(user.ID, 10),
+			ID:        hex.EncodeToString(id),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(m.secret)
+}
+
+func (m *TokenManager) parse(token, tokenType string) (Claims, error) {
+	var claims Claims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		return m.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithTimeFunc(m.now),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil || claims.Type != tokenType {
+		return Claims{}, ErrInvalidToken
+	}
+	if _, err := claims.UserID(); err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	return claims, nil
+}
+
+// HashToken returns the digest stored in place of a refresh token, so a leaked
+// database does not hand out usable sessions.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"microd-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestTokenManager(t *testing.T) {
+	manager := NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	user := models.User{ID: 42, Role: models.RoleAdmin}
+
+	t.Run("IssueAndParse", func(t *testing.T) {
+		tokens, err := manager.IssueTokens(user)
+		if err != nil {
+			t.Fatalf("IssueTokens() error = %v", err)
+		}
+		if tokens.TokenType != "Bearer" || tokens.ExpiresIn != 60 {
+			t.Errorf("unexpected token metadata: %+v", tokens)
+		}
+
+		claims, err := manager.ParseAccessToken(tokens.AccessToken)
+		if err != nil {
+			t.Fatalf("ParseAccessToken() error = %v", err)
+		}
+		if id, _ := claims.UserID(); id != 42 {
+			t.Errorf("expected user ID 42, got %d", id)
+		}
+		if claims.Role != models.RoleAdmin {
+			t.Errorf("expected admin role claim, got %d", claims.Role)
+		}
+
+		if _, err := manager.ParseRefreshToken(tokens.RefreshToken); err != nil {
+			t.Errorf("ParseRefreshToken() error = %v", err)
+		}
+	})
+
+	t.Run("TokenTypesAreNotInterchangeable", func(t *testing.T) {
+		tokens, _ := manager.IssueTokens(user)
+		if _, err := manager.ParseAccessToken(tokens.RefreshToken); err != ErrInvalidToken {
+			t.Errorf("expected refresh token to be rejected as access token, got %v", err)
+		}
+		if _, err := manager.ParseRefreshToken(tokens.AccessToken); err != ErrInvalidToken {
+			t.Errorf("expected access token to be rejected as refresh token, got %v", err)
+		}
+	})
+
+	t.Run("RefreshTokensAreUnique", func(t *testing.T) {
+		first, _ := manager.IssueTokens(user)
+		second, _ := manager.IssueTokens(user)
+		if first.RefreshToken == second.RefreshToken {
+			t.Error("expected each issued refresh token to be unique")
+		}
+	})
+
+	t.Run("WrongSecret", func(t *testing.T) {
+		tokens, _ := manager.IssueTokens(user)
+		other := NewTokenManager([]byte("other-secret"), time.Minute, time.Hour)
+		if _, err := other.ParseAccessToken(tokens.AccessToken); err != ErrInvalidToken {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("Expired", func(t *testing.T) {
+		tokens, _ := manager.IssueTokens(user)
+		expired := NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+		expired.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+		if _, err := expired.ParseAccessToken(tokens.AccessToken); err != ErrInvalidToken {
+			t.Errorf("expected expired token to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("Garbage", func(t *testing.T) {
+		if _, err := manager.ParseAccessToken("not-a-token"); err != ErrInvalidToken {
+			t.Errorf("expected ErrInvalidToken, got %v", err)
+		}
+	})
+}
+
+func TestHashToken(t *testing.T) {
+	if HashToken("a") == HashToken("b") {
+		t.Error("expected different tokens to hash differently")
+	}
+	if HashToken("a") != HashToken("a") {
+		t.Error("expected hashing to be deterministic")
+	}
+	if HashToken("a") == "a" {
+		t.Error("expected hash to differ from the token")
+	}
+}
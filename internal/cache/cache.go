@@ -1,54 +1,105 @@
 package cache
 
 import (
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type cacheEntry struct {
-	createdAt time.Time
+	expiresAt time.Time
 	val       []byte
 }
 
+func (e cacheEntry) expired(now time.Time) bool {
+	return now.After(e.expiresAt)
+}
+
+// Cache is an in-memory Store. Entries live for the interval it was created
+// with unless they are set with a TTL of their own; expired entries are
+// misses and are removed by a reaper that runs every interval until Close.
 type Cache struct {
 	interval time.Duration
 	cache    map[string]cacheEntry
 	mu       sync.RWMutex
+	stats    counters
+
+	done      chan struct{}
+	closeOnce sync.Once
 }
 
 func NewCache(interval time.Duration) *Cache {
 	cache := &Cache{
 		cache:    make(map[string]cacheEntry),
 		interval: interval,
+		done:     make(chan struct{}),
 	}
 	go cache.reapLoop()
 	return cache
 }
+
 func (c *Cache) Get(key string) ([]byte, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	entry, ok := c.cache[key]
-	return entry.val, ok
+	if !ok || entry.expired(time.Now()) {
+		c.stats.misses.Add(1)
+		return nil, false
+	}
+	c.stats.hits.Add(1)
+	return entry.val, true
 }
 
 func (c *Cache) Set(key string, val []byte) {
+	c.SetWithTTL(key, val, c.interval)
+}
+
+func (c *Cache) SetWithTTL(key string, val []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.interval
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.cache[key] = cacheEntry{
-		createdAt: time.Now(),
+		expiresAt: time.Now().Add(ttl),
 		val:       val,
 	}
 }
 
+func (c *Cache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.cache, key)
+}
+
+func (c *Cache) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.cache {
+		if strings.HasPrefix(key, prefix) {
+			delete(c.cache, key)
+		}
+	}
+}
+
 func (c *Cache) reapLoop() {
 	ticker := time.NewTicker(c.interval)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+		}
+
+		now := time.Now()
 		c.mu.Lock()
 		for key, entry := range c.cache {
-			if time.Since(entry.createdAt) > c.interval {
+			if entry.expired(now) {
 				delete(c.cache, key)
+				c.stats.evictions.Add(1)
 			}
 		}
 		c.mu.Unlock()
@@ -60,3 +111,27 @@ func (c *Cache) Clear() {
 	defer c.mu.Unlock()
 	c.cache = make(map[string]cacheEntry)
 }
+
+// Close stops the reaper. The cache can still be used, but expired entries
+// are then only ever skipped, not removed.
+func (c *Cache) Close() error {
+	c.closeOnce.Do(func() { close(c.done) })
+	return nil
+}
+
+func (c *Cache) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// counters are the statistics a store keeps, updated without its lock.
+type counters struct {
+	hits, misses, evictions atomic.Uint64
+}
+
+func (c *counters) snapshot() Stats {
+	return Stats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
@@ -59,3 +59,89 @@ func TestReapLoop(t *testing.T) {
 		return
 	}
 }
+
+func TestTTLCheckedOnRead(t *testing.T) {
+	// The reaper would not run for an hour; the entry must still expire.
+	cache := NewCache(time.Hour)
+	defer cache.Close()
+
+	cache.SetWithTTL("short", []byte("testdata"), 5*time.Millisecond)
+	cache.Set("long", []byte("testdata"))
+	if _, ok := cache.Get("short"); !ok {
+		t.Fatalf("expected to find key")
+	}
+
+	time.Sleep(10 * time.Millisecond)
+
+	if _, ok := cache.Get("short"); ok {
+		t.Errorf("expected the entry to have expired")
+	}
+	if _, ok := cache.Get("long"); !ok {
+		t.Errorf("expected an entry with the default TTL to remain")
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cache := NewCache(time.Hour)
+	defer cache.Close()
+
+	for _, key := range []string{"api:1", "api:10", "api:list:limit=50", "api:search:q=ledger"} {
+		cache.Set(key, []byte("testdata"))
+	}
+
+	cache.Delete("api:1")
+	if _, ok := cache.Get("api:1"); ok {
+		t.Errorf("expected api:1 to be deleted")
+	}
+	if _, ok := cache.Get("api:10"); !ok {
+		t.Errorf("expected Delete to remove only the exact key")
+	}
+
+	cache.DeletePrefix("api:list:")
+	if _, ok := cache.Get("api:list:limit=50"); ok {
+		t.Errorf("expected the listing to be deleted")
+	}
+	for _, key := range []string{"api:10", "api:search:q=ledger"} {
+		if _, ok := cache.Get(key); !ok {
+			t.Errorf("expected %s to remain", key)
+		}
+	}
+}
+
+func TestClose(t *testing.T) {
+	cache := NewCache(5 * time.Millisecond)
+	cache.Set("https://example.com", []byte("testdata"))
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	cache.Close()
+
+	time.Sleep(15 * time.Millisecond)
+
+	cache.mu.RLock()
+	remaining := len(cache.cache)
+	cache.mu.RUnlock()
+	if remaining != 1 {
+		t.Errorf("expected the reaper to be stopped, %d entries remain", remaining)
+	}
+	if _, ok := cache.Get("https://example.com"); ok {
+		t.Errorf("expected an expired entry to be a miss after Close")
+	}
+}
+
+func TestStats(t *testing.T) {
+	cache := NewCache(5 * time.Millisecond)
+	defer cache.Close()
+
+	cache.Set("a", []byte("testdata"))
+	cache.Get("a")
+	cache.Get("a")
+	cache.Get("b")
+
+	time.Sleep(15 * time.Millisecond)
+
+	stats := cache.Stats()
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Evictions != 1 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
@@ -0,0 +1,31 @@
+package cache
+
+import "time"
+
+// Store keeps byte values under string keys for a limited time. Entries may
+// be dropped at any moment, and a store that cannot be reached behaves as if
+// it were empty, so callers must always be able to fall back to the source.
+type Store interface {
+	Get(key string) ([]byte, bool)
+	// Set stores val for the store's default TTL.
+	Set(key string, val []byte)
+	// SetWithTTL stores val for ttl; a non-positive ttl means the default.
+	SetWithTTL(key string, val []byte, ttl time.Duration)
+	Delete(key string)
+	// DeletePrefix removes every key that starts with prefix.
+	DeletePrefix(prefix string)
+	Clear()
+	// Close releases the resources of the store, such as background
+	// goroutines and connections.
+	Close() error
+	Stats() Stats
+}
+
+// Stats counts the reads a store has served from its entries and the reads
+// it could not, and the entries it dropped on its own because they expired
+// or to make room.
+type Stats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+}
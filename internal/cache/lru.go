@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	val       []byte
+	expiresAt time.Time
+}
+
+// LRU is an in-memory Store bounded by the total size of its keys and values.
+// When a write takes it over the bound, the least recently used entries are
+// evicted. Entries older than their TTL are misses; a zero TTL keeps them
+// until they are evicted.
+type LRU struct {
+	maxBytes int64
+	ttl      time.Duration
+	size     int64
+	items    map[string]*list.Element
+	order    *list.List // most recently used first
+	mu       sync.Mutex
+	stats    counters
+}
+
+func NewLRU(maxBytes int64, ttl time.Duration) *LRU {
+	return &LRU{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+func entrySize(key string, val []byte) int64 {
+	return int64(len(key) + len(val))
+}
+
+func (c *LRU) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		c.stats.misses.Add(1)
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.remove(elem)
+		c.stats.evictions.Add(1)
+		c.stats.misses.Add(1)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	c.stats.hits.Add(1)
+	return entry.val, true
+}
+
+func (c *LRU) Set(key string, val []byte) {
+	c.SetWithTTL(key, val, c.ttl)
+}
+
+// SetWithTTL stores val under key. A value that could never fit within the
+// bound is not stored, and replaces nothing but an older value of the same
+// key.
+func (c *LRU) SetWithTTL(key string, val []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = c.ttl
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+	size := entrySize(key, val)
+	if size > c.maxBytes {
+		return
+	}
+
+	entry := &lruEntry{key: key, val: val}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+	c.items[key] = c.order.PushFront(entry)
+	c.size += size
+
+	for c.size > c.maxBytes {
+		c.remove(c.order.Back())
+		c.stats.evictions.Add(1)
+	}
+}
+
+func (c *LRU) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.items[key]; ok {
+		c.remove(elem)
+	}
+}
+
+func (c *LRU) DeletePrefix(prefix string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, elem := range c.items {
+		if strings.HasPrefix(key, prefix) {
+			c.remove(elem)
+		}
+	}
+}
+
+func (c *LRU) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*list.Element)
+	c.order.Init()
+	c.size = 0
+}
+
+// Close does nothing: an LRU has no reaper, it drops expired entries as
+// they are read or evicted.
+func (c *LRU) Close() error {
+	return nil
+}
+
+func (c *LRU) Stats() Stats {
+	return c.stats.snapshot()
+}
+
+// Len returns the number of entries, including expired ones that have not
+// been read since they expired.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) remove(elem *list.Element) {
+	entry := c.order.Remove(elem).(*lruEntry)
+	delete(c.items, entry.key)
+	c.size -= entrySize(entry.key, entry.val)
+}
@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLRU(t *testing.T) {
+	t.Run("EvictsLeastRecentlyUsed", func(t *testing.T) {
+		// Every entry takes 4 bytes: a 1-byte key and a 3-byte value.
+		cache := NewLRU(12, 0)
+		cache.Set("a", []byte("aaa"))
+		cache.Set("b", []byte("bbb"))
+		cache.Set("c", []byte("ccc"))
+		cache.Get("a")
+		cache.Set("d", []byte("ddd"))
+
+		if _, ok := cache.Get("b"); ok {
+			t.Errorf("expected the least recently used key to be evicted")
+		}
+		for _, key := range []string{"a", "c", "d"} {
+			if _, ok := cache.Get(key); !ok {
+				t.Errorf("expected to find key %s", key)
+			}
+		}
+		if cache.Len() != 3 {
+			t.Errorf("expected 3 entries, got %d", cache.Len())
+		}
+	})
+
+	t.Run("ReplacesValues", func(t *testing.T) {
+		cache := NewLRU(12, 0)
+		cache.Set("a", []byte("aaa"))
+		cache.Set("b", []byte("bbb"))
+		cache.Set("a", []byte("aaaaaaa"))
+
+		if val, ok := cache.Get("a"); !ok || string(val) != "aaaaaaa" {
+			t.Errorf("expected the new value, got %q", val)
+		}
+		if _, ok := cache.Get("b"); !ok {
+			t.Errorf("expected the replacement to fit alongside b")
+		}
+
+		cache.Set("a", []byte(strings.Repeat("x", 20)))
+		if _, ok := cache.Get("a"); ok {
+			t.Errorf("expected a value larger than the bound not to be stored")
+		}
+		if _, ok := cache.Get("b"); !ok {
+			t.Errorf("expected an oversized value not to evict other keys")
+		}
+	})
+
+	t.Run("Expires", func(t *testing.T) {
+		cache := NewLRU(1024, 5*time.Millisecond)
+		cache.Set("a", []byte("aaa"))
+		if _, ok := cache.Get("a"); !ok {
+			t.Fatalf("expected to find key")
+		}
+		time.Sleep(10 * time.Millisecond)
+		if _, ok := cache.Get("a"); ok {
+			t.Errorf("expected the entry to have expired")
+		}
+		if cache.Len() != 0 {
+			t.Errorf("expected the expired entry to be removed, got %d entries", cache.Len())
+		}
+	})
+
+	t.Run("EntryTTL", func(t *testing.T) {
+		cache := NewLRU(1024, time.Hour)
+		cache.SetWithTTL("a", []byte("aaa"), 5*time.Millisecond)
+		cache.Set("b", []byte("bbb"))
+		time.Sleep(10 * time.Millisecond)
+		if _, ok := cache.Get("a"); ok {
+			t.Errorf("expected the entry to have expired")
+		}
+		if _, ok := cache.Get("b"); !ok {
+			t.Errorf("expected an entry with the default TTL to remain")
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		cache := NewLRU(1024, 0)
+		for _, key := range []string{"api:1", "api:10", "api:list:a", "api:list:b"} {
+			cache.Set(key, []byte("x"))
+		}
+		cache.Delete("api:1")
+		cache.DeletePrefix("api:list:")
+		if _, ok := cache.Get("api:10"); !ok || cache.Len() != 1 {
+			t.Errorf("expected only api:10 to remain, got %d entries", cache.Len())
+		}
+		cache.Set("c", []byte(strings.Repeat("x", 1000)))
+		if _, ok := cache.Get("api:10"); !ok {
+			t.Errorf("expected deleted entries to free their space")
+		}
+	})
+
+	t.Run("Stats", func(t *testing.T) {
+		cache := NewLRU(8, 0)
+		cache.Set("a", []byte("aaa"))
+		cache.Set("b", []byte("bbb"))
+		cache.Set("c", []byte("ccc"))
+		cache.Get("c")
+		cache.Get("a")
+		if stats := cache.Stats(); stats.Hits != 1 || stats.Misses != 1 || stats.Evictions != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("Clear", func(t *testing.T) {
+		cache := NewLRU(1024, 0)
+		cache.Set("a", []byte("aaa"))
+		cache.Clear()
+		if _, ok := cache.Get("a"); ok || cache.Len() != 0 {
+			t.Errorf("expected an empty cache")
+		}
+		cache.Set("b", []byte("bbb"))
+		if _, ok := cache.Get("b"); !ok {
+			t.Errorf("expected the cache to be usable after Clear")
+		}
+	})
+}
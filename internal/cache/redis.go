@@ -0,0 +1,445 @@
+package cache
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultRedisTimeout  = 2 * time.Second
+	defaultRedisPoolSize = 8
+
+	// A failed dial is retried after minRedisBackoff, doubling with every
+	// further failure up to maxRedisBackoff.
+	minRedisBackoff = 100 * time.Millisecond
+	maxRedisBackoff = 5 * time.Second
+)
+
+type RedisOptions struct {
+	Addr     string
+	Password string
+	DB       int
+	// Prefix is put in front of every key, so that a database can be shared
+	// and Clear removes only the keys of this store.
+	Prefix string
+	// TTL is the expiry Redis is asked to apply to each entry; zero keeps
+	// entries until they are evicted.
+	TTL     time.Duration
+	Timeout time.Duration
+	// PoolSize is how many idle connections are kept for reuse. Commands
+	// beyond it dial their own connection, which is closed afterwards.
+	PoolSize int
+}
+
+// ParseRedisURL reads the address, password and database number from a URL
+// of the form redis://[:password@]host[:port][/db].
+func ParseRedisURL(raw string) (RedisOptions, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return RedisOptions{}, fmt.Errorf("invalid Redis URL: %w", err)
+	}
+	if u.Scheme != "redis" || u.Hostname() == "" {
+		return RedisOptions{}, fmt.Errorf("invalid Redis URL %q, expected redis://host[:port][/db]", raw)
+	}
+
+	opts := RedisOptions{Addr: u.Host}
+	if u.Port() == "" {
+		opts.Addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+	if password, ok := u.User.Password(); ok {
+		opts.Password = password
+	}
+	if db := strings.TrimPrefix(u.Path, "/"); db != "" {
+		opts.DB, err = strconv.Atoi(db)
+		if err != nil || opts.DB < 0 {
+			return RedisOptions{}, fmt.Errorf("invalid Redis database %q", db)
+		}
+	}
+	return opts, nil
+}
+
+// redisError is an error reply from the server.
+type redisError string
+
+func (e redisError) Error() string { return string(e) }
+
+var (
+	errProtocol         = errors.New("cache: malformed RESP reply")
+	errRedisUnavailable = errors.New("cache: redis unavailable, waiting to dial again")
+	errRedisClosed      = errors.New("cache: redis store closed")
+)
+
+// RedisStore is a Store kept in Redis, or any server speaking its RESP
+// protocol. Commands run concurrently, each on a connection taken from a pool
+// of idle ones or dialled for it. Failures are logged and treated as misses.
+// After a failed dial the store does not dial again until a backoff has
+// passed, so that while the server is down commands fail at once instead of
+// each waiting for the timeout. Redis expires and evicts entries itself, so
+// the store cannot count evictions.
+type RedisStore struct {
+	opts  RedisOptions
+	stats counters
+
+	mu      sync.Mutex
+	idle    []*redisConn
+	closed  bool
+	retryAt time.Time
+	backoff time.Duration
+}
+
+// redisConn is a connection with the reader its replies are parsed from.
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func NewRedisStore(opts RedisOptions) *RedisStore {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultRedisTimeout
+	}
+	if opts.PoolSize <= 0 {
+		opts.PoolSize = defaultRedisPoolSize
+	}
+	return &RedisStore{opts: opts}
+}
+
+func (s *RedisStore) Get(key string) ([]byte, bool) {
+	reply, err := s.do("GET", s.opts.Prefix+key)
+	if err != nil {
+		logFailure("GET", err)
+	}
+	val, ok := reply.([]byte)
+	if !ok {
+		s.stats.misses.Add(1)
+		return nil, false
+	}
+	s.stats.hits.Add(1)
+	return val, true
+}
+
+func (s *RedisStore) Set(key string, val []byte) {
+	s.SetWithTTL(key, val, s.opts.TTL)
+}
+
+func (s *RedisStore) SetWithTTL(key string, val []byte, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = s.opts.TTL
+	}
+	args := []string{"SET", s.opts.Prefix + key, string(val)}
+	if ttl > 0 {
+		args = append(args, "PX", strconv.FormatInt(ttl.Milliseconds(), 10))
+	}
+	if _, err := s.do(args...); err != nil {
+		logFailure("SET", err)
+	}
+}
+
+func (s *RedisStore) Delete(key string) {
+	if _, err := s.do("DEL", s.opts.Prefix+key); err != nil {
+		logFailure("DEL", err)
+	}
+}
+
+func (s *RedisStore) DeletePrefix(prefix string) {
+	s.deleteMatching(escapeGlob(s.opts.Prefix+prefix) + "*")
+}
+
+// Clear deletes the keys under the store's prefix. It walks them with SCAN
+// rather than using FLUSHDB, which would take other data with it.
+func (s *RedisStore) Clear() {
+	s.deleteMatching(escapeGlob(s.opts.Prefix) + "*")
+}
+
+// deleteMatching deletes the keys that match a SCAN pattern.
+func (s *RedisStore) deleteMatching(match string) {
+	cursor := "0"
+	for {
+		reply, err := s.do("SCAN", cursor, "MATCH", match, "COUNT", "100")
+		if err != nil {
+			logFailure("SCAN", err)
+			return
+		}
+		var keys []string
+		cursor, keys, err = scanReply(reply)
+		if err != nil {
+			log.Printf("cache: redis SCAN failed: %v", err)
+			return
+		}
+		if len(keys) > 0 {
+			if _, err := s.do(append([]string{"DEL"}, keys...)...); err != nil {
+				logFailure("DEL", err)
+				return
+			}
+		}
+		if cursor == "0" {
+			return
+		}
+	}
+}
+
+func (s *RedisStore) Stats() Stats {
+	return s.stats.snapshot()
+}
+
+// Close closes the idle connections. Commands still running close theirs
+// when they finish, and later commands fail as misses.
+func (s *RedisStore) Close() error {
+	s.mu.Lock()
+	idle := s.idle
+	s.idle, s.closed = nil, true
+	s.mu.Unlock()
+
+	var errs []error
+	for _, conn := range idle {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// do sends a command and reads its reply. An error reply is returned as a
+// redisError; any other error drops the connection. A pooled connection may
+// have been closed by the server while it sat idle, after an idle timeout or
+// a restart, so a command failing on one is sent once more on a new
+// connection. The store only sends commands that are safe to repeat.
+func (s *RedisStore) do(args ...string) (interface{}, error) {
+	conn, pooled, err := s.conn()
+	if err != nil {
+		return nil, err
+	}
+	reply, err := conn.roundTrip(args, s.opts.Timeout)
+	if err != nil && pooled {
+		conn.Close()
+		if conn, err = s.redial(); err != nil {
+			return nil, err
+		}
+		reply, err = conn.roundTrip(args, s.opts.Timeout)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	s.release(conn)
+	if e, ok := reply.(redisError); ok {
+		return nil, e
+	}
+	return reply, nil
+}
+
+// conn takes an idle connection, reporting it as pooled, or dials a new one.
+func (s *RedisStore) conn() (*redisConn, bool, error) {
+	s.mu.Lock()
+	if n := len(s.idle); n > 0 {
+		conn := s.idle[n-1]
+		s.idle = s.idle[:n-1]
+		s.mu.Unlock()
+		return conn, true, nil
+	}
+	s.mu.Unlock()
+
+	conn, err := s.redial()
+	return conn, false, err
+}
+
+// redial dials a new connection. While a dial backoff is running it returns
+// errRedisUnavailable without dialling.
+func (s *RedisStore) redial() (*redisConn, error) {
+	s.mu.Lock()
+	switch {
+	case s.closed:
+		s.mu.Unlock()
+		return nil, errRedisClosed
+	case time.Now().Before(s.retryAt):
+		s.mu.Unlock()
+		return nil, errRedisUnavailable
+	}
+	s.mu.Unlock()
+
+	conn, err := s.dial()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.backoff = min(max(2*s.backoff, minRedisBackoff), maxRedisBackoff)
+		s.retryAt = time.Now().Add(s.backoff)
+		return nil, err
+	}
+	s.backoff, s.retryAt = 0, time.Time{}
+	return conn, nil
+}
+
+// release returns a healthy connection to the pool, or closes it when the
+// pool is full or the store closed.
+func (s *RedisStore) release(conn *redisConn) {
+	s.mu.Lock()
+	if !s.closed && len(s.idle) < s.opts.PoolSize {
+		s.idle = append(s.idle, conn)
+		conn = nil
+	}
+	s.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+func (s *RedisStore) dial() (*redisConn, error) {
+	nc, err := net.DialTimeout("tcp", s.opts.Addr, s.opts.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	conn := &redisConn{Conn: nc, r: bufio.NewReader(nc)}
+
+	var setup [][]string
+	if s.opts.Password != "" {
+		setup = append(setup, []string{"AUTH", s.opts.Password})
+	}
+	if s.opts.DB != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(s.opts.DB)})
+	}
+	for _, args := range setup {
+		reply, err := conn.roundTrip(args, s.opts.Timeout)
+		if err == nil {
+			if e, ok := reply.(redisError); ok {
+				err = e
+			}
+		}
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s: %w", args[0], err)
+		}
+	}
+	return conn, nil
+}
+
+func (c *redisConn) roundTrip(args []string, timeout time.Duration) (interface{}, error) {
+	c.SetDeadline(time.Now().Add(timeout))
+	if _, err := c.Write(encodeCommand(args)); err != nil {
+		return nil, err
+	}
+	return readReply(c.r)
+}
+
+// logFailure logs a failed command. Commands refused during a dial backoff
+// are not logged, as the failed dial already was, nor are those refused by a
+// closed store.
+func logFailure(cmd string, err error) {
+	if errors.Is(err, errRedisUnavailable) || errors.Is(err, errRedisClosed) {
+		return
+	}
+	log.Printf("cache: redis %s failed: %v", cmd, err)
+}
+
+// encodeCommand writes a command as a RESP array of bulk strings.
+func encodeCommand(args []string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	return b.Bytes()
+}
+
+// readReply reads one RESP value: a string for a simple string, a redisError,
+// an int64, a []byte for a bulk string, an []interface{} for an array, or nil
+// for a null bulk string or array.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errProtocol
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return redisError(body), nil
+	case ':':
+		n, err := strconv.ParseInt(body, 10, 64)
+		if err != nil {
+			return nil, errProtocol
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, errProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		if buf[n] != '\r' || buf[n+1] != '\n' {
+			return nil, errProtocol
+		}
+		return buf[:n], nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, errProtocol
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	}
+	return nil, errProtocol
+}
+
+// scanReply splits the reply to SCAN into the next cursor and the keys.
+func scanReply(reply interface{}) (string, []string, error) {
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return "", nil, errProtocol
+	}
+	cursor, ok := items[0].([]byte)
+	if !ok {
+		return "", nil, errProtocol
+	}
+	list, ok := items[1].([]interface{})
+	if !ok {
+		return "", nil, errProtocol
+	}
+	keys := make([]string, 0, len(list))
+	for _, item := range list {
+		key, ok := item.([]byte)
+		if !ok {
+			return "", nil, errProtocol
+		}
+		keys = append(keys, string(key))
+	}
+	return string(cursor), keys, nil
+}
+
+// escapeGlob quotes the characters that MATCH patterns treat specially.
+func escapeGlob(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if strings.ContainsRune(`*?[]\`, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,449 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-process server for the subset of RESP commands that
+// RedisStore sends.
+type fakeRedis struct {
+	listener net.Listener
+	password string
+
+	mu     sync.Mutex
+	data   map[int]map[string]string
+	expiry map[string]time.Time
+	conns  []net.Conn
+}
+
+func startFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("error listening: %v", err)
+	}
+	f := &fakeRedis{
+		listener: listener,
+		password: password,
+		data:     make(map[int]map[string]string),
+		expiry:   make(map[string]time.Time),
+	}
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			f.mu.Lock()
+			f.conns = append(f.conns, conn)
+			f.mu.Unlock()
+			go f.serve(conn)
+		}
+	}()
+	return f
+}
+
+func (f *fakeRedis) addr() string {
+	return f.listener.Addr().String()
+}
+
+// dropConnections closes the server side of every open connection, as a
+// restart would.
+func (f *fakeRedis) dropConnections() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, conn := range f.conns {
+		conn.Close()
+	}
+	f.conns = nil
+}
+
+func (f *fakeRedis) keys(db int) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for key := range f.data[db] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	db, authed := 0, f.password == ""
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]interface{})
+		var args []string
+		for _, item := range items {
+			arg, _ := item.([]byte)
+			args = append(args, string(arg))
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		f.mu.Lock()
+		if f.data[db] == nil {
+			f.data[db] = make(map[string]string)
+		}
+		data := f.data[db]
+		var out string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authed = args[1] == f.password
+			out = "+OK\r\n"
+			if !authed {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required.\r\n"
+		case cmd == "SELECT":
+			db, _ = strconv.Atoi(args[1])
+			out = "+OK\r\n"
+		case cmd == "GET":
+			val, ok := data[args[1]]
+			if deadline, expires := f.expiry[args[1]]; expires && time.Now().After(deadline) {
+				ok = false
+			}
+			out = "$-1\r\n"
+			if ok {
+				out = fmt.Sprintf("$%d\r\n%s\r\n", len(val), val)
+			}
+		case cmd == "SET":
+			data[args[1]] = args[2]
+			delete(f.expiry, args[1])
+			if len(args) == 5 && strings.ToUpper(args[3]) == "PX" {
+				ms, _ := strconv.Atoi(args[4])
+				f.expiry[args[1]] = time.Now().Add(time.Duration(ms) * time.Millisecond)
+			}
+			out = "+OK\r\n"
+		case cmd == "DEL":
+			n := 0
+			for _, key := range args[1:] {
+				if _, ok := data[key]; ok {
+					delete(data, key)
+					n++
+				}
+			}
+			out = fmt.Sprintf(":%d\r\n", n)
+		case cmd == "SCAN":
+			// Returns every match at once; only trailing-star patterns with
+			// escaped literals are understood.
+			prefix := strings.ReplaceAll(strings.TrimSuffix(args[3], "*"), `\`, "")
+			var b strings.Builder
+			var matched []string
+			for key := range data {
+				if strings.HasPrefix(key, prefix) {
+					matched = append(matched, key)
+				}
+			}
+			fmt.Fprintf(&b, "*2\r\n$1\r\n0\r\n*%d\r\n", len(matched))
+			for _, key := range matched {
+				fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(key), key)
+			}
+			out = b.String()
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+func TestRedisStore(t *testing.T) {
+	t.Run("SetGet", func(t *testing.T) {
+		server := startFakeRedis(t, "")
+		store := NewRedisStore(RedisOptions{Addr: server.addr(), Prefix: "microd:"})
+		defer store.Close()
+
+		if _, ok := store.Get("api:1"); ok {
+			t.Errorf("expected a miss for an unknown key")
+		}
+		store.Set("api:1", []byte("binary\r\n\x00value"))
+		val, ok := store.Get("api:1")
+		if !ok || string(val) != "binary\r\n\x00value" {
+			t.Errorf("expected to find the value, got %q", val)
+		}
+		if keys := server.keys(0); len(keys) != 1 || keys[0] != "microd:api:1" {
+			t.Errorf("expected the key to be prefixed, got %v", keys)
+		}
+	})
+
+	t.Run("TTL", func(t *testing.T) {
+		server := startFakeRedis(t, "")
+		store := NewRedisStore(RedisOptions{Addr: server.addr(), TTL: 5 * time.Millisecond})
+		defer store.Close()
+
+		store.Set("api:1", []byte("value"))
+		if _, ok := store.Get("api:1"); !ok {
+			t.Fatalf("expected to find key")
+		}
+		time.Sleep(10 * time.Millisecond)
+		if _, ok := store.Get("api:1"); ok {
+			t.Errorf("expected the entry to have expired")
+		}
+	})
+
+	t.Run("ClearKeepsOtherKeys", func(t *testing.T) {
+		server := startFakeRedis(t, "")
+		store := NewRedisStore(RedisOptions{Addr: server.addr(), Prefix: "microd[1]:"})
+		other := NewRedisStore(RedisOptions{Addr: server.addr(), Prefix: "other:"})
+		defer store.Close()
+		defer other.Close()
+
+		store.Set("api:1", []byte("a"))
+		store.Set("apis:list", []byte("b"))
+		other.Set("api:1", []byte("c"))
+		store.Clear()
+
+		if keys := server.keys(0); len(keys) != 1 || keys[0] != "other:api:1" {
+			t.Errorf("expected only the other store's key to remain, got %v", keys)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		server := startFakeRedis(t, "")
+		store := NewRedisStore(RedisOptions{Addr: server.addr(), Prefix: "microd:", TTL: time.Hour})
+		defer store.Close()
+
+		for _, key := range []string{"api:1", "api:10", "api:list:a", "api:list:b"} {
+			store.Set(key, []byte("x"))
+		}
+		store.SetWithTTL("api:2", []byte("x"), 5*time.Millisecond)
+		store.Delete("api:1")
+		store.DeletePrefix("api:list:")
+		if keys := server.keys(0); len(keys) != 2 || keys[0] != "microd:api:10" || keys[1] != "microd:api:2" {
+			t.Errorf("expected api:10 and api:2 to remain, got %v", keys)
+		}
+
+		time.Sleep(10 * time.Millisecond)
+		store.Get("api:2")
+		store.Get("api:10")
+		if stats := store.Stats(); stats.Hits != 1 || stats.Misses != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+	})
+
+	t.Run("AuthAndSelect", func(t *testing.T) {
+		server := startFakeRedis(t, "s3cret")
+		opts, err := ParseRedisURL("redis://:s3cret@" + server.addr() + "/2")
+		if err != nil {
+			t.Fatalf("ParseRedisURL() error = %v", err)
+		}
+		store := NewRedisStore(opts)
+		defer store.Close()
+
+		store.Set("api:1", []byte("value"))
+		if _, ok := store.Get("api:1"); !ok {
+			t.Errorf("expected to find key")
+		}
+		if keys := server.keys(2); len(keys) != 1 {
+			t.Errorf("expected the key in database 2, got %v", keys)
+		}
+
+		wrong := NewRedisStore(RedisOptions{Addr: server.addr(), Password: "wrong"})
+		defer wrong.Close()
+		if _, ok := wrong.Get("api:1"); ok {
+			t.Errorf("expected a failed login to behave as a miss")
+		}
+	})
+
+	t.Run("Reconnects", func(t *testing.T) {
+		server := startFakeRedis(t, "")
+		store := NewRedisStore(RedisOptions{Addr: server.addr()})
+		defer store.Close()
+
+		store.Set("api:1", []byte("value"))
+		store.mu.Lock()
+		for _, conn := range store.idle {
+			conn.Close()
+		}
+		store.mu.Unlock()
+
+		if _, ok := store.Get("api:1"); !ok {
+			t.Errorf("expected the store to dial again after a broken connection")
+		}
+	})
+
+	t.Run("RetriesDroppedConnection", func(t *testing.T) {
+		server := startFakeRedis(t, "")
+		store := NewRedisStore(RedisOptions{Addr: server.addr()})
+		defer store.Close()
+
+		store.Set("api:1", []byte("value"))
+		server.dropConnections()
+
+		store.Delete("api:1")
+		if keys := server.keys(0); len(keys) != 0 {
+			t.Errorf("expected the delete to reach the server, got %v", keys)
+		}
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		server := startFakeRedis(t, "")
+		store := NewRedisStore(RedisOptions{Addr: server.addr()})
+
+		store.Set("api:1", []byte("value"))
+		running, _, err := store.conn()
+		if err != nil {
+			t.Fatalf("error taking a connection: %v", err)
+		}
+		store.Close()
+		store.release(running)
+
+		store.mu.Lock()
+		idle := len(store.idle)
+		store.mu.Unlock()
+		if idle != 0 {
+			t.Errorf("expected no connections to be pooled after Close, got %d", idle)
+		}
+		if _, ok := store.Get("api:1"); ok {
+			t.Errorf("expected a closed store to behave as a miss")
+		}
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		store := NewRedisStore(RedisOptions{Addr: "127.0.0.1:1", Timeout: 100 * time.Millisecond})
+		store.Set("api:1", []byte("value"))
+		if _, ok := store.Get("api:1"); ok {
+			t.Errorf("expected an unreachable server to behave as a miss")
+		}
+		store.Clear()
+	})
+
+	t.Run("BacksOffAfterFailedDial", func(t *testing.T) {
+		// A server that accepts connections but never answers, so that every
+		// dial waits for the timeout.
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("error listening: %v", err)
+		}
+		defer listener.Close()
+		var mu sync.Mutex
+		var accepted []net.Conn
+		go func() {
+			for {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				mu.Lock()
+				accepted = append(accepted, conn)
+				mu.Unlock()
+			}
+		}()
+		dials := func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(accepted)
+		}
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, conn := range accepted {
+				conn.Close()
+			}
+		}()
+
+		timeout := 200 * time.Millisecond
+		store := NewRedisStore(RedisOptions{Addr: listener.Addr().String(), Password: "pw", Timeout: timeout})
+		defer store.Close()
+
+		if _, ok := store.Get("api:1"); ok {
+			t.Fatalf("expected a silent server to behave as a miss")
+		}
+		start := time.Now()
+		for i := 0; i < 10; i++ {
+			if _, ok := store.Get("api:1"); ok {
+				t.Fatalf("expected a miss during the backoff")
+			}
+		}
+		if elapsed := time.Since(start); elapsed >= timeout {
+			t.Errorf("expected misses during the backoff to fail fast, took %v", elapsed)
+		}
+		if n := dials(); n != 1 {
+			t.Errorf("expected a single dial during the backoff, got %d", n)
+		}
+
+		store.mu.Lock()
+		backoff := store.backoff
+		store.retryAt = time.Time{}
+		store.mu.Unlock()
+		store.Get("api:1")
+		if n := dials(); n != 2 {
+			t.Errorf("expected the store to dial again after the backoff, got %d dials", n)
+		}
+		store.mu.Lock()
+		if store.backoff != 2*backoff {
+			t.Errorf("expected the backoff to double to %v, got %v", 2*backoff, store.backoff)
+		}
+		store.mu.Unlock()
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		server := startFakeRedis(t, "")
+		store := NewRedisStore(RedisOptions{Addr: server.addr(), PoolSize: 2})
+		defer store.Close()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				key := fmt.Sprintf("api:%d", i)
+				store.Set(key, []byte(key))
+				if val, ok := store.Get(key); !ok || string(val) != key {
+					t.Errorf("expected to read back %s, got %q", key, val)
+				}
+			}(i)
+		}
+		wg.Wait()
+
+		store.mu.Lock()
+		defer store.mu.Unlock()
+		if len(store.idle) > 2 {
+			t.Errorf("expected at most 2 idle connections, got %d", len(store.idle))
+		}
+	})
+}
+
+func TestParseRedisURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want RedisOptions
+	}{
+		{"redis://localhost", RedisOptions{Addr: "localhost:6379"}},
+		{"redis://cache.internal:6380/3", RedisOptions{Addr: "cache.internal:6380", DB: 3}},
+		{"redis://user:pw@localhost:6379/0", RedisOptions{Addr: "localhost:6379", Password: "pw"}},
+	}
+	for _, tt := range tests {
+		got, err := ParseRedisURL(tt.url)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseRedisURL(%q) = %+v, %v, want %+v", tt.url, got, err, tt.want)
+		}
+	}
+
+	for _, url := range []string{"http://localhost", "redis://", "redis://localhost/db", "::"} {
+		if _, err := ParseRedisURL(url); err == nil {
+			t.Errorf("ParseRedisURL(%q) expected an error", url)
+		}
+	}
+}
@@ -0,0 +1,334 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"microd-api/internal/models"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatCSV  = "csv"
+)
+
+var (
+	ErrUnknownFormat   = errors.New("unknown format, expected json, yaml or csv")
+	ErrInvalidDocument = errors.New("invalid catalog document")
+)
+
+// Record is an API as it is exported and imported: the fields a client may
+// write, without the ID and timestamps the catalog assigns. Records are
+// matched to existing APIs by name.
+type Record struct {
+	Name              string     `json:"name" yaml:"name"`
+	Version           string     `json:"version,omitempty" yaml:"version,omitempty"`
+	Description       string     `json:"description,omitempty" yaml:"description,omitempty"`
+	DocumentationLink string     `json:"documentation_link,omitempty" yaml:"documentation_link,omitempty"`
+	ForumReference    string     `json:"forum_reference,omitempty" yaml:"forum_reference,omitempty"`
+	ApmLink           string     `json:"apm_link,omitempty" yaml:"apm_link,omitempty"`
+	TeamID            int64      `json:"team_id,omitempty" yaml:"team_id,omitempty"`
+	Tags              []string   `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Lifecycle         string     `json:"lifecycle,omitempty" yaml:"lifecycle,omitempty"`
+	DeprecatedAt      *time.Time `json:"deprecated_at,omitempty" yaml:"deprecated_at,omitempty"`
+	SunsetAt          *time.Time `json:"sunset_at,omitempty" yaml:"sunset_at,omitempty"`
+	Swagger           string     `json:"swagger,omitempty" yaml:"swagger,omitempty"`
+}
+
+func FromAPI(api models.API) Record {
+	return Record{
+		Name:              api.Name,
+		Version:           api.Version,
+		Description:       api.Description,
+		DocumentationLink: api.DocumentationLink,
+		ForumReference:    api.ForumReference,
+		ApmLink:           api.ApmLink,
+		TeamID:            api.TeamID,
+		Tags:              api.Tags,
+		Lifecycle:         api.Lifecycle,
+		DeprecatedAt:      optionalTime(api.DeprecatedAt),
+		SunsetAt:          optionalTime(api.SunsetAt),
+		Swagger:           api.Swagger,
+	}
+}
+
+func (r Record) API() models.API {
+	api := models.API{
+		Name:              r.Name,
+		Version:           r.Version,
+		Description:       r.Description,
+		DocumentationLink: r.DocumentationLink,
+		ForumReference:    r.ForumReference,
+		ApmLink:           r.ApmLink,
+		TeamID:            r.TeamID,
+		Tags:              r.Tags,
+		Lifecycle:         r.Lifecycle,
+		Swagger:           r.Swagger,
+	}
+	if r.DeprecatedAt != nil {
+		api.DeprecatedAt = r.DeprecatedAt.UTC()
+	}
+	if r.SunsetAt != nil {
+		api.SunsetAt = r.SunsetAt.UTC()
+	}
+	return api
+}
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
+
+func ValidFormat(format string) bool {
+	return format == FormatJSON || format == FormatYAML || format == FormatCSV
+}
+
+// FormatFromContentType returns the format of a media type, or "" when it is
+// not one of the supported formats.
+func FormatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "application/json":
+		return FormatJSON
+	case "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		return FormatYAML
+	case "text/csv":
+		return FormatCSV
+	}
+	return ""
+}
+
+// ContentType returns the media type of a document in format.
+func ContentType(format string) string {
+	switch format {
+	case FormatYAML:
+		return "application/yaml"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	}
+	return "application/json"
+}
+
+func Encode(w io.Writer, format string, records []Record) error {
+	if records == nil {
+		records = []Record{}
+	}
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(records)
+	case FormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(records); err != nil {
+			return err
+		}
+		return encoder.Close()
+	case FormatCSV:
+		return encodeCSV(w, records)
+	}
+	return ErrUnknownFormat
+}
+
+// Decode reads the records of a document in format. JSON and YAML documents
+// are a list of records; unknown fields are rejected so that a misspelt field
+// is not silently dropped.
+func Decode(r io.Reader, format string) ([]Record, error) {
+	var records []Record
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&records); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+		}
+	case FormatYAML:
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&records); err != nil && err != io.EOF {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+		}
+	case FormatCSV:
+		return decodeCSV(r)
+	default:
+		return nil, ErrUnknownFormat
+	}
+	if records == nil {
+		records = []Record{}
+	}
+	return records, nil
+}
+
+// csvColumn maps one CSV column to its field in Record. Tags are joined with
+// commas, which a tag cannot contain, and dates are RFC 3339 timestamps.
+type csvColumn struct {
+	name string
+	get  func(r *Record) string
+	set  func(r *Record, value string) error
+}
+
+func stringColumn(name string, field func(r *Record) *string) csvColumn {
+	return csvColumn{
+		name: name,
+		get:  func(r *Record) string { return *field(r) },
+		set:  func(r *Record, value string) error { *field(r) = value; return nil },
+	}
+}
+
+func timeColumn(name string, field func(r *Record) **time.Time) csvColumn {
+	return csvColumn{
+		name: name,
+		get: func(r *Record) string {
+			if *field(r) == nil {
+				return ""
+			}
+			return (*field(r)).UTC().Format(time.RFC3339)
+		},
+		set: func(r *Record, value string) error {
+			if value == "" {
+				return nil
+			}
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return errors.New("must be an RFC 3339 timestamp")
+			}
+			*field(r) = &t
+			return nil
+		},
+	}
+}
+
+var csvColumns = []csvColumn{
+	stringColumn("name", func(r *Record) *string { return &r.Name }),
+	stringColumn("version", func(r *Record) *string { return &r.Version }),
+	stringColumn("description", func(r *Record) *string { return &r.Description }),
+	stringColumn("documentation_link", func(r *Record) *string { return &r.DocumentationLink }),
+	stringColumn("forum_reference", func(r *Record) *string { return &r.ForumReference }),
+	stringColumn("apm_link", func(r *Record) *string { return &r.ApmLink }),
+	{
+		name: "team_id",
+		get: func(r *Record) string {
+			if r.TeamID == 0 {
+				return ""
+			}
+			return strconv.FormatInt(r.TeamID, 10)
+		},
+		set: func(r *Record, value string) error {
+			if value == "" {
+				return nil
+			}
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return errors.New("must be a team ID")
+			}
+			r.TeamID = id
+			return nil
+		},
+	},
+	{
+		name: "tags",
+		get:  func(r *Record) string { return strings.Join(r.Tags, ",") },
+		set: func(r *Record, value string) error {
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					r.Tags = append(r.Tags, tag)
+				}
+			}
+			return nil
+		},
+	},
+	stringColumn("lifecycle", func(r *Record) *string { return &r.Lifecycle }),
+	timeColumn("deprecated_at", func(r *Record) **time.Time { return &r.DeprecatedAt }),
+	timeColumn("sunset_at", func(r *Record) **time.Time { return &r.SunsetAt }),
+	stringColumn("swagger", func(r *Record) *string { return &r.Swagger }),
+}
+
+func encodeCSV(w io.Writer, records []Record) error {
+	writer := csv.NewWriter(w)
+	row := make([]string, len(csvColumns))
+	for i, column := range csvColumns {
+		row[i] = column.name
+	}
+	if err := writer.Write(row); err != nil {
+		return err
+	}
+	for _, record := range records {
+		for i, column := range csvColumns {
+			row[i] = column.get(&record)
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// decodeCSV reads a CSV document whose first row names the columns. Columns
+// may come in any order and all but name may be left out.
+func decodeCSV(r io.Reader) ([]Record, error) {
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err == io.EOF {
+		return []Record{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+	}
+
+	columns := make([]csvColumn, len(header))
+	seen := make(map[string]bool)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		found := false
+		for _, column := range csvColumns {
+			if column.name == name {
+				columns[i], found = column, true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("%w: unknown column %q", ErrInvalidDocument, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicate column %q", ErrInvalidDocument, name)
+		}
+		seen[name] = true
+	}
+	if !seen["name"] {
+		return nil, fmt.Errorf("%w: missing column \"name\"", ErrInvalidDocument)
+	}
+
+	records := []Record{}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDocument, err)
+		}
+		var record Record
+		for i, value := range row {
+			if err := columns[i].set(&record, value); err != nil {
+				line, _ := reader.FieldPos(i)
+				return nil, fmt.Errorf("%w: line %d: %s %v", ErrInvalidDocument, line, columns[i].name, err)
+			}
+		}
+		records = append(records, record)
+	}
+}
@@ -0,0 +1,113 @@
+package catalog
+
+import (
+	"bytes"
+	"errors"
+	"microd-api/internal/models"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	deprecated := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	records := []Record{
+		{
+			Name:              "Ledger",
+			Version:           "1.2.0",
+			Description:       "Double-entry \"books\", with commas",
+			DocumentationLink: "https://docs.example.com/ledger",
+			TeamID:            1,
+			Tags:              []string{"billing", "internal"},
+			Lifecycle:         models.LifecycleDeprecated,
+			DeprecatedAt:      &deprecated,
+			Swagger:           "openapi: 3.0.0\ninfo:\n  title: Ledger\n",
+		},
+		{Name: "Login", Lifecycle: models.LifecycleStable},
+	}
+
+	for _, format := range []string{FormatJSON, FormatYAML, FormatCSV} {
+		t.Run(format, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := Encode(&b, format, records); err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			decoded, err := Decode(&b, format)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(decoded, records) {
+				t.Errorf("Decode(Encode()) = %+v, want %+v", decoded, records)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("CSVColumnsInAnyOrder", func(t *testing.T) {
+		records, err := Decode(strings.NewReader("tags,Name\n\"a, b\",Orders\n,Billing\n"), FormatCSV)
+		if err != nil {
+			t.Fatalf("Decode() error = %v", err)
+		}
+		expected := []Record{{Name: "Orders", Tags: []string{"a", "b"}}, {Name: "Billing"}}
+		if !reflect.DeepEqual(records, expected) {
+			t.Errorf("Decode() = %+v, want %+v", records, expected)
+		}
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		for _, format := range []string{FormatJSON, FormatYAML, FormatCSV} {
+			input := ""
+			if format == FormatJSON {
+				input = "[]"
+			}
+			records, err := Decode(strings.NewReader(input), format)
+			if err != nil || records == nil || len(records) != 0 {
+				t.Errorf("Decode(%s) = %v, %v, want no records", format, records, err)
+			}
+		}
+	})
+
+	invalid := []struct {
+		name   string
+		format string
+		input  string
+	}{
+		{"JSONUnknownField", FormatJSON, `[{"name": "Orders", "team": "Commerce"}]`},
+		{"JSONNotAList", FormatJSON, `{"name": "Orders"}`},
+		{"YAMLUnknownField", FormatYAML, "- name: Orders\n  owner: Commerce\n"},
+		{"CSVUnknownColumn", FormatCSV, "name,owner\nOrders,Commerce\n"},
+		{"CSVMissingName", FormatCSV, "version\n1.0.0\n"},
+		{"CSVDuplicateColumn", FormatCSV, "name,name\nOrders,Orders\n"},
+		{"CSVBadTeam", FormatCSV, "name,team_id\nOrders,Commerce\n"},
+		{"CSVBadDate", FormatCSV, "name,sunset_at\nOrders,tomorrow\n"},
+		{"CSVShortRow", FormatCSV, "name,version\nOrders\n"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(tt.input), tt.format); !errors.Is(err, ErrInvalidDocument) {
+				t.Errorf("Decode() error = %v, want ErrInvalidDocument", err)
+			}
+		})
+	}
+
+	if _, err := Decode(strings.NewReader("[]"), "xml"); err != ErrUnknownFormat {
+		t.Errorf("Decode() error = %v, want ErrUnknownFormat", err)
+	}
+}
+
+func TestFormatFromContentType(t *testing.T) {
+	tests := map[string]string{
+		"application/json; charset=utf-8": FormatJSON,
+		"application/x-yaml":              FormatYAML,
+		"text/csv":                        FormatCSV,
+		"text/plain":                      "",
+		"":                                "",
+	}
+	for contentType, expected := range tests {
+		if format := FormatFromContentType(contentType); format != expected {
+			t.Errorf("FormatFromContentType(%q) = %q, want %q", contentType, format, expected)
+		}
+	}
+}
@@ -4,19 +4,42 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type Config struct {
-	DBPath string
-	Port   int
+	DBPath          string
+	Port            int
+	JWTSecret       string
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	AdminEmail      string
+
+	EnforceSpecCompatibility bool
+
+	TrashRetention     time.Duration
+	TrashPurgeInterval time.Duration
+
+	CacheBackend  string
+	CacheTTL      time.Duration
+	CacheMaxBytes int64
+	CacheRedisURL string
 }
 
+// Cache backends selectable with CACHE_BACKEND.
+const (
+	CacheMemory = "memory"
+	CacheLRU    = "lru"
+	CacheRedis  = "redis"
+)
+
 func Load() (*Config, error) {
 	godotenv.Load()
 
 	config := &Config{}
+	var err error
 
 	config.DBPath = os.Getenv("DB_PATH")
 	if config.DBPath == "" {
@@ -34,5 +57,94 @@ func Load() (*Config, error) {
 		config.Port = 8080
 	}
 
+	config.JWTSecret = os.Getenv("JWT_SECRET")
+
+	config.AccessTokenTTL, err = durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	config.RefreshTokenTTL, err = durationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	config.AdminEmail = os.Getenv("ADMIN_EMAIL")
+
+	config.EnforceSpecCompatibility, err = boolEnv("ENFORCE_SPEC_COMPATIBILITY", false)
+	if err != nil {
+		return nil, err
+	}
+
+	config.TrashRetention, err = durationEnv("TRASH_RETENTION", 30*24*time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	config.TrashPurgeInterval, err = durationEnv("TRASH_PURGE_INTERVAL", time.Hour)
+	if err != nil {
+		return nil, err
+	}
+
+	config.CacheBackend = os.Getenv("CACHE_BACKEND")
+	switch config.CacheBackend {
+	case "":
+		config.CacheBackend = CacheMemory
+	case CacheMemory, CacheLRU, CacheRedis:
+	default:
+		return nil, fmt.Errorf("invalid CACHE_BACKEND %q, expected memory, lru or redis", config.CacheBackend)
+	}
+
+	config.CacheTTL, err = durationEnv("CACHE_TTL", 5*time.Minute)
+	if err != nil {
+		return nil, err
+	}
+
+	config.CacheMaxBytes, err = int64Env("CACHE_MAX_BYTES", 64<<20)
+	if err != nil {
+		return nil, err
+	}
+
+	config.CacheRedisURL = os.Getenv("CACHE_REDIS_URL")
+	if config.CacheRedisURL == "" {
+		config.CacheRedisURL = "redis://localhost:6379/0"
+	}
+
 	return config, nil
 }
+
+func durationEnv(key string, fallback time.Duration) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return d, nil
+}
+
+func int64Env(key string, fallback int64) (int64, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return n, nil
+}
+
+func boolEnv(key string, fallback bool) (bool, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return b, nil
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoad(t *testing.T) {
@@ -18,12 +19,44 @@ func TestLoad(t *testing.T) {
 		if config.Port != 8080 {
 			t.Errorf("Expected default Port to be 8080, got %d", config.Port)
 		}
+		if config.AccessTokenTTL != 15*time.Minute {
+			t.Errorf("Expected default AccessTokenTTL to be 15m, got %s", config.AccessTokenTTL)
+		}
+		if config.RefreshTokenTTL != 30*24*time.Hour {
+			t.Errorf("Expected default RefreshTokenTTL to be 720h, got %s", config.RefreshTokenTTL)
+		}
+		if config.EnforceSpecCompatibility {
+			t.Errorf("Expected EnforceSpecCompatibility to be off by default")
+		}
+		if config.TrashRetention != 30*24*time.Hour {
+			t.Errorf("Expected default TrashRetention to be 720h, got %s", config.TrashRetention)
+		}
+		if config.TrashPurgeInterval != time.Hour {
+			t.Errorf("Expected default TrashPurgeInterval to be 1h, got %s", config.TrashPurgeInterval)
+		}
+		if config.CacheBackend != CacheMemory || config.CacheTTL != 5*time.Minute || config.CacheMaxBytes != 64<<20 {
+			t.Errorf("Unexpected default cache settings: %s, %s, %d", config.CacheBackend, config.CacheTTL, config.CacheMaxBytes)
+		}
+		if config.CacheRedisURL != "redis://localhost:6379/0" {
+			t.Errorf("Expected default CacheRedisURL to be redis://localhost:6379/0, got %s", config.CacheRedisURL)
+		}
 	})
 
 	t.Run("CustomValues", func(t *testing.T) {
 		os.Clearenv()
 		os.Setenv("DB_PATH", "/custom/path.db")
 		os.Setenv("PORT", "9090")
+		os.Setenv("JWT_SECRET", "s3cret")
+		os.Setenv("ACCESS_TOKEN_TTL", "5m")
+		os.Setenv("REFRESH_TOKEN_TTL", "48h")
+		os.Setenv("ADMIN_EMAIL", "ops@example.com")
+		os.Setenv("ENFORCE_SPEC_COMPATIBILITY", "true")
+		os.Setenv("TRASH_RETENTION", "168h")
+		os.Setenv("TRASH_PURGE_INTERVAL", "10m")
+		os.Setenv("CACHE_BACKEND", "redis")
+		os.Setenv("CACHE_TTL", "30s")
+		os.Setenv("CACHE_MAX_BYTES", "1024")
+		os.Setenv("CACHE_REDIS_URL", "redis://cache:6379/1")
 		config, err := Load()
 		if err != nil {
 			t.Fatalf("Load() error = %v", err)
@@ -34,6 +67,33 @@ func TestLoad(t *testing.T) {
 		if config.Port != 9090 {
 			t.Errorf("Expected Port to be 9090, got %d", config.Port)
 		}
+		if config.JWTSecret != "s3cret" {
+			t.Errorf("Expected JWTSecret to be 's3cret', got %s", config.JWTSecret)
+		}
+		if config.AccessTokenTTL != 5*time.Minute {
+			t.Errorf("Expected AccessTokenTTL to be 5m, got %s", config.AccessTokenTTL)
+		}
+		if config.RefreshTokenTTL != 48*time.Hour {
+			t.Errorf("Expected RefreshTokenTTL to be 48h, got %s", config.RefreshTokenTTL)
+		}
+		if config.AdminEmail != "ops@example.com" {
+			t.Errorf("Expected AdminEmail to be 'ops@example.com', got %s", config.AdminEmail)
+		}
+		if !config.EnforceSpecCompatibility {
+			t.Errorf("Expected EnforceSpecCompatibility to be on")
+		}
+		if config.TrashRetention != 168*time.Hour {
+			t.Errorf("Expected TrashRetention to be 168h, got %s", config.TrashRetention)
+		}
+		if config.TrashPurgeInterval != 10*time.Minute {
+			t.Errorf("Expected TrashPurgeInterval to be 10m, got %s", config.TrashPurgeInterval)
+		}
+		if config.CacheBackend != CacheRedis || config.CacheTTL != 30*time.Second || config.CacheMaxBytes != 1024 {
+			t.Errorf("Unexpected cache settings: %s, %s, %d", config.CacheBackend, config.CacheTTL, config.CacheMaxBytes)
+		}
+		if config.CacheRedisURL != "redis://cache:6379/1" {
+			t.Errorf("Expected CacheRedisURL to be redis://cache:6379/1, got %s", config.CacheRedisURL)
+		}
 	})
 
 	t.Run("InvalidPort", func(t *testing.T) {
@@ -44,4 +104,31 @@ func TestLoad(t *testing.T) {
 			t.Errorf("Expected error for invalid PORT, got nil")
 		}
 	})
+
+	t.Run("InvalidSpecCompatibility", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("ENFORCE_SPEC_COMPATIBILITY", "sometimes")
+		_, err := Load()
+		if err == nil {
+			t.Errorf("Expected error for invalid ENFORCE_SPEC_COMPATIBILITY, got nil")
+		}
+	})
+
+	t.Run("InvalidCacheBackend", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("CACHE_BACKEND", "memcached")
+		_, err := Load()
+		if err == nil {
+			t.Errorf("Expected error for invalid CACHE_BACKEND, got nil")
+		}
+	})
+
+	t.Run("InvalidTokenTTL", func(t *testing.T) {
+		os.Clearenv()
+		os.Setenv("ACCESS_TOKEN_TTL", "forever")
+		_, err := Load()
+		if err == nil {
+			t.Errorf("Expected error for invalid ACCESS_TOKEN_TTL, got nil")
+		}
+	})
 }
@@ -1,12 +1,21 @@
 package controller
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"io"
+	"microd-api/internal/catalog"
+	"microd-api/internal/graph"
+	"microd-api/internal/jsonpatch"
 	"microd-api/internal/models"
 	"microd-api/internal/service"
 	"microd-api/internal/utils"
+	"mime"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -19,17 +28,23 @@ func NewAPIController(service service.APIService) APIController {
 	return &DefaultAPIController{service: service}
 }
 
+// badRequest is the error for a request whose path, query or body cannot be
+// read, answered with a 400 problem.
+func badRequest(message string) error {
+	return utils.NewError(utils.ErrBadRequest, message)
+}
+
 func (c *DefaultAPIController) CreateAPI(w http.ResponseWriter, r *http.Request) {
-	var api models.API
-	err := json.NewDecoder(r.Body).Decode(&api)
+	var req apiRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
 		return
 	}
 
-	id, err := c.service.CreateAPI(r.Context(), api)
+	id, err := c.service.CreateAPI(r.Context(), req.API())
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error creating API")
+		utils.RespondWithProblem(w, r, err)
 		return
 	}
 
@@ -39,55 +54,159 @@ func (c *DefaultAPIController) GetAPIByID(w http.ResponseWriter, r *http.Request
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid API ID")
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
 		return
 	}
 
 	api, err := c.service.GetAPIByID(r.Context(), id)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusNotFound, "API not found")
+		utils.RespondWithProblem(w, r, err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, api)
+	etag := apiETag(api)
+	w.Header().Set("ETag", etag)
+	setLifecycleHeaders(w, api)
+	if ifNoneMatch(r, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, newAPIResponse(api))
+}
+
+// apiETag is the entity tag of an API: its row version, which changes with
+// every write.
+func apiETag(api models.API) string {
+	return `"` + strconv.FormatInt(api.RowVersion, 10) + `"`
+}
+
+// parseIfMatch reads the If-Match header, or returns nil when there is none.
+// Entity tags that are weak or were not issued by apiETag never match.
+func parseIfMatch(r *http.Request) *models.IfMatch {
+	values := r.Header.Values("If-Match")
+	if len(values) == 0 {
+		return nil
+	}
+	ifMatch := &models.IfMatch{}
+	for _, tag := range strings.Split(strings.Join(values, ","), ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			ifMatch.Any = true
+			continue
+		}
+		if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		if version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64); err == nil {
+			ifMatch.Versions = append(ifMatch.Versions, version)
+		}
+	}
+	return ifMatch
+}
+
+// ifNoneMatch reports whether the If-None-Match header lists etag, using the
+// weak comparison that RFC 9110 prescribes for it.
+func ifNoneMatch(r *http.Request, etag string) bool {
+	for _, value := range r.Header.Values("If-None-Match") {
+		for _, tag := range strings.Split(value, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setLifecycleHeaders announces that an API is deprecated with the
+// Deprecation (RFC 9745) and Sunset (RFC 8594) headers. Only deprecated and
+// retired APIs have a deprecation date.
+func setLifecycleHeaders(w http.ResponseWriter, api models.API) {
+	if api.DeprecatedAt.IsZero() {
+		return
+	}
+	w.Header().Set("Deprecation", "@"+strconv.FormatInt(api.DeprecatedAt.Unix(), 10))
+	if !api.SunsetAt.IsZero() {
+		w.Header().Set("Sunset", api.SunsetAt.UTC().Format(http.TimeFormat))
+	}
 }
 
 func (c *DefaultAPIController) UpdateAPI(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid API ID")
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
 		return
 	}
 
-	var api models.API
-	err = json.NewDecoder(r.Body).Decode(&api)
+	var req apiRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid request payload")
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
 		return
 	}
+	api := req.API()
 	api.ID = id
 
-	err = c.service.UpdateAPI(r.Context(), api)
+	err = c.service.UpdateAPI(r.Context(), api, parseIfMatch(r))
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error updating API")
+		utils.RespondWithProblem(w, r, err)
 		return
 	}
 
 	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "API updated successfully"})
 }
 
+// acceptPatch lists the patch media types PatchAPI understands, as announced
+// in the Accept-Patch header (RFC 5789).
+var acceptPatch = jsonpatch.MergePatchType + ", " + jsonpatch.JSONPatchType
+
+// PatchAPI applies a JSON Merge Patch or JSON Patch document, chosen by the
+// Content-Type of the request, and responds with the patched API.
+func (c *DefaultAPIController) PatchAPI(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != jsonpatch.MergePatchType && mediaType != jsonpatch.JSONPatchType {
+		w.Header().Set("Accept-Patch", acceptPatch)
+		utils.RespondWithProblem(w, r, utils.NewError(utils.ErrUnsupportedMediaType,
+			"Unsupported patch type, expected "+acceptPatch))
+		return
+	}
+
+	patch, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	api, err := c.service.PatchAPI(r.Context(), id, parseIfMatch(r), mediaType, patch)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	w.Header().Set("ETag", apiETag(api))
+	setLifecycleHeaders(w, api)
+	utils.RespondWithJSON(w, http.StatusOK, newAPIResponse(api))
+}
+
 func (c *DefaultAPIController) DeleteAPI(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "Invalid API ID")
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
 		return
 	}
 
-	err = c.service.DeleteAPI(r.Context(), id)
+	err = c.service.DeleteAPI(r.Context(), id, parseIfMatch(r))
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error deleting API")
+		utils.RespondWithProblem(w, r, err)
 		return
 	}
 
@@ -95,11 +214,647 @@ func (c *DefaultAPIController) DeleteAPI(w http.ResponseWriter, r *http.Request)
 }
 
 func (c *DefaultAPIController) ListAPIs(w http.ResponseWriter, r *http.Request) {
-	apis, err := c.service.ListAPIs(r.Context())
+	filter, err := parseAPIFilter(r.URL.Query())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	page, err := c.service.ListAPIs(r.Context(), filter)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIPageResponse(page))
+}
+
+// parseAPIFilter reads the query parameters of a catalog listing. The error
+// is a bad request naming the invalid parameter.
+func parseAPIFilter(query url.Values) (models.APIFilter, error) {
+	filter := models.APIFilter{
+		Version:   query.Get("version"),
+		Lifecycle: query.Get("lifecycle"),
+		Sort:      query.Get("sort"),
+		Cursor:    query.Get("cursor"),
+	}
+
+	if categoryStr := query.Get("category"); categoryStr != "" {
+		categoryID, err := strconv.ParseInt(categoryStr, 10, 64)
+		if err != nil {
+			return filter, badRequest("Invalid category ID")
+		}
+		filter.CategoryID = categoryID
+	}
+
+	if teamStr := query.Get("team_id"); teamStr != "" {
+		teamID, err := strconv.ParseInt(teamStr, 10, 64)
+		if err != nil {
+			return filter, badRequest("Invalid team ID")
+		}
+		filter.TeamID = teamID
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return filter, badRequest("Invalid limit")
+		}
+		filter.Limit = limit
+	}
+
+	for _, tags := range query["tags"] {
+		filter.Tags = append(filter.Tags, strings.Split(tags, ",")...)
+	}
+
+	return filter, nil
+}
+
+func (c *DefaultAPIController) SearchAPIs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var limit int
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			utils.RespondWithProblem(w, r, badRequest("Invalid limit"))
+			return
+		}
+	}
+
+	page, err := c.service.SearchAPIs(r.Context(), query.Get("q"), limit)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPISearchPageResponse(page))
+}
+
+func (c *DefaultAPIController) GetAPISpecOperations(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	spec, err := c.service.GetAPISpec(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, spec)
+}
+
+// DiffAPISpec compares the stored OpenAPI document with the JSON or YAML
+// document in the request body.
+func (c *DefaultAPIController) DiffAPISpec(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	candidate, err := io.ReadAll(r.Body)
+	if err != nil || len(bytes.TrimSpace(candidate)) == 0 {
+		utils.RespondWithProblem(w, r, badRequest("Request body must contain an OpenAPI document"))
+		return
+	}
+
+	report, err := c.service.DiffAPISpec(r.Context(), id, string(candidate))
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, report)
+}
+
+func (c *DefaultAPIController) ListAPICategories(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	categories, err := c.service.ListAPICategories(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newCategoryResponses(categories))
+}
+
+func (c *DefaultAPIController) AttachCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	categoryIDStr := chi.URLParam(r, "categoryID")
+	categoryID, err := strconv.ParseInt(categoryIDStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid category ID"))
+		return
+	}
+
+	err = c.service.AttachCategory(r.Context(), id, categoryID)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Category attached successfully"})
+}
+
+func (c *DefaultAPIController) DetachCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	categoryIDStr := chi.URLParam(r, "categoryID")
+	categoryID, err := strconv.ParseInt(categoryIDStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid category ID"))
+		return
+	}
+
+	err = c.service.DetachCategory(r.Context(), id, categoryID)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Category detached successfully"})
+}
+
+func (c *DefaultAPIController) ListAPIRevisions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	revisions, err := c.service.ListAPIRevisions(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIRevisionResponses(revisions))
+}
+
+func (c *DefaultAPIController) GetAPIRevision(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	revisionStr := chi.URLParam(r, "revision")
+	revision, err := strconv.ParseInt(revisionStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid revision"))
+		return
+	}
+
+	rev, err := c.service.GetAPIRevision(r.Context(), id, revision)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIRevisionResponse(rev))
+}
+
+func (c *DefaultAPIController) RestoreAPIRevision(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	revisionStr := chi.URLParam(r, "revision")
+	revision, err := strconv.ParseInt(revisionStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid revision"))
+		return
+	}
+
+	api, err := c.service.RestoreAPIRevision(r.Context(), id, revision)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIResponse(api))
+}
+
+func (c *DefaultAPIController) ListTrash(w http.ResponseWriter, r *http.Request) {
+	deleted, err := c.service.ListTrash(r.Context())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newDeletedAPIResponses(deleted))
+}
+
+func (c *DefaultAPIController) RestoreFromTrash(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	api, err := c.service.RestoreFromTrash(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIResponse(api))
+}
+
+func (c *DefaultAPIController) ListAPIVersions(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	versions, err := c.service.ListAPIVersions(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIVersionListResponse(versions))
+}
+
+func (c *DefaultAPIController) GetAPIVersion(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	version, err := c.service.GetAPIVersion(r.Context(), id, chi.URLParam(r, "version"))
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIVersionResponse(version))
+}
+
+func (c *DefaultAPIController) CreateAPIVersion(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	var req apiVersionRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+	version := req.APIVersion(id)
+
+	created, err := c.service.CreateAPIVersion(r.Context(), version)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, newAPIVersionResponse(created))
+}
+
+func (c *DefaultAPIController) UpdateAPIVersion(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	var req apiVersionRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+	version := req.APIVersion(id)
+	version.Version = chi.URLParam(r, "version")
+
+	updated, err := c.service.UpdateAPIVersion(r.Context(), version)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIVersionResponse(updated))
+}
+
+func (c *DefaultAPIController) DeleteAPIVersion(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	err = c.service.DeleteAPIVersion(r.Context(), id, chi.URLParam(r, "version"))
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Version deleted successfully"})
+}
+
+func (c *DefaultAPIController) ListTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := c.service.ListTags(r.Context())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, tags)
+}
+
+func (c *DefaultAPIController) RenameTag(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	err := c.service.RenameTag(r.Context(), chi.URLParam(r, "name"), payload.Name)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Tag renamed successfully"})
+}
+
+func (c *DefaultAPIController) MergeTags(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Into string `json:"into"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	err := c.service.MergeTags(r.Context(), chi.URLParam(r, "name"), payload.Into)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Tags merged successfully"})
+}
+
+func (c *DefaultAPIController) ListAPIDependencies(w http.ResponseWriter, r *http.Request) {
+	c.listDependencyWalk(w, r, c.service.ListAPIDependencies)
+}
+
+func (c *DefaultAPIController) ListAPIDependents(w http.ResponseWriter, r *http.Request) {
+	c.listDependencyWalk(w, r, c.service.ListAPIDependents)
+}
+
+// listDependencyWalk serves a walk of the dependency graph from the API in
+// the path. The depth query parameter limits how far it goes; by default it
+// follows every path.
+func (c *DefaultAPIController) listDependencyWalk(w http.ResponseWriter, r *http.Request,
+	walk func(ctx context.Context, id int64, maxDepth int) ([]models.DependencyNode, error)) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	var depth int
+	if depthStr := r.URL.Query().Get("depth"); depthStr != "" {
+		depth, err = strconv.Atoi(depthStr)
+		if err != nil || depth <= 0 {
+			utils.RespondWithProblem(w, r, badRequest("Invalid depth"))
+			return
+		}
+	}
+
+	nodes, err := walk(r.Context(), id, depth)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, nodes)
+}
+
+func (c *DefaultAPIController) AddAPIDependency(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	dependencyIDStr := chi.URLParam(r, "dependencyID")
+	dependencyID, err := strconv.ParseInt(dependencyIDStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid dependency ID"))
+		return
+	}
+
+	err = c.service.AddAPIDependency(r.Context(), id, dependencyID)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Dependency added successfully"})
+}
+
+func (c *DefaultAPIController) RemoveAPIDependency(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	dependencyIDStr := chi.URLParam(r, "dependencyID")
+	dependencyID, err := strconv.ParseInt(dependencyIDStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid dependency ID"))
+		return
+	}
+
+	err = c.service.RemoveAPIDependency(r.Context(), id, dependencyID)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Dependency removed successfully"})
+}
+
+func (c *DefaultAPIController) GetAPIDependencyGraph(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid API ID"))
+		return
+	}
+
+	c.respondWithDependencyGraph(w, r, id)
+}
+
+func (c *DefaultAPIController) GetDependencyGraph(w http.ResponseWriter, r *http.Request) {
+	c.respondWithDependencyGraph(w, r, 0)
+}
+
+// respondWithDependencyGraph writes the graph as JSON or, with format=dot, in
+// the Graphviz DOT language.
+func (c *DefaultAPIController) respondWithDependencyGraph(w http.ResponseWriter, r *http.Request, id int64) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "dot" {
+		utils.RespondWithProblem(w, r, badRequest("Invalid format, expected json or dot"))
+		return
+	}
+
+	g, err := c.service.GetDependencyGraph(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	if format != "dot" {
+		utils.RespondWithJSON(w, http.StatusOK, newDependencyGraphResponse(g))
+		return
+	}
+
+	nodes := make([]graph.Node, 0, len(g.Nodes))
+	for _, node := range g.Nodes {
+		nodes = append(nodes, graph.Node{ID: node.ID, Label: node.Name})
+	}
+	edges := make([]graph.Edge, 0, len(g.Edges))
+	for _, edge := range g.Edges {
+		edges = append(edges, graph.Edge{From: edge.APIID, To: edge.DependsOnID})
+	}
+
+	var b bytes.Buffer
+	if err := graph.WriteDOT(&b, "apis", nodes, edges); err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.Bytes())
+}
+
+// ExportAPIs writes the catalog as a JSON, YAML or CSV document, chosen with
+// the format query parameter. The listing filters of ListAPIs apply.
+func (c *DefaultAPIController) ExportAPIs(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = catalog.FormatJSON
+	}
+	if !catalog.ValidFormat(format) {
+		utils.RespondWithProblem(w, r, badRequest("Invalid format, expected json, yaml or csv"))
+		return
+	}
+
+	filter, err := parseAPIFilter(r.URL.Query())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	records, err := c.service.ExportAPIs(r.Context(), filter)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := catalog.Encode(&b, format, records); err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+	w.Header().Set("Content-Type", catalog.ContentType(format))
+	w.Header().Set("Content-Disposition", `attachment; filename="apis.`+format+`"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(b.Bytes())
+}
+
+// ImportAPIs reads a document in the format given by the format query
+// parameter or, without one, the Content-Type header. With mode=upsert
+// records replace the APIs of the same name, and with dry_run=true nothing is
+// written.
+func (c *DefaultAPIController) ImportAPIs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	format := query.Get("format")
+	if format == "" {
+		format = catalog.FormatFromContentType(r.Header.Get("Content-Type"))
+	}
+
+	var options models.APIImportOptions
+	switch query.Get("mode") {
+	case "", "create":
+	case "upsert":
+		options.Upsert = true
+	default:
+		utils.RespondWithProblem(w, r, badRequest("Invalid mode, expected create or upsert"))
+		return
+	}
+	if dryRun := query.Get("dry_run"); dryRun != "" {
+		var err error
+		options.DryRun, err = strconv.ParseBool(dryRun)
+		if err != nil {
+			utils.RespondWithProblem(w, r, badRequest("Invalid dry_run"))
+			return
+		}
+	}
+
+	records, err := catalog.Decode(r.Body, format)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest(err.Error()))
+		return
+	}
+
+	report, err := c.service.ImportAPIs(r.Context(), records, options)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "Error listing APIs")
+		utils.RespondWithProblem(w, r, err)
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, apis)
+	utils.RespondWithJSON(w, http.StatusOK, report)
 }
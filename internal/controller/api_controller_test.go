@@ -2,13 +2,19 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"microd-api/internal/auth"
 	"microd-api/internal/mocks"
 	"microd-api/internal/models"
 	"microd-api/internal/service"
+	"microd-api/internal/utils"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -17,15 +23,17 @@ func TestAPIController(t *testing.T) {
 	mockRepo := mocks.NewMockAPIRepository()
 	apiService := service.NewAPIService(mockRepo)
 	controller := NewAPIController(apiService)
+	admin := models.User{ID: 1, Role: models.RoleAdmin}
 
 	t.Run("CreateAPI", func(t *testing.T) {
-		api := models.API{
+		api := apiRequest{
 			Name:        "Test API",
-			Version:     "1.0",
+			Version:     "1.0.0",
 			Description: "Test Description",
 		}
 		body, _ := json.Marshal(api)
 		req, _ := http.NewRequest("POST", "/apis", bytes.NewBuffer(body))
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 
 		controller.CreateAPI(rr, req)
@@ -43,6 +51,7 @@ func TestAPIController(t *testing.T) {
 
 	t.Run("CreateAPI_InvalidJSON", func(t *testing.T) {
 		req, _ := http.NewRequest("POST", "/apis", bytes.NewBufferString("invalid json"))
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 
 		controller.CreateAPI(rr, req)
@@ -54,6 +63,7 @@ func TestAPIController(t *testing.T) {
 
 	t.Run("GetAPIByID", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/apis/1", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 
 		r := chi.NewRouter()
@@ -64,7 +74,7 @@ func TestAPIController(t *testing.T) {
 			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 		}
 
-		var response models.API
+		var response apiResponse
 		json.Unmarshal(rr.Body.Bytes(), &response)
 		if response.Name != "Test API" {
 			t.Errorf("handler returned unexpected body: got %v want %v", response.Name, "Test API")
@@ -73,6 +83,7 @@ func TestAPIController(t *testing.T) {
 
 	t.Run("GetAPIByID_NotFound", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/apis/999", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 
 		r := chi.NewRouter()
@@ -85,13 +96,14 @@ func TestAPIController(t *testing.T) {
 	})
 
 	t.Run("UpdateAPI", func(t *testing.T) {
-		api := models.API{
+		api := apiRequest{
 			Name:        "Updated API",
-			Version:     "2.0",
+			Version:     "2.0.0",
 			Description: "Updated Description",
 		}
 		body, _ := json.Marshal(api)
 		req, _ := http.NewRequest("PUT", "/apis/1", bytes.NewBuffer(body))
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 
 		r := chi.NewRouter()
@@ -105,6 +117,7 @@ func TestAPIController(t *testing.T) {
 
 	t.Run("UpdateAPI_InvalidJSON", func(t *testing.T) {
 		req, _ := http.NewRequest("PUT", "/apis/1", bytes.NewBufferString("invalid json"))
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 
 		r := chi.NewRouter()
@@ -118,6 +131,7 @@ func TestAPIController(t *testing.T) {
 
 	t.Run("DeleteAPI", func(t *testing.T) {
 		req, _ := http.NewRequest("DELETE", "/apis/1", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 
 		r := chi.NewRouter()
@@ -131,29 +145,59 @@ func TestAPIController(t *testing.T) {
 
 	t.Run("DeleteAPI_NotFound", func(t *testing.T) {
 		req, _ := http.NewRequest("DELETE", "/apis/999", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 
 		r := chi.NewRouter()
 		r.Delete("/apis/{id}", controller.DeleteAPI)
 		r.ServeHTTP(rr, req)
 
-		if status := rr.Code; status != http.StatusInternalServerError {
-			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusInternalServerError)
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+		if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("expected problem+json response, got %q", ct)
+		}
+	})
+
+	t.Run("BadRequestProblems", func(t *testing.T) {
+		r := chi.NewRouter()
+		r.Get("/apis", controller.ListAPIs)
+		r.Get("/apis/{id}", controller.GetAPIByID)
+		r.Post("/apis", controller.CreateAPI)
+
+		for _, tt := range []struct{ method, path, body string }{
+			{"GET", "/apis/abc", ""},
+			{"GET", "/apis?limit=0", ""},
+			{"POST", "/apis", "{"},
+		} {
+			req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = req.WithContext(auth.WithUser(req.Context(), admin))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			var problem utils.Problem
+			json.Unmarshal(rr.Body.Bytes(), &problem)
+			if rr.Code != http.StatusBadRequest || rr.Header().Get("Content-Type") != "application/problem+json" || problem.Status != http.StatusBadRequest || problem.Detail == "" {
+				t.Errorf("%s %s: expected a 400 problem, got %d %s", tt.method, tt.path, rr.Code, rr.Body.String())
+			}
 		}
 	})
 
 	t.Run("ListAPIs", func(t *testing.T) {
-		api := models.API{
+		api := apiRequest{
 			Name:        "Test API",
-			Version:     "1.0",
+			Version:     "1.0.0",
 			Description: "Test Description",
 		}
 		body, _ := json.Marshal(api)
 		req, _ := http.NewRequest("POST", "/apis", bytes.NewBuffer(body))
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr := httptest.NewRecorder()
 		controller.CreateAPI(rr, req)
 
 		req, _ = http.NewRequest("GET", "/apis", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
 		rr = httptest.NewRecorder()
 
 		controller.ListAPIs(rr, req)
@@ -162,10 +206,990 @@ func TestAPIController(t *testing.T) {
 			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
 		}
 
-		var response []models.API
+		var response apiPageResponse
 		json.Unmarshal(rr.Body.Bytes(), &response)
-		if len(response) != 1 {
-			t.Errorf("handler returned unexpected number of apis: got %v want %v", len(response), 1)
+		if len(response.Items) != 1 || response.Total != 1 {
+			t.Errorf("handler returned unexpected number of apis: got %v want %v", len(response.Items), 1)
+		}
+	})
+
+	t.Run("APICategories", func(t *testing.T) {
+		r := chi.NewRouter()
+		r.Get("/apis", controller.ListAPIs)
+		r.Get("/apis/{id}/categories", controller.ListAPICategories)
+		r.Put("/apis/{id}/categories/{categoryID}", controller.AttachCategory)
+		r.Delete("/apis/{id}/categories/{categoryID}", controller.DetachCategory)
+
+		req, _ := http.NewRequest("PUT", "/apis/2/categories/5", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("attach returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		req, _ = http.NewRequest("GET", "/apis/2/categories", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		var categories []categoryResponse
+		json.Unmarshal(rr.Body.Bytes(), &categories)
+		if len(categories) != 1 || categories[0].ID != 5 {
+			t.Errorf("handler returned unexpected categories: %v", categories)
+		}
+
+		req, _ = http.NewRequest("GET", "/apis?category=5", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		var page apiPageResponse
+		json.Unmarshal(rr.Body.Bytes(), &page)
+		if len(page.Items) != 1 || page.Items[0].ID != 2 {
+			t.Errorf("handler returned unexpected apis for category filter: %v", page.Items)
+		}
+
+		req, _ = http.NewRequest("GET", "/apis?category=abc", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("invalid category filter returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+
+		req, _ = http.NewRequest("DELETE", "/apis/2/categories/5", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("detach returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		req, _ = http.NewRequest("GET", "/apis/999/categories", nil)
+		req = req.WithContext(auth.WithUser(req.Context(), admin))
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("unknown API returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("WriteAuthorization", func(t *testing.T) {
+		r := chi.NewRouter()
+		r.Post("/apis", controller.CreateAPI)
+		r.Delete("/apis/{id}", controller.DeleteAPI)
+
+		body, _ := json.Marshal(apiRequest{Name: "Ledger", TeamID: 1})
+		req, _ := http.NewRequest("POST", "/apis", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler returned wrong status code without user: got %v want %v", status, http.StatusUnauthorized)
+		}
+
+		req, _ = http.NewRequest("POST", "/apis", bytes.NewBuffer(body))
+		req = req.WithContext(auth.WithUser(req.Context(), models.User{ID: 2, TeamID: 2}))
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler returned wrong status code for other team: got %v want %v", status, http.StatusForbidden)
+		}
+
+		req, _ = http.NewRequest("POST", "/apis", bytes.NewBuffer(body))
+		req = req.WithContext(auth.WithUser(req.Context(), models.User{ID: 3, TeamID: 1}))
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusCreated {
+			t.Fatalf("handler returned wrong status code for team member: got %v want %v", status, http.StatusCreated)
+		}
+
+		var created map[string]int64
+		json.Unmarshal(rr.Body.Bytes(), &created)
+		req, _ = http.NewRequest("DELETE", "/apis/"+strconv.FormatInt(created["id"], 10), nil)
+		req = req.WithContext(auth.WithUser(req.Context(), models.User{ID: 2, TeamID: 2}))
+		rr = httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("delete returned wrong status code for other team: got %v want %v", status, http.StatusForbidden)
+		}
+	})
+}
+
+func TestAPIControllerListQueries(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	for _, api := range []models.API{
+		{Name: "Ledger", TeamID: 1, Tags: []string{"billing", "public"}},
+		{Name: "Login", TeamID: 2, Tags: []string{"auth"}},
+		{Name: "Payouts", TeamID: 1, Tags: []string{"billing"}},
+	} {
+		mockRepo.CreateAPI(ctx, api, 0)
+	}
+
+	list := func(query string) (*httptest.ResponseRecorder, apiPageResponse) {
+		req, _ := http.NewRequest("GET", "/apis?"+query, nil)
+		rr := httptest.NewRecorder()
+		controller.ListAPIs(rr, req)
+		var page apiPageResponse
+		json.Unmarshal(rr.Body.Bytes(), &page)
+		return rr, page
+	}
+
+	t.Run("Pagination", func(t *testing.T) {
+		rr, page := list("limit=2")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		if len(page.Items) != 2 || page.Total != 3 || page.NextCursor == "" {
+			t.Fatalf("unexpected first page: %+v", page)
+		}
+
+		_, page = list("limit=2&cursor=" + page.NextCursor)
+		if len(page.Items) != 1 || page.NextCursor != "" {
+			t.Errorf("unexpected last page: %+v", page)
+		}
+	})
+
+	t.Run("Envelope", func(t *testing.T) {
+		rr, _ := list("limit=1")
+		var raw map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &raw)
+		for _, key := range []string{"items", "next_cursor", "total"} {
+			if _, ok := raw[key]; !ok {
+				t.Errorf("response envelope is missing %q", key)
+			}
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		_, page := list("team_id=1&tags=billing")
+		if page.Total != 2 {
+			t.Errorf("expected 2 APIs for team and tag filter, got %d", page.Total)
+		}
+
+		_, page = list("team_id=2&tags=billing")
+		if page.Total != 0 {
+			t.Errorf("expected no APIs of team 2 tagged billing, got %d", page.Total)
+		}
+
+		_, page = list("tags=billing,public")
+		if page.Total != 1 {
+			t.Errorf("expected 1 API carrying both tags, got %d", page.Total)
+		}
+	})
+
+	t.Run("InvalidQueries", func(t *testing.T) {
+		for _, query := range []string{"limit=abc", "limit=0", "limit=1000", "sort=description", "cursor=garbage", "team_id=abc"} {
+			rr, _ := list(query)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("query %q returned wrong status code: got %v want %v", query, rr.Code, http.StatusBadRequest)
+			}
+		}
+	})
+}
+
+func TestAPIControllerSearch(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := context.Background()
+
+	mockRepo.CreateAPI(ctx, models.API{Name: "Ledger", TeamID: 4}, 0)
+	mockRepo.CreateAPI(ctx, models.API{Name: "Login", TeamID: 2}, 0)
+
+	t.Run("Results", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/apis/search?q=ledger", nil)
+		rr := httptest.NewRecorder()
+		controller.SearchAPIs(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var page apiSearchPageResponse
+		json.Unmarshal(rr.Body.Bytes(), &page)
+		if page.Total != 1 || page.Items[0].API.Name != "Ledger" {
+			t.Errorf("unexpected search results: %+v", page)
+		}
+	})
+
+	t.Run("InvalidQueries", func(t *testing.T) {
+		for _, query := range []string{"", "q=", "q=ledger&limit=abc", "q=ledger&limit=1000"} {
+			req, _ := http.NewRequest("GET", "/apis/search?"+query, nil)
+			rr := httptest.NewRecorder()
+			controller.SearchAPIs(rr, req)
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("query %q returned wrong status code: got %v want %v", query, rr.Code, http.StatusBadRequest)
+			}
+		}
+	})
+}
+
+func TestAPIControllerValidation(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	admin := models.User{ID: 1, Role: models.RoleAdmin}
+
+	body, _ := json.Marshal(apiRequest{Version: "1.0", DocumentationLink: "not a url"})
+	req, _ := http.NewRequest("POST", "/apis", bytes.NewBuffer(body))
+	req = req.WithContext(auth.WithUser(req.Context(), admin))
+	rr := httptest.NewRecorder()
+
+	controller.CreateAPI(rr, req)
+
+	if rr.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusUnprocessableEntity)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected problem+json response, got %q", ct)
+	}
+
+	var problem utils.Problem
+	json.Unmarshal(rr.Body.Bytes(), &problem)
+	var fields []string
+	for _, f := range problem.Errors {
+		fields = append(fields, f.Field)
+	}
+	if strings.Join(fields, ",") != "name,version,documentation_link" {
+		t.Errorf("expected every invalid field in the response, got %v", fields)
+	}
+}
+
+func TestAPIControllerSpecOperations(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := context.Background()
+
+	spec := `{"swagger": "2.0", "info": {"title": "Ledger", "version": "1.0.0"}, "host": "ledger.example.com",
+		"paths": {"/entries": {"get": {"operationId": "listEntries", "responses": {"200": {"description": "ok"}}}}}}`
+	withSpec, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Ledger", Swagger: spec}, 0)
+	withoutSpec, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Login"}, 0)
+
+	get := func(id string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/apis/"+id+"/spec/operations", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		controller.GetAPISpecOperations(rr, req)
+		return rr
+	}
+
+	rr := get(strconv.FormatInt(withSpec, 10))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var raw map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &raw)
+	for _, key := range []string{"paths", "operations", "servers", "security_schemes"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("response is missing %q", key)
+		}
+	}
+	if ops, _ := raw["operations"].([]interface{}); len(ops) != 1 {
+		t.Errorf("expected 1 operation, got %v", raw["operations"])
+	}
+
+	if rr := get(strconv.FormatInt(withoutSpec, 10)); rr.Code != http.StatusNotFound {
+		t.Errorf("API without spec returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+	if rr := get("abc"); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid ID returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAPIControllerDiffSpec(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := context.Background()
+
+	spec := "swagger: '2.0'\ninfo: {title: Ledger, version: 1.0.0}\npaths:\n  /entries:\n    get:\n      responses: {'200': {description: ok}}\n"
+	id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Ledger", Swagger: spec}, 0)
+
+	diff := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/apis/1/spec/diff", strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", strconv.FormatInt(id, 10))
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		controller.DiffAPISpec(rr, req)
+		return rr
+	}
+
+	rr := diff("swagger: '2.0'\ninfo: {title: Ledger, version: 2.0.0}\npaths: {}\n")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var report struct {
+		Breaking bool `json:"breaking"`
+		Changes  []struct {
+			Type string `json:"type"`
+		} `json:"changes"`
+	}
+	json.Unmarshal(rr.Body.Bytes(), &report)
+	if !report.Breaking || len(report.Changes) != 1 || report.Changes[0].Type != "path-removed" {
+		t.Errorf("unexpected report: %s", rr.Body.String())
+	}
+
+	if rr := diff(""); rr.Code != http.StatusBadRequest {
+		t.Errorf("empty body returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+	if rr := diff(`{"openapi": "3.0.0"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid candidate returned wrong status code: got %v want %v", rr.Code, http.StatusUnprocessableEntity)
+	}
+}
+
+func TestAPIControllerRevisions(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	admin := models.User{ID: 1, Role: models.RoleAdmin}
+	ctx := auth.WithUser(context.Background(), admin)
+
+	id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Ledger", Version: "1.0.0"}, 0)
+	mockRepo.UpdateAPI(ctx, models.API{ID: id, Name: "Ledger", Version: "2.0.0"}, 0)
+
+	serve := func(handler http.HandlerFunc, method string, params map[string]string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/apis", nil)
+		rctx := chi.NewRouteContext()
+		for k, v := range params {
+			rctx.URLParams.Add(k, v)
+		}
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+	apiID := strconv.FormatInt(id, 10)
+
+	t.Run("List", func(t *testing.T) {
+		rr := serve(controller.ListAPIRevisions, "GET", map[string]string{"id": apiID})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var revisions []apiRevisionResponse
+		json.Unmarshal(rr.Body.Bytes(), &revisions)
+		if len(revisions) != 2 || revisions[0].Revision != 2 || revisions[0].Action != models.RevisionUpdate {
+			t.Errorf("unexpected revisions: %+v", revisions)
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		rr := serve(controller.GetAPIRevision, "GET", map[string]string{"id": apiID, "revision": "1"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var rev apiRevisionResponse
+		json.Unmarshal(rr.Body.Bytes(), &rev)
+		if rev.Snapshot.Version != "1.0.0" {
+			t.Errorf("unexpected revision: %+v", rev)
+		}
+
+		if rr := serve(controller.GetAPIRevision, "GET", map[string]string{"id": apiID, "revision": "9"}); rr.Code != http.StatusNotFound {
+			t.Errorf("unknown revision returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+		}
+		if rr := serve(controller.GetAPIRevision, "GET", map[string]string{"id": apiID, "revision": "latest"}); rr.Code != http.StatusBadRequest {
+			t.Errorf("invalid revision returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		rr := serve(controller.RestoreAPIRevision, "POST", map[string]string{"id": apiID, "revision": "1"})
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var api apiResponse
+		json.Unmarshal(rr.Body.Bytes(), &api)
+		if api.Version != "1.0.0" {
+			t.Errorf("unexpected restored API: %+v", api)
 		}
 	})
 }
+
+func TestAPIControllerTrash(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Ledger"}, 0)
+	mockRepo.DeleteAPI(ctx, id, 0, 0)
+
+	req, _ := http.NewRequest("GET", "/trash", nil)
+	rr := httptest.NewRecorder()
+	controller.ListTrash(rr, req.WithContext(ctx))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var trash []map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &trash)
+	if len(trash) != 1 || trash[0]["deleted_at"] == nil || trash[0]["purge_at"] == nil {
+		t.Errorf("unexpected trash: %s", rr.Body.String())
+	}
+
+	restore := func(id string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/trash/"+id+"/restore", nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		controller.RestoreFromTrash(rr, req)
+		return rr
+	}
+
+	if rr := restore(strconv.FormatInt(id, 10)); rr.Code != http.StatusOK {
+		t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if rr := restore(strconv.FormatInt(id, 10)); rr.Code != http.StatusNotFound {
+		t.Errorf("restoring twice returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+	if rr := restore("abc"); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid ID returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAPIControllerTags(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	mockRepo.CreateAPI(ctx, models.API{Name: "Ledger", Tags: []string{"finance", "internal"}}, 0)
+	mockRepo.CreateAPI(ctx, models.API{Name: "Payouts", Tags: []string{"finance"}}, 0)
+
+	req, _ := http.NewRequest("GET", "/tags", nil)
+	rr := httptest.NewRecorder()
+	controller.ListTags(rr, req.WithContext(ctx))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	var tags []map[string]interface{}
+	json.Unmarshal(rr.Body.Bytes(), &tags)
+	if len(tags) != 2 || tags[0]["name"] != "finance" || tags[0]["api_count"] != float64(2) {
+		t.Errorf("unexpected tags: %s", rr.Body.String())
+	}
+
+	send := func(handler http.HandlerFunc, method, name, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/tags/"+name, strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("name", name)
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		tag     string
+		body    string
+		status  int
+	}{
+		{"RenameInvalidJSON", controller.RenameTag, "PUT", "internal", `{`, http.StatusBadRequest},
+		{"RenameBlank", controller.RenameTag, "PUT", "internal", `{"name":""}`, http.StatusUnprocessableEntity},
+		{"RenameConflict", controller.RenameTag, "PUT", "internal", `{"name":"Finance"}`, http.StatusConflict},
+		{"Rename", controller.RenameTag, "PUT", "internal", `{"name":"private"}`, http.StatusOK},
+		{"MergeMissing", controller.MergeTags, "POST", "internal", `{"into":"finance"}`, http.StatusNotFound},
+		{"Merge", controller.MergeTags, "POST", "private", `{"into":"finance"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := send(tt.handler, tt.method, tt.tag, tt.body); rr.Code != tt.status {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s", rr.Code, tt.status, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestAPIControllerVersions(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Ledger"}, 0)
+	apiID := strconv.FormatInt(id, 10)
+
+	serve := func(handler http.HandlerFunc, method, version, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/apis/"+apiID+"/versions/"+version, strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", apiID)
+		rctx.URLParams.Add("version", version)
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	rr := serve(controller.CreateAPIVersion, "POST", "", `{"version": "1.0.0", "released_at": "2024-01-01T00:00:00Z", "documentation_link": "https://docs.example.com/v1", "id": 99, "created_at": "2001-01-01T00:00:00Z"}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("create returned wrong status code: got %v want %v: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	var created apiVersionResponse
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if created.ID == 99 || time.Time(created.CreatedAt).Year() == 2001 || created.DocumentationLink != "https://docs.example.com/v1" {
+		t.Errorf("expected id and created_at to be read-only: %s", rr.Body.String())
+	}
+	serve(controller.CreateAPIVersion, "POST", "", `{"version": "1.1.0", "released_at": "2024-03-01T00:00:00Z"}`)
+
+	if rr := serve(controller.CreateAPIVersion, "POST", "", `{"version": "1.0"}`); rr.Code != http.StatusUnprocessableEntity {
+		t.Errorf("invalid version returned wrong status code: got %v want %v", rr.Code, http.StatusUnprocessableEntity)
+	}
+	if rr := serve(controller.CreateAPIVersion, "POST", "", `{`); rr.Code != http.StatusBadRequest {
+		t.Errorf("invalid payload returned wrong status code: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+
+	rr = serve(controller.ListAPIVersions, "GET", "", "")
+	var list apiVersionListResponse
+	json.Unmarshal(rr.Body.Bytes(), &list)
+	if rr.Code != http.StatusOK || list.Latest != "1.1.0" || len(list.Items) != 2 || list.Items[0].Version != "1.1.0" {
+		t.Errorf("unexpected version list: %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = serve(controller.UpdateAPIVersion, "PUT", "1.1.0", `{"version": "9.9.9", "status": "deprecated", "released_at": "2024-03-01T00:00:00Z"}`)
+	var updated apiVersionResponse
+	json.Unmarshal(rr.Body.Bytes(), &updated)
+	if rr.Code != http.StatusOK || updated.Version != "1.1.0" || updated.Status != models.LifecycleDeprecated {
+		t.Errorf("expected the path to name the version being updated, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	if rr := serve(controller.GetAPIVersion, "GET", "latest", ""); rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"1.1.0"`) {
+		t.Errorf("unexpected latest version: %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := serve(controller.DeleteAPIVersion, "DELETE", "1.0.0", ""); rr.Code != http.StatusOK {
+		t.Errorf("delete returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+	}
+	if rr := serve(controller.GetAPIVersion, "GET", "1.0.0", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("deleted version returned wrong status code: got %v want %v", rr.Code, http.StatusNotFound)
+	}
+}
+
+func TestAPIControllerDeprecationHeaders(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	stable, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Current", Lifecycle: models.LifecycleStable}, 0)
+	deprecated, _ := mockRepo.CreateAPI(ctx, models.API{
+		Name:         "Legacy",
+		Lifecycle:    models.LifecycleDeprecated,
+		DeprecatedAt: time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+		SunsetAt:     time.Date(2024, 12, 31, 23, 59, 59, 0, time.UTC),
+	}, 0)
+
+	get := func(id int64) *httptest.ResponseRecorder {
+		idStr := strconv.FormatInt(id, 10)
+		req, _ := http.NewRequest("GET", "/apis/"+idStr, nil)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", idStr)
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		controller.GetAPIByID(rr, req)
+		return rr
+	}
+
+	rr := get(deprecated)
+	if got := rr.Header().Get("Deprecation"); got != "@1705276800" {
+		t.Errorf("unexpected Deprecation header: %q", got)
+	}
+	if got := rr.Header().Get("Sunset"); got != "Tue, 31 Dec 2024 23:59:59 GMT" {
+		t.Errorf("unexpected Sunset header: %q", got)
+	}
+
+	rr = get(stable)
+	if rr.Header().Get("Deprecation") != "" || rr.Header().Get("Sunset") != "" {
+		t.Errorf("expected no lifecycle headers for a stable API, got %v", rr.Header())
+	}
+}
+
+func TestAPIControllerDependencies(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	mockRepo.CreateAPI(ctx, models.API{Name: "Ledger"}, 0)
+	mockRepo.CreateAPI(ctx, models.API{Name: "Payouts"}, 0)
+	mockRepo.CreateAPI(ctx, models.API{Name: "Checkout"}, 0)
+
+	send := func(handler http.HandlerFunc, method, target string, params ...string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, target, nil)
+		rctx := chi.NewRouteContext()
+		for i := 0; i+1 < len(params); i += 2 {
+			rctx.URLParams.Add(params[i], params[i+1])
+		}
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		method  string
+		target  string
+		params  []string
+		status  int
+	}{
+		{"Add", controller.AddAPIDependency, "PUT", "/apis/2/dependencies/1", []string{"id", "2", "dependencyID", "1"}, http.StatusOK},
+		{"AddConsumer", controller.AddAPIDependency, "PUT", "/apis/3/dependencies/2", []string{"id", "3", "dependencyID", "2"}, http.StatusOK},
+		{"AddSelf", controller.AddAPIDependency, "PUT", "/apis/1/dependencies/1", []string{"id", "1", "dependencyID", "1"}, http.StatusUnprocessableEntity},
+		{"AddMissing", controller.AddAPIDependency, "PUT", "/apis/1/dependencies/9", []string{"id", "1", "dependencyID", "9"}, http.StatusNotFound},
+		{"AddInvalidID", controller.AddAPIDependency, "PUT", "/apis/1/dependencies/x", []string{"id", "1", "dependencyID", "x"}, http.StatusBadRequest},
+		{"Dependents", controller.ListAPIDependents, "GET", "/apis/1/dependents", []string{"id", "1"}, http.StatusOK},
+		{"InvalidDepth", controller.ListAPIDependencies, "GET", "/apis/3/dependencies?depth=0", []string{"id", "3"}, http.StatusBadRequest},
+		{"InvalidFormat", controller.GetDependencyGraph, "GET", "/dependencies?format=svg", nil, http.StatusBadRequest},
+		{"GraphMissing", controller.GetAPIDependencyGraph, "GET", "/apis/9/dependencies/graph", []string{"id", "9"}, http.StatusNotFound},
+		{"RemoveMissing", controller.RemoveAPIDependency, "DELETE", "/apis/1/dependencies/2", []string{"id", "1", "dependencyID", "2"}, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := send(tt.handler, tt.method, tt.target, tt.params...); rr.Code != tt.status {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s", rr.Code, tt.status, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("Walk", func(t *testing.T) {
+		rr := send(controller.ListAPIDependencies, "GET", "/apis/3/dependencies?depth=1", "id", "3")
+		var nodes []map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &nodes)
+		if len(nodes) != 1 || nodes[0]["name"] != "Payouts" || nodes[0]["depth"] != float64(1) {
+			t.Errorf("unexpected dependencies: %s", rr.Body.String())
+		}
+
+		rr = send(controller.ListAPIDependents, "GET", "/apis/1/dependents", "id", "1")
+		json.Unmarshal(rr.Body.Bytes(), &nodes)
+		if len(nodes) != 2 || nodes[1]["name"] != "Checkout" || nodes[1]["depth"] != float64(2) {
+			t.Errorf("unexpected dependents: %s", rr.Body.String())
+		}
+	})
+
+	t.Run("GraphJSON", func(t *testing.T) {
+		rr := send(controller.GetDependencyGraph, "GET", "/dependencies")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		var graph dependencyGraphResponse
+		if err := json.Unmarshal(rr.Body.Bytes(), &graph); err != nil {
+			t.Fatalf("error decoding graph: %v", err)
+		}
+		if len(graph.Nodes) != 3 || len(graph.Edges) != 2 || graph.Cycles == nil {
+			t.Errorf("unexpected graph: %s", rr.Body.String())
+		}
+		var raw struct {
+			Edges []struct {
+				CreatedAt string `json:"created_at"`
+			} `json:"edges"`
+		}
+		json.Unmarshal(rr.Body.Bytes(), &raw)
+		for _, edge := range raw.Edges {
+			if created, err := time.Parse(time.RFC3339, edge.CreatedAt); err != nil || created.Format(time.RFC3339) != edge.CreatedAt || created.Location() != time.UTC {
+				t.Errorf("expected created_at in RFC 3339 UTC, got %q", edge.CreatedAt)
+			}
+		}
+	})
+
+	t.Run("GraphDOT", func(t *testing.T) {
+		rr := send(controller.GetAPIDependencyGraph, "GET", "/apis/2/dependencies/graph?format=dot", "id", "2")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "text/vnd.graphviz; charset=utf-8" {
+			t.Errorf("unexpected Content-Type: %q", got)
+		}
+		body := rr.Body.String()
+		if !strings.HasPrefix(body, "digraph \"apis\" {") || !strings.Contains(body, "3 -> 2;") || !strings.Contains(body, `1 [label="Ledger"];`) {
+			t.Errorf("unexpected DOT output:\n%s", body)
+		}
+	})
+}
+
+func TestAPIControllerImportExport(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	mockRepo.CreateAPI(ctx, models.API{Name: "Ledger", TeamID: 1, Tags: []string{"billing"}, Lifecycle: models.LifecycleStable}, 0)
+
+	export := func(query string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("GET", "/apis/export?"+query, nil)
+		rr := httptest.NewRecorder()
+		controller.ExportAPIs(rr, req.WithContext(ctx))
+		return rr
+	}
+	importAPIs := func(query, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/apis/import?"+query, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rr := httptest.NewRecorder()
+		controller.ImportAPIs(rr, req.WithContext(ctx))
+		return rr
+	}
+
+	t.Run("ExportCSV", func(t *testing.T) {
+		rr := export("format=csv")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+		if got := rr.Header().Get("Content-Type"); got != "text/csv; charset=utf-8" {
+			t.Errorf("unexpected Content-Type: %q", got)
+		}
+		if got := rr.Header().Get("Content-Disposition"); got != `attachment; filename="apis.csv"` {
+			t.Errorf("unexpected Content-Disposition: %q", got)
+		}
+		if !strings.HasPrefix(rr.Body.String(), "name,version,") || !strings.Contains(rr.Body.String(), "Ledger,") {
+			t.Errorf("unexpected CSV:\n%s", rr.Body.String())
+		}
+	})
+
+	t.Run("ExportYAML", func(t *testing.T) {
+		rr := export("format=yaml")
+		if !strings.Contains(rr.Body.String(), "- name: Ledger\n") || !strings.Contains(rr.Body.String(), "team_id: 1\n") {
+			t.Errorf("unexpected YAML:\n%s", rr.Body.String())
+		}
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		rr := importAPIs("mode=upsert&dry_run=true", "application/yaml", "- name: Ledger\n  team_id: 1\n- name: Payouts\n  team_id: 1\n")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		var report models.APIImportReport
+		json.Unmarshal(rr.Body.Bytes(), &report)
+		if !report.DryRun || report.Created != 1 || report.Updated != 1 || report.Items[0].Changes[0] != "tags" {
+			t.Errorf("unexpected report: %s", rr.Body.String())
+		}
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		rr := importAPIs("format=csv", "text/plain", "name,team_id,tags\nPayouts,1,\"billing,public\"\n")
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		page, _ := mockRepo.ListAPIs(ctx, models.APIFilter{})
+		if page.Total != 2 || page.Items[1].Name != "Payouts" || len(page.Items[1].Tags) != 2 {
+			t.Errorf("expected Payouts to be imported, got %+v", page.Items)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		query       string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"UnknownFormat", "", "text/plain", "name\nOrders\n", http.StatusBadRequest},
+		{"InvalidMode", "mode=replace", "application/json", "[]", http.StatusBadRequest},
+		{"InvalidDryRun", "dry_run=maybe", "application/json", "[]", http.StatusBadRequest},
+		{"InvalidDocument", "", "application/json", `[{"name": "Orders", "owner": "x"}]`, http.StatusBadRequest},
+		{"Existing", "", "application/json", `[{"name": "ledger"}]`, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := importAPIs(tt.query, tt.contentType, tt.body); rr.Code != tt.status {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s", rr.Code, tt.status, rr.Body.String())
+			}
+		})
+	}
+
+	if rr := export("format=xml"); rr.Code != http.StatusBadRequest {
+		t.Errorf("handler returned wrong status code for an unknown format: got %v want %v", rr.Code, http.StatusBadRequest)
+	}
+}
+
+func TestAPIControllerPatch(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Ledger", Version: "1.0.0", ApmLink: "https://apm.example.com/ledger", Lifecycle: models.LifecycleStable}, 0)
+
+	patch := func(id, contentType, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("PATCH", "/apis/"+id, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", id)
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		controller.PatchAPI(rr, req)
+		return rr
+	}
+	idStr := strconv.FormatInt(id, 10)
+
+	t.Run("MergePatch", func(t *testing.T) {
+		rr := patch(idStr, "application/merge-patch+json; charset=utf-8", `{"description": "Double-entry books", "lifecycle": "deprecated"}`)
+		if rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		var api apiResponse
+		json.Unmarshal(rr.Body.Bytes(), &api)
+		if api.Description != "Double-entry books" || api.ApmLink != "https://apm.example.com/ledger" {
+			t.Errorf("unexpected API: %+v", api)
+		}
+		if rr.Header().Get("Deprecation") == "" {
+			t.Errorf("expected a Deprecation header")
+		}
+	})
+
+	t.Run("JSONPatch", func(t *testing.T) {
+		rr := patch(idStr, "application/json-patch+json", `[{"op": "test", "path": "/version", "value": "2.0.0"}]`)
+		if rr.Code != http.StatusConflict {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusConflict)
+		}
+	})
+
+	t.Run("UnsupportedMediaType", func(t *testing.T) {
+		rr := patch(idStr, "application/json", `{"description": ""}`)
+		if rr.Code != http.StatusUnsupportedMediaType || rr.Header().Get("Content-Type") != "application/problem+json" {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusUnsupportedMediaType)
+		}
+		if got := rr.Header().Get("Accept-Patch"); got != "application/merge-patch+json, application/json-patch+json" {
+			t.Errorf("unexpected Accept-Patch: %q", got)
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			id, contentType, body string
+			status                int
+		}{
+			{"abc", "application/merge-patch+json", `{}`, http.StatusBadRequest},
+			{idStr, "application/json-patch+json", `{"op": "add"}`, http.StatusBadRequest},
+			{idStr, "application/merge-patch+json", `{"name": ""}`, http.StatusUnprocessableEntity},
+			{"999", "application/merge-patch+json", `{}`, http.StatusNotFound},
+		}
+		for _, tt := range tests {
+			if rr := patch(tt.id, tt.contentType, tt.body); rr.Code != tt.status {
+				t.Errorf("PATCH %s %s: got %v want %v", tt.id, tt.body, rr.Code, tt.status)
+			}
+		}
+	})
+}
+
+func TestAPIControllerETag(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Ledger", Lifecycle: models.LifecycleStable}, 0)
+	idStr := strconv.FormatInt(id, 10)
+
+	serve := func(method string, handler http.HandlerFunc, body string, header http.Header) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, "/apis/"+idStr, strings.NewReader(body))
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", idStr)
+		req = req.WithContext(context.WithValue(ctx, chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+		handler(rr, req)
+		return rr
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		rr := serve("GET", controller.GetAPIByID, "", nil)
+		if got := rr.Header().Get("ETag"); got != `"1"` {
+			t.Errorf("unexpected ETag: %q", got)
+		}
+
+		for _, tag := range []string{`"1"`, `W/"1"`, `"0", "1"`, `*`} {
+			rr := serve("GET", controller.GetAPIByID, "", http.Header{"If-None-Match": {tag}})
+			if rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+				t.Errorf("If-None-Match %s: got %v with %q, want 304 without a body", tag, rr.Code, rr.Body.String())
+			}
+		}
+		if rr := serve("GET", controller.GetAPIByID, "", http.Header{"If-None-Match": {`"2"`}}); rr.Code != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		body := `{"name": "Ledger", "version": "1.0.0"}`
+		for _, tag := range []string{`"2"`, `W/"1"`, `"abc"`} {
+			rr := serve("PUT", controller.UpdateAPI, body, http.Header{"If-Match": {tag}})
+			if rr.Code != http.StatusPreconditionFailed {
+				t.Errorf("If-Match %s: got %v want %v", tag, rr.Code, http.StatusPreconditionFailed)
+			}
+		}
+		if rr := serve("PUT", controller.UpdateAPI, body, http.Header{"If-Match": {`"1"`}}); rr.Code != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+		}
+		if got := serve("GET", controller.GetAPIByID, "", nil).Header().Get("ETag"); got != `"2"` {
+			t.Errorf("expected the ETag to change, got %q", got)
+		}
+	})
+
+	t.Run("Patch", func(t *testing.T) {
+		header := http.Header{"Content-Type": {"application/merge-patch+json"}, "If-Match": {`"2"`}}
+		rr := serve("PATCH", controller.PatchAPI, `{"description": "Books"}`, header)
+		if rr.Code != http.StatusOK || rr.Header().Get("ETag") != `"3"` {
+			t.Errorf("unexpected response %v with ETag %q", rr.Code, rr.Header().Get("ETag"))
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if rr := serve("DELETE", controller.DeleteAPI, "", http.Header{"If-Match": {`"2"`}}); rr.Code != http.StatusPreconditionFailed {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusPreconditionFailed)
+		}
+		if rr := serve("DELETE", controller.DeleteAPI, "", http.Header{"If-Match": {`"1", "3"`}}); rr.Code != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", rr.Code, http.StatusOK)
+		}
+	})
+}
+
+func TestAPIControllerRepresentation(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	controller := NewAPIController(service.NewAPIService(mockRepo))
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	r := chi.NewRouter()
+	r.Post("/apis", controller.CreateAPI)
+	r.Get("/apis/{id}", controller.GetAPIByID)
+	r.Put("/apis/{id}", controller.UpdateAPI)
+	send := func(method, target, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, target, strings.NewReader(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req.WithContext(ctx))
+		return rr
+	}
+
+	// Server-managed members of the body are ignored rather than stored.
+	rr := send("POST", "/apis", `{"id": 42, "name": "Ledger", "version": "1.0.0", "documentation_link": "https://docs.example.com/ledger",
+		"created_at": "1999-12-31T23:59:59Z", "updated_at": "1999-12-31T23:59:59Z", "row_version": 9}`)
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusCreated, rr.Body.String())
+	}
+	var created map[string]int64
+	json.Unmarshal(rr.Body.Bytes(), &created)
+	if created["id"] == 42 {
+		t.Errorf("expected the ID in the body to be ignored")
+	}
+	path := "/apis/" + strconv.FormatInt(created["id"], 10)
+
+	var raw map[string]interface{}
+	json.Unmarshal(send("GET", path, ``).Body.Bytes(), &raw)
+	for _, key := range []string{"id", "name", "documentation_link", "team_id", "tags", "deprecated_at", "created_at", "updated_at"} {
+		if _, ok := raw[key]; !ok {
+			t.Errorf("expected member %q in %v", key, raw)
+		}
+	}
+	if _, ok := raw["Name"]; ok {
+		t.Errorf("expected snake_case members, got %v", raw)
+	}
+	if raw["team_id"] != nil || raw["deprecated_at"] != nil {
+		t.Errorf("expected unset values to be null, got %v", raw)
+	}
+	createdAt, _ := raw["created_at"].(string)
+	if parsed, err := time.Parse(time.RFC3339, createdAt); err != nil || !strings.HasSuffix(createdAt, "Z") || parsed.Year() == 1999 {
+		t.Errorf("expected an RFC 3339 UTC creation time set by the server, got %q", createdAt)
+	}
+
+	rr = send("PUT", path, `{"id": 42, "name": "Ledger", "version": "1.1.0", "created_at": "1999-12-31T23:59:59Z"}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", rr.Code, http.StatusOK, rr.Body.String())
+	}
+	var api apiResponse
+	json.Unmarshal(send("GET", path, ``).Body.Bytes(), &api)
+	if api.ID != created["id"] || api.Version != "1.1.0" || time.Time(api.CreatedAt).Format(time.RFC3339) != createdAt {
+		t.Errorf("expected the update to keep the ID and creation time, got %+v", api)
+	}
+}
@@ -0,0 +1,304 @@
+package controller
+
+import (
+	"encoding/json"
+	"microd-api/internal/models"
+	"time"
+)
+
+// timestamp is a time as the API documents it: RFC 3339 in UTC, or null when
+// the time is not set.
+type timestamp time.Time
+
+func (t timestamp) MarshalJSON() ([]byte, error) {
+	if time.Time(t).IsZero() {
+		return []byte("null"), nil
+	}
+	return []byte(`"` + time.Time(t).UTC().Format(time.RFC3339) + `"`), nil
+}
+
+func (t *timestamp) UnmarshalJSON(data []byte) error {
+	var value *time.Time
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if value == nil {
+		*t = timestamp{}
+		return nil
+	}
+	*t = timestamp(value.UTC())
+	return nil
+}
+
+// apiRequest is the body of a request that creates or replaces an API. The ID,
+// the timestamps and the row version are managed by the server, so they are
+// not part of it; a body that carries them, such as an API read back from
+// GetAPIByID, has them ignored.
+type apiRequest struct {
+	Name              string    `json:"name"`
+	Version           string    `json:"version"`
+	Description       string    `json:"description"`
+	DocumentationLink string    `json:"documentation_link"`
+	ForumReference    string    `json:"forum_reference"`
+	ApmLink           string    `json:"apm_link"`
+	TeamID            int64     `json:"team_id"`
+	Tags              []string  `json:"tags"`
+	Lifecycle         string    `json:"lifecycle"`
+	DeprecatedAt      timestamp `json:"deprecated_at"`
+	SunsetAt          timestamp `json:"sunset_at"`
+	Swagger           string    `json:"swagger"`
+}
+
+func (req apiRequest) API() models.API {
+	return models.API{
+		Name:              req.Name,
+		Version:           req.Version,
+		Description:       req.Description,
+		DocumentationLink: req.DocumentationLink,
+		ForumReference:    req.ForumReference,
+		ApmLink:           req.ApmLink,
+		TeamID:            req.TeamID,
+		Tags:              req.Tags,
+		Lifecycle:         req.Lifecycle,
+		DeprecatedAt:      time.Time(req.DeprecatedAt),
+		SunsetAt:          time.Time(req.SunsetAt),
+		Swagger:           req.Swagger,
+	}
+}
+
+// apiResponse is an API as it is sent to clients. It names its members the
+// way JSON patches address them.
+type apiResponse struct {
+	ID                int64     `json:"id"`
+	Name              string    `json:"name"`
+	Version           string    `json:"version"`
+	Description       string    `json:"description"`
+	DocumentationLink string    `json:"documentation_link"`
+	ForumReference    string    `json:"forum_reference"`
+	ApmLink           string    `json:"apm_link"`
+	TeamID            *int64    `json:"team_id"`
+	Tags              []string  `json:"tags"`
+	Lifecycle         string    `json:"lifecycle"`
+	DeprecatedAt      timestamp `json:"deprecated_at"`
+	SunsetAt          timestamp `json:"sunset_at"`
+	Swagger           string    `json:"swagger"`
+	CreatedAt         timestamp `json:"created_at"`
+	UpdatedAt         timestamp `json:"updated_at"`
+}
+
+func newAPIResponse(api models.API) apiResponse {
+	resp := apiResponse{
+		ID:                api.ID,
+		Name:              api.Name,
+		Version:           api.Version,
+		Description:       api.Description,
+		DocumentationLink: api.DocumentationLink,
+		ForumReference:    api.ForumReference,
+		ApmLink:           api.ApmLink,
+		Tags:              api.Tags,
+		Lifecycle:         api.Lifecycle,
+		DeprecatedAt:      timestamp(api.DeprecatedAt),
+		SunsetAt:          timestamp(api.SunsetAt),
+		Swagger:           api.Swagger,
+		CreatedAt:         timestamp(api.CreatedAt),
+		UpdatedAt:         timestamp(api.UpdatedAt),
+	}
+	if resp.Tags == nil {
+		resp.Tags = []string{}
+	}
+	if api.TeamID != 0 {
+		resp.TeamID = &api.TeamID
+	}
+	return resp
+}
+
+func newAPIResponses(apis []models.API) []apiResponse {
+	resp := make([]apiResponse, 0, len(apis))
+	for _, api := range apis {
+		resp = append(resp, newAPIResponse(api))
+	}
+	return resp
+}
+
+type apiPageResponse struct {
+	Items      []apiResponse `json:"items"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+	Total      int64         `json:"total"`
+}
+
+func newAPIPageResponse(page models.APIPage) apiPageResponse {
+	return apiPageResponse{
+		Items:      newAPIResponses(page.Items),
+		NextCursor: page.NextCursor,
+		Total:      page.Total,
+	}
+}
+
+type apiSearchResultResponse struct {
+	API     apiResponse `json:"api"`
+	Snippet string      `json:"snippet"`
+	Score   float64     `json:"score"`
+}
+
+type apiSearchPageResponse struct {
+	Items []apiSearchResultResponse `json:"items"`
+	Total int64                     `json:"total"`
+}
+
+func newAPISearchPageResponse(page models.APISearchPage) apiSearchPageResponse {
+	resp := apiSearchPageResponse{
+		Items: make([]apiSearchResultResponse, 0, len(page.Items)),
+		Total: page.Total,
+	}
+	for _, result := range page.Items {
+		resp.Items = append(resp.Items, apiSearchResultResponse{
+			API:     newAPIResponse(result.API),
+			Snippet: result.Snippet,
+			Score:   result.Score,
+		})
+	}
+	return resp
+}
+
+type deletedAPIResponse struct {
+	API       apiResponse `json:"api"`
+	DeletedAt timestamp   `json:"deleted_at"`
+	PurgeAt   timestamp   `json:"purge_at"`
+}
+
+func newDeletedAPIResponses(deleted []models.DeletedAPI) []deletedAPIResponse {
+	resp := make([]deletedAPIResponse, 0, len(deleted))
+	for _, d := range deleted {
+		resp = append(resp, deletedAPIResponse{
+			API:       newAPIResponse(d.API),
+			DeletedAt: timestamp(d.DeletedAt),
+			PurgeAt:   timestamp(d.PurgeAt),
+		})
+	}
+	return resp
+}
+
+type apiRevisionResponse struct {
+	ID        int64       `json:"id"`
+	APIID     int64       `json:"api_id"`
+	Revision  int64       `json:"revision"`
+	Action    string      `json:"action"`
+	ActorID   *int64      `json:"actor_id"`
+	Snapshot  apiResponse `json:"snapshot"`
+	CreatedAt timestamp   `json:"created_at"`
+}
+
+func newAPIRevisionResponse(rev models.APIRevision) apiRevisionResponse {
+	resp := apiRevisionResponse{
+		ID:        rev.ID,
+		APIID:     rev.APIID,
+		Revision:  rev.Revision,
+		Action:    rev.Action,
+		Snapshot:  newAPIResponse(rev.Snapshot),
+		CreatedAt: timestamp(rev.CreatedAt),
+	}
+	if rev.ActorID != 0 {
+		resp.ActorID = &rev.ActorID
+	}
+	return resp
+}
+
+func newAPIRevisionResponses(revisions []models.APIRevision) []apiRevisionResponse {
+	resp := make([]apiRevisionResponse, 0, len(revisions))
+	for _, rev := range revisions {
+		resp = append(resp, newAPIRevisionResponse(rev))
+	}
+	return resp
+}
+
+// apiVersionRequest is the body of a request that creates or replaces a
+// version. The API comes from the path, and so does the version itself when
+// it is replaced.
+type apiVersionRequest struct {
+	Version           string    `json:"version"`
+	Swagger           string    `json:"swagger"`
+	DocumentationLink string    `json:"documentation_link"`
+	Status            string    `json:"status"`
+	ReleasedAt        timestamp `json:"released_at"`
+}
+
+func (req apiVersionRequest) APIVersion(apiID int64) models.APIVersion {
+	return models.APIVersion{
+		APIID:             apiID,
+		Version:           req.Version,
+		Swagger:           req.Swagger,
+		DocumentationLink: req.DocumentationLink,
+		Status:            req.Status,
+		ReleasedAt:        time.Time(req.ReleasedAt),
+	}
+}
+
+type apiVersionResponse struct {
+	ID                int64     `json:"id"`
+	APIID             int64     `json:"api_id"`
+	Version           string    `json:"version"`
+	Swagger           string    `json:"swagger"`
+	DocumentationLink string    `json:"documentation_link"`
+	Status            string    `json:"status"`
+	ReleasedAt        timestamp `json:"released_at"`
+	CreatedAt         timestamp `json:"created_at"`
+	UpdatedAt         timestamp `json:"updated_at"`
+}
+
+func newAPIVersionResponse(version models.APIVersion) apiVersionResponse {
+	return apiVersionResponse{
+		ID:                version.ID,
+		APIID:             version.APIID,
+		Version:           version.Version,
+		Swagger:           version.Swagger,
+		DocumentationLink: version.DocumentationLink,
+		Status:            version.Status,
+		ReleasedAt:        timestamp(version.ReleasedAt),
+		CreatedAt:         timestamp(version.CreatedAt),
+		UpdatedAt:         timestamp(version.UpdatedAt),
+	}
+}
+
+type apiVersionListResponse struct {
+	Latest string               `json:"latest,omitempty"`
+	Items  []apiVersionResponse `json:"items"`
+}
+
+func newAPIVersionListResponse(list models.APIVersionList) apiVersionListResponse {
+	resp := apiVersionListResponse{
+		Latest: list.Latest,
+		Items:  make([]apiVersionResponse, 0, len(list.Items)),
+	}
+	for _, version := range list.Items {
+		resp.Items = append(resp.Items, newAPIVersionResponse(version))
+	}
+	return resp
+}
+
+type apiDependencyResponse struct {
+	APIID       int64     `json:"api_id"`
+	DependsOnID int64     `json:"depends_on_id"`
+	CreatedAt   timestamp `json:"created_at"`
+}
+
+type dependencyGraphResponse struct {
+	Nodes  []models.DependencyNode `json:"nodes"`
+	Edges  []apiDependencyResponse `json:"edges"`
+	Cycles [][]int64               `json:"cycles"`
+}
+
+func newDependencyGraphResponse(g models.DependencyGraph) dependencyGraphResponse {
+	resp := dependencyGraphResponse{
+		Nodes:  g.Nodes,
+		Edges:  make([]apiDependencyResponse, 0, len(g.Edges)),
+		Cycles: g.Cycles,
+	}
+	for _, edge := range g.Edges {
+		resp.Edges = append(resp.Edges, apiDependencyResponse{
+			APIID:       edge.APIID,
+			DependsOnID: edge.DependsOnID,
+			CreatedAt:   timestamp(edge.CreatedAt),
+		})
+	}
+	return resp
+}
@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"encoding/json"
+	"microd-api/internal/service"
+	"microd-api/internal/utils"
+	"net/http"
+)
+
+type DefaultAuthController struct {
+	service service.AuthService
+}
+
+func NewAuthController(service service.AuthService) AuthController {
+	return &DefaultAuthController{service: service}
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (c *DefaultAuthController) Login(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	tokens, err := c.service.Login(r.Context(), payload.Email, payload.Password)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, tokens)
+}
+
+func (c *DefaultAuthController) Refresh(w http.ResponseWriter, r *http.Request) {
+	var payload refreshTokenRequest
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil || payload.RefreshToken == "" {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	tokens, err := c.service.Refresh(r.Context(), payload.RefreshToken)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, tokens)
+}
+
+func (c *DefaultAuthController) Logout(w http.ResponseWriter, r *http.Request) {
+	var payload refreshTokenRequest
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil || payload.RefreshToken == "" {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	err = c.service.Logout(r.Context(), payload.RefreshToken)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Logged out successfully"})
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"microd-api/internal/auth"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"microd-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestAuthController(t *testing.T) {
+	mockRepo := mocks.NewMockUserRepository()
+	tokens := auth.NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	authService := service.NewAuthService(mockRepo, tokens)
+	controller := NewAuthController(authService)
+
+	_, err := service.NewUserService(mockRepo).RegisterUser(context.Background(), models.User{Name: "Ada", Email: "ada@example.com"}, "correct horse")
+	if err != nil {
+		t.Fatalf("error registering user: %v", err)
+	}
+
+	var pair auth.TokenPair
+
+	t.Run("Login", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"email": "ada@example.com", "password": "correct horse"})
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		controller.Login(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		json.Unmarshal(rr.Body.Bytes(), &pair)
+		if pair.AccessToken == "" || pair.RefreshToken == "" {
+			t.Errorf("response is missing tokens: %s", rr.Body.String())
+		}
+	})
+
+	t.Run("Login_InvalidCredentials", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"email": "ada@example.com", "password": "wrong"})
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		controller.Login(rr, req)
+
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("Login_InvalidJSON", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/login", bytes.NewBufferString("invalid json"))
+		rr := httptest.NewRecorder()
+
+		controller.Login(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"refresh_token": pair.RefreshToken})
+		req, _ := http.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		controller.Refresh(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+		json.Unmarshal(rr.Body.Bytes(), &pair)
+	})
+
+	t.Run("Refresh_MissingToken", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/auth/refresh", bytes.NewBufferString(`{}`))
+		rr := httptest.NewRecorder()
+
+		controller.Refresh(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"refresh_token": pair.RefreshToken})
+		req, _ := http.NewRequest("POST", "/auth/logout", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		controller.Logout(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		req, _ = http.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(body))
+		rr = httptest.NewRecorder()
+
+		controller.Refresh(rr, req)
+
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("refresh after logout returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+		}
+	})
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"encoding/json"
+	"microd-api/internal/models"
+	"microd-api/internal/service"
+	"microd-api/internal/utils"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type DefaultCategoryController struct {
+	service service.CategoryService
+}
+
+func NewCategoryController(service service.CategoryService) CategoryController {
+	return &DefaultCategoryController{service: service}
+}
+
+func (c *DefaultCategoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var req categoryRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		utils.RespondWithProblem(w, r, badRequest("Category name is required"))
+		return
+	}
+
+	id, err := c.service.CreateCategory(r.Context(), models.APICategory{Name: req.Name})
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, map[string]int64{"id": id})
+}
+
+func (c *DefaultCategoryController) GetCategoryByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid category ID"))
+		return
+	}
+
+	category, err := c.service.GetCategoryByID(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newCategoryResponse(category))
+}
+
+func (c *DefaultCategoryController) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid category ID"))
+		return
+	}
+
+	var req categoryRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		utils.RespondWithProblem(w, r, badRequest("Category name is required"))
+		return
+	}
+
+	err = c.service.UpdateCategory(r.Context(), models.APICategory{ID: id, Name: req.Name})
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Category updated successfully"})
+}
+
+func (c *DefaultCategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid category ID"))
+		return
+	}
+
+	err = c.service.DeleteCategory(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Category deleted successfully"})
+}
+
+func (c *DefaultCategoryController) ListCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := c.service.ListCategories(r.Context())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newCategoryResponses(categories))
+}
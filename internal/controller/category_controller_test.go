@@ -0,0 +1,204 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"microd-api/internal/auth"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"microd-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestCategoryController(t *testing.T) {
+	mockRepo := mocks.NewMockCategoryRepository()
+	categoryService := service.NewCategoryService(mockRepo)
+	controller := NewCategoryController(categoryService)
+
+	// caller is the user requests are made as; the zero User makes them
+	// anonymous.
+	caller := models.User{ID: 1, Role: models.RoleAdmin}
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if caller.ID != 0 {
+				req = req.WithContext(auth.WithUser(req.Context(), caller))
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Post("/categories", controller.CreateCategory)
+	r.Get("/categories", controller.ListCategories)
+	r.Get("/categories/{id}", controller.GetCategoryByID)
+	r.Put("/categories/{id}", controller.UpdateCategory)
+	r.Delete("/categories/{id}", controller.DeleteCategory)
+
+	t.Run("CreateCategory", func(t *testing.T) {
+		body, _ := json.Marshal(categoryRequest{Name: "Payments"})
+		req, _ := http.NewRequest("POST", "/categories", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+		}
+
+		var response map[string]int64
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if _, exists := response["id"]; !exists {
+			t.Errorf("response doesn't contain id field")
+		}
+	})
+
+	t.Run("CreateCategory_MissingName", func(t *testing.T) {
+		body, _ := json.Marshal(categoryRequest{Name: "  "})
+		req, _ := http.NewRequest("POST", "/categories", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("CreateCategory_InvalidJSON", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/categories", bytes.NewBufferString("invalid json"))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("GetCategoryByID", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/categories/1", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		var response categoryResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if response.Name != "Payments" {
+			t.Errorf("handler returned unexpected body: got %v want %v", response.Name, "Payments")
+		}
+	})
+
+	t.Run("GetCategoryByID_NotFound", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/categories/999", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("UpdateCategory", func(t *testing.T) {
+		body, _ := json.Marshal(categoryRequest{Name: "Billing"})
+		req, _ := http.NewRequest("PUT", "/categories/1", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("ListCategories", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/categories", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		var response []categoryResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if len(response) != 1 || response[0].Name != "Billing" {
+			t.Errorf("handler returned unexpected categories: %v", response)
+		}
+	})
+
+	t.Run("DeleteCategory", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/categories/1", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("DeleteCategory_NotFound", func(t *testing.T) {
+		req, _ := http.NewRequest("DELETE", "/categories/1", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("Authorization", func(t *testing.T) {
+		admin := caller
+		defer func() { caller = admin }()
+
+		tests := []struct {
+			name   string
+			caller models.User
+			method string
+			path   string
+			body   string
+			status int
+		}{
+			{"CreateAnonymous", models.User{}, "POST", "/categories", `{"name": "Risk"}`, http.StatusUnauthorized},
+			{"CreateMember", models.User{ID: 2}, "POST", "/categories", `{"name": "Risk"}`, http.StatusForbidden},
+			{"UpdateMember", models.User{ID: 2}, "PUT", "/categories/1", `{"name": "Risk"}`, http.StatusForbidden},
+			{"DeleteMember", models.User{ID: 2}, "DELETE", "/categories/1", ``, http.StatusForbidden},
+			{"ListMember", models.User{ID: 2}, "GET", "/categories", ``, http.StatusOK},
+		}
+		for _, tt := range tests {
+			caller = tt.caller
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.status {
+				t.Errorf("%s: handler returned wrong status code: got %v want %v", tt.name, status, tt.status)
+			}
+		}
+	})
+
+	t.Run("CreateCategory_Duplicate", func(t *testing.T) {
+		body, _ := json.Marshal(categoryRequest{Name: "Identity"})
+		for _, want := range []int{http.StatusCreated, http.StatusConflict} {
+			req, _ := http.NewRequest("POST", "/categories", bytes.NewBuffer(body))
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != want {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, want)
+			}
+		}
+	})
+}
@@ -0,0 +1,33 @@
+package controller
+
+import "microd-api/internal/models"
+
+// categoryRequest is the body of a request that creates or renames a
+// category.
+type categoryRequest struct {
+	Name string `json:"name"`
+}
+
+type categoryResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt timestamp `json:"created_at"`
+	UpdatedAt timestamp `json:"updated_at"`
+}
+
+func newCategoryResponse(category models.APICategory) categoryResponse {
+	return categoryResponse{
+		ID:        category.ID,
+		Name:      category.Name,
+		CreatedAt: timestamp(category.CreatedAt),
+		UpdatedAt: timestamp(category.UpdatedAt),
+	}
+}
+
+func newCategoryResponses(categories []models.APICategory) []categoryResponse {
+	resp := make([]categoryResponse, 0, len(categories))
+	for _, category := range categories {
+		resp = append(resp, newCategoryResponse(category))
+	}
+	return resp
+}
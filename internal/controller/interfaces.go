@@ -8,6 +8,70 @@ type APIController interface {
 	CreateAPI(w http.ResponseWriter, r *http.Request)
 	GetAPIByID(w http.ResponseWriter, r *http.Request)
 	UpdateAPI(w http.ResponseWriter, r *http.Request)
+	PatchAPI(w http.ResponseWriter, r *http.Request)
 	DeleteAPI(w http.ResponseWriter, r *http.Request)
 	ListAPIs(w http.ResponseWriter, r *http.Request)
+	SearchAPIs(w http.ResponseWriter, r *http.Request)
+	GetAPISpecOperations(w http.ResponseWriter, r *http.Request)
+	DiffAPISpec(w http.ResponseWriter, r *http.Request)
+	ListAPICategories(w http.ResponseWriter, r *http.Request)
+	AttachCategory(w http.ResponseWriter, r *http.Request)
+	DetachCategory(w http.ResponseWriter, r *http.Request)
+	ListAPIRevisions(w http.ResponseWriter, r *http.Request)
+	GetAPIRevision(w http.ResponseWriter, r *http.Request)
+	RestoreAPIRevision(w http.ResponseWriter, r *http.Request)
+	ListTrash(w http.ResponseWriter, r *http.Request)
+	RestoreFromTrash(w http.ResponseWriter, r *http.Request)
+	ListAPIVersions(w http.ResponseWriter, r *http.Request)
+	GetAPIVersion(w http.ResponseWriter, r *http.Request)
+	CreateAPIVersion(w http.ResponseWriter, r *http.Request)
+	UpdateAPIVersion(w http.ResponseWriter, r *http.Request)
+	DeleteAPIVersion(w http.ResponseWriter, r *http.Request)
+	ListTags(w http.ResponseWriter, r *http.Request)
+	RenameTag(w http.ResponseWriter, r *http.Request)
+	MergeTags(w http.ResponseWriter, r *http.Request)
+	ListAPIDependencies(w http.ResponseWriter, r *http.Request)
+	ListAPIDependents(w http.ResponseWriter, r *http.Request)
+	AddAPIDependency(w http.ResponseWriter, r *http.Request)
+	RemoveAPIDependency(w http.ResponseWriter, r *http.Request)
+	GetAPIDependencyGraph(w http.ResponseWriter, r *http.Request)
+	GetDependencyGraph(w http.ResponseWriter, r *http.Request)
+	ExportAPIs(w http.ResponseWriter, r *http.Request)
+	ImportAPIs(w http.ResponseWriter, r *http.Request)
+}
+
+type CategoryController interface {
+	CreateCategory(w http.ResponseWriter, r *http.Request)
+	GetCategoryByID(w http.ResponseWriter, r *http.Request)
+	UpdateCategory(w http.ResponseWriter, r *http.Request)
+	DeleteCategory(w http.ResponseWriter, r *http.Request)
+	ListCategories(w http.ResponseWriter, r *http.Request)
+}
+
+type TeamController interface {
+	CreateTeam(w http.ResponseWriter, r *http.Request)
+	GetTeamByID(w http.ResponseWriter, r *http.Request)
+	UpdateTeam(w http.ResponseWriter, r *http.Request)
+	DeleteTeam(w http.ResponseWriter, r *http.Request)
+	ListTeams(w http.ResponseWriter, r *http.Request)
+	ListTeamMembers(w http.ResponseWriter, r *http.Request)
+	AddTeamMember(w http.ResponseWriter, r *http.Request)
+	RemoveTeamMember(w http.ResponseWriter, r *http.Request)
+	ListTeamAPIs(w http.ResponseWriter, r *http.Request)
+}
+
+type UserController interface {
+	RegisterUser(w http.ResponseWriter, r *http.Request)
+	GetUserByID(w http.ResponseWriter, r *http.Request)
+	GetCurrentUser(w http.ResponseWriter, r *http.Request)
+	UpdateUser(w http.ResponseWriter, r *http.Request)
+	UpdateUserRole(w http.ResponseWriter, r *http.Request)
+	UpdateUserTeam(w http.ResponseWriter, r *http.Request)
+	ListUsers(w http.ResponseWriter, r *http.Request)
+}
+
+type AuthController interface {
+	Login(w http.ResponseWriter, r *http.Request)
+	Refresh(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
 }
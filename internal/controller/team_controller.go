@@ -0,0 +1,185 @@
+package controller
+
+import (
+	"encoding/json"
+	"microd-api/internal/service"
+	"microd-api/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type DefaultTeamController struct {
+	service service.TeamService
+}
+
+func NewTeamController(service service.TeamService) TeamController {
+	return &DefaultTeamController{service: service}
+}
+
+func (c *DefaultTeamController) CreateTeam(w http.ResponseWriter, r *http.Request) {
+	var req teamRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	id, err := c.service.CreateTeam(r.Context(), req.Team())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, map[string]int64{"id": id})
+}
+
+func (c *DefaultTeamController) GetTeamByID(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid team ID"))
+		return
+	}
+
+	team, err := c.service.GetTeamByID(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newTeamResponse(team))
+}
+
+func (c *DefaultTeamController) UpdateTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid team ID"))
+		return
+	}
+
+	var req teamRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+	team := req.Team()
+	team.ID = id
+
+	err = c.service.UpdateTeam(r.Context(), team)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Team updated successfully"})
+}
+
+func (c *DefaultTeamController) DeleteTeam(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid team ID"))
+		return
+	}
+
+	err = c.service.DeleteTeam(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Team deleted successfully"})
+}
+
+func (c *DefaultTeamController) ListTeams(w http.ResponseWriter, r *http.Request) {
+	teams, err := c.service.ListTeams(r.Context())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newTeamResponses(teams))
+}
+
+func (c *DefaultTeamController) ListTeamMembers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid team ID"))
+		return
+	}
+
+	users, err := c.service.ListTeamMembers(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newUserResponses(users))
+}
+
+func (c *DefaultTeamController) AddTeamMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid team ID"))
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid user ID"))
+		return
+	}
+
+	err = c.service.AddTeamMember(r.Context(), id, userID)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Team member added successfully"})
+}
+
+func (c *DefaultTeamController) RemoveTeamMember(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid team ID"))
+		return
+	}
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid user ID"))
+		return
+	}
+
+	err = c.service.RemoveTeamMember(r.Context(), id, userID)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "Team member removed successfully"})
+}
+
+func (c *DefaultTeamController) ListTeamAPIs(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid team ID"))
+		return
+	}
+
+	filter, err := parseAPIFilter(r.URL.Query())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	page, err := c.service.ListTeamAPIs(r.Context(), id, filter)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newAPIPageResponse(page))
+}
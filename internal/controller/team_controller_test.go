@@ -0,0 +1,101 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"microd-api/internal/auth"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"microd-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestTeamController(t *testing.T) {
+	apiRepo := mocks.NewMockAPIRepository()
+	userRepo := mocks.NewMockUserRepository()
+	teamService := service.NewTeamService(mocks.NewMockTeamRepository(apiRepo, userRepo), service.NewAPIService(apiRepo))
+	controller := NewTeamController(teamService)
+
+	r := chi.NewRouter()
+	r.Post("/teams", controller.CreateTeam)
+	r.Get("/teams", controller.ListTeams)
+	r.Get("/teams/{id}", controller.GetTeamByID)
+	r.Put("/teams/{id}", controller.UpdateTeam)
+	r.Delete("/teams/{id}", controller.DeleteTeam)
+	r.Get("/teams/{id}/apis", controller.ListTeamAPIs)
+	r.Get("/teams/{id}/members", controller.ListTeamMembers)
+	r.Put("/teams/{id}/members/{userID}", controller.AddTeamMember)
+	r.Delete("/teams/{id}/members/{userID}", controller.RemoveTeamMember)
+
+	admin := auth.WithUser(context.Background(), models.User{ID: 100, Role: models.RoleAdmin})
+	send := func(method, path, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, bytes.NewBufferString(body))
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req.WithContext(admin))
+		return rr
+	}
+
+	userID, _ := userRepo.CreateUser(admin, models.User{Name: "Ada", Email: "ada@example.com"})
+	apiRepo.CreateAPI(admin, models.API{Name: "Payouts", TeamID: 1}, 0)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{"CreateTeam", "POST", "/teams", `{"name": "Payments"}`, http.StatusCreated},
+		{"CreateTeam_InvalidJSON", "POST", "/teams", `{`, http.StatusBadRequest},
+		{"CreateTeam_MissingName", "POST", "/teams", `{"name": " "}`, http.StatusUnprocessableEntity},
+		{"CreateTeam_Duplicate", "POST", "/teams", `{"name": "payments"}`, http.StatusConflict},
+		{"GetTeamByID", "GET", "/teams/1", ``, http.StatusOK},
+		{"GetTeamByID_InvalidID", "GET", "/teams/abc", ``, http.StatusBadRequest},
+		{"GetTeamByID_NotFound", "GET", "/teams/42", ``, http.StatusNotFound},
+		{"UpdateTeam", "PUT", "/teams/1", `{"name": "Treasury", "description": "Money movement"}`, http.StatusOK},
+		{"ListTeams", "GET", "/teams", ``, http.StatusOK},
+		{"AddTeamMember", "PUT", "/teams/1/members/1", ``, http.StatusOK},
+		{"AddTeamMember_InvalidUserID", "PUT", "/teams/1/members/abc", ``, http.StatusBadRequest},
+		{"AddTeamMember_UnknownTeam", "PUT", "/teams/42/members/1", ``, http.StatusNotFound},
+		{"ListTeamMembers", "GET", "/teams/1/members", ``, http.StatusOK},
+		{"ListTeamAPIs", "GET", "/teams/1/apis?sort=name&limit=5", ``, http.StatusOK},
+		{"ListTeamAPIs_InvalidLimit", "GET", "/teams/1/apis?limit=0", ``, http.StatusBadRequest},
+		{"ListTeamAPIs_UnknownTeam", "GET", "/teams/42/apis", ``, http.StatusNotFound},
+		{"DeleteTeam_OwnsAPIs", "DELETE", "/teams/1", ``, http.StatusConflict},
+		{"RemoveTeamMember", "DELETE", "/teams/1/members/1", ``, http.StatusOK},
+		{"RemoveTeamMember_NotMember", "DELETE", "/teams/1/members/1", ``, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rr := send(tt.method, tt.path, tt.body); rr.Code != tt.status {
+				t.Errorf("handler returned wrong status code: got %v want %v: %s", rr.Code, tt.status, rr.Body.String())
+			}
+		})
+	}
+
+	t.Run("Responses", func(t *testing.T) {
+		var team teamResponse
+		json.Unmarshal(send("GET", "/teams/1", ``).Body.Bytes(), &team)
+		if team.Name != "Treasury" || team.Description != "Money movement" {
+			t.Errorf("unexpected team: %+v", team)
+		}
+
+		send("PUT", "/teams/1/members/1", ``)
+		var members []userResponse
+		json.Unmarshal(send("GET", "/teams/1/members", ``).Body.Bytes(), &members)
+		if len(members) != 1 || members[0].ID != userID || members[0].TeamID == nil || *members[0].TeamID != 1 {
+			t.Errorf("unexpected members: %+v", members)
+		}
+
+		var page apiPageResponse
+		json.Unmarshal(send("GET", "/teams/1/apis", ``).Body.Bytes(), &page)
+		if page.Total != 1 || page.Items[0].Name != "Payouts" {
+			t.Errorf("unexpected team APIs: %+v", page)
+		}
+	})
+}
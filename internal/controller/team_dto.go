@@ -0,0 +1,39 @@
+package controller
+
+import "microd-api/internal/models"
+
+// teamRequest is the body of a request that creates or renames a team.
+type teamRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+func (req teamRequest) Team() models.Team {
+	return models.Team{Name: req.Name, Description: req.Description}
+}
+
+type teamResponse struct {
+	ID          int64     `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   timestamp `json:"created_at"`
+	UpdatedAt   timestamp `json:"updated_at"`
+}
+
+func newTeamResponse(team models.Team) teamResponse {
+	return teamResponse{
+		ID:          team.ID,
+		Name:        team.Name,
+		Description: team.Description,
+		CreatedAt:   timestamp(team.CreatedAt),
+		UpdatedAt:   timestamp(team.UpdatedAt),
+	}
+}
+
+func newTeamResponses(teams []models.Team) []teamResponse {
+	resp := make([]teamResponse, 0, len(teams))
+	for _, team := range teams {
+		resp = append(resp, newTeamResponse(team))
+	}
+	return resp
+}
@@ -0,0 +1,156 @@
+package controller
+
+import (
+	"encoding/json"
+	"microd-api/internal/models"
+	"microd-api/internal/service"
+	"microd-api/internal/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type DefaultUserController struct {
+	service service.UserService
+}
+
+func NewUserController(service service.UserService) UserController {
+	return &DefaultUserController{service: service}
+}
+
+func (c *DefaultUserController) RegisterUser(w http.ResponseWriter, r *http.Request) {
+	var payload struct {
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Avatar   string `json:"avatar"`
+		Password string `json:"password"`
+	}
+	err := json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	user := models.User{Name: payload.Name, Email: payload.Email, Avatar: payload.Avatar}
+	id, err := c.service.RegisterUser(r.Context(), user, payload.Password)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusCreated, map[string]int64{"id": id})
+}
+
+func (c *DefaultUserController) GetUserByID(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid user ID"))
+		return
+	}
+
+	user, err := c.service.GetUserByID(r.Context(), id)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newUserResponse(user))
+}
+
+func (c *DefaultUserController) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user, err := c.service.GetCurrentUser(r.Context())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newUserResponse(user))
+}
+
+func (c *DefaultUserController) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid user ID"))
+		return
+	}
+
+	var req userRequest
+	err = json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	err = c.service.UpdateUser(r.Context(), models.User{ID: id, Name: req.Name, Avatar: req.Avatar})
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "User updated successfully"})
+}
+
+func (c *DefaultUserController) UpdateUserRole(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid user ID"))
+		return
+	}
+
+	var payload struct {
+		Role *int64 `json:"role"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil || payload.Role == nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	err = c.service.UpdateUserRole(r.Context(), id, *payload.Role)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "User role updated successfully"})
+}
+
+func (c *DefaultUserController) UpdateUserTeam(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid user ID"))
+		return
+	}
+
+	var payload struct {
+		TeamID *int64 `json:"team_id"`
+	}
+	err = json.NewDecoder(r.Body).Decode(&payload)
+	if err != nil || payload.TeamID == nil {
+		utils.RespondWithProblem(w, r, badRequest("Invalid request payload"))
+		return
+	}
+
+	err = c.service.UpdateUserTeam(r.Context(), id, *payload.TeamID)
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, map[string]string{"message": "User team updated successfully"})
+}
+
+func (c *DefaultUserController) ListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := c.service.ListUsers(r.Context())
+	if err != nil {
+		utils.RespondWithProblem(w, r, err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, newUserResponses(users))
+}
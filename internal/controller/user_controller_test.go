@@ -0,0 +1,209 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"microd-api/internal/auth"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"microd-api/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestUserController(t *testing.T) {
+	mockRepo := mocks.NewMockUserRepository()
+	userService := service.NewUserService(mockRepo)
+	controller := NewUserController(userService)
+
+	r := chi.NewRouter()
+	r.Post("/users", controller.RegisterUser)
+	r.Get("/users", controller.ListUsers)
+	r.Get("/users/me", controller.GetCurrentUser)
+	r.Get("/users/{id}", controller.GetUserByID)
+	r.Put("/users/{id}", controller.UpdateUser)
+	r.Put("/users/{id}/role", controller.UpdateUserRole)
+	r.Put("/users/{id}/team", controller.UpdateUserTeam)
+
+	asUser := func(req *http.Request, user models.User) *http.Request {
+		return req.WithContext(auth.WithUser(req.Context(), user))
+	}
+	admin := models.User{ID: 100, Role: models.RoleAdmin}
+	member := models.User{ID: 1, Role: models.RoleUser}
+
+	t.Run("RegisterUser", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"name": "Ada", "email": "ada@example.com", "password": "correct horse"})
+		req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+		}
+	})
+
+	t.Run("RegisterUser_DuplicateEmail", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"name": "Ada", "email": "ada@example.com", "password": "correct horse"})
+		req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusConflict {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusConflict)
+		}
+	})
+
+	t.Run("RegisterUser_Invalid", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"name": "Ada", "email": "not-an-email", "password": "correct horse"})
+		req, _ := http.NewRequest("POST", "/users", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusUnprocessableEntity {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("GetUserByID", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/users/1", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+
+		var response map[string]interface{}
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if _, exists := response["RefreshToken"]; exists {
+			t.Errorf("response must not expose the refresh token")
+		}
+		if _, exists := response["PasswordHash"]; exists {
+			t.Errorf("response must not expose the password hash")
+		}
+	})
+
+	t.Run("GetCurrentUser_Unauthenticated", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/users/me", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("GetCurrentUser", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/users/me", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, member))
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		body, _ := json.Marshal(userRequest{Name: "Ada Lovelace", Avatar: "http://avatar.example.com/ada.png"})
+		req, _ := http.NewRequest("PUT", "/users/1", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, member))
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("UpdateUser_Forbidden", func(t *testing.T) {
+		body, _ := json.Marshal(userRequest{Name: "Hijacked"})
+		req, _ := http.NewRequest("PUT", "/users/1", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, models.User{ID: 2}))
+
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusForbidden)
+		}
+	})
+
+	t.Run("UpdateUserRole", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/users/1/role", bytes.NewBufferString(`{"role": 1}`))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, member))
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler returned wrong status code for non-admin: got %v want %v", status, http.StatusForbidden)
+		}
+
+		req, _ = http.NewRequest("PUT", "/users/1/role", bytes.NewBufferString(`{"role": 1}`))
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, admin))
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code for admin: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("UpdateUserRole_MissingRole", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/users/1/role", bytes.NewBufferString(`{}`))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, admin))
+
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("UpdateUserTeam", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/users/1/team", bytes.NewBufferString(`{"team_id": 1}`))
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, member))
+		if status := rr.Code; status != http.StatusForbidden {
+			t.Errorf("handler returned wrong status code for non-admin: got %v want %v", status, http.StatusForbidden)
+		}
+
+		req, _ = http.NewRequest("PUT", "/users/1/team", bytes.NewBufferString(`{"team_id": 1}`))
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, admin))
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code for admin: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("ListUsers", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/users", nil)
+		rr := httptest.NewRecorder()
+
+		r.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler returned wrong status code without user: got %v want %v", status, http.StatusUnauthorized)
+		}
+
+		req, _ = http.NewRequest("GET", "/users", nil)
+		rr = httptest.NewRecorder()
+
+		r.ServeHTTP(rr, asUser(req, admin))
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code for admin: got %v want %v", status, http.StatusOK)
+		}
+
+		var response []userResponse
+		json.Unmarshal(rr.Body.Bytes(), &response)
+		if len(response) != 1 {
+			t.Errorf("handler returned unexpected number of users: got %v want %v", len(response), 1)
+		}
+	})
+}
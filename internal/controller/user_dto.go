@@ -0,0 +1,47 @@
+package controller
+
+import "microd-api/internal/models"
+
+// userRequest is the body of a request that updates a user's profile. The
+// email, role and team are changed through their own endpoints.
+type userRequest struct {
+	Name   string `json:"name"`
+	Avatar string `json:"avatar"`
+}
+
+// userResponse is a user as it is sent to clients, without the password hash
+// and refresh token.
+type userResponse struct {
+	ID        int64     `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Avatar    string    `json:"avatar"`
+	Role      int64     `json:"role"`
+	TeamID    *int64    `json:"team_id"`
+	CreatedAt timestamp `json:"created_at"`
+	UpdatedAt timestamp `json:"updated_at"`
+}
+
+func newUserResponse(user models.User) userResponse {
+	resp := userResponse{
+		ID:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Avatar:    user.Avatar,
+		Role:      user.Role,
+		CreatedAt: timestamp(user.CreatedAt),
+		UpdatedAt: timestamp(user.UpdatedAt),
+	}
+	if user.TeamID != 0 {
+		resp.TeamID = &user.TeamID
+	}
+	return resp
+}
+
+func newUserResponses(users []models.User) []userResponse {
+	resp := make([]userResponse, 0, len(users))
+	for _, user := range users {
+		resp = append(resp, newUserResponse(user))
+	}
+	return resp
+}
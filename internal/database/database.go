@@ -0,0 +1,39 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"microd-api/sql/schemas"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Open opens the SQLite database at path with foreign key enforcement enabled
+// and brings its schema up to date.
+func Open(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", dsn(path))
+	if err != nil {
+		return nil, fmt.Errorf("error opening database: %w", err)
+	}
+
+	// SQLite only supports a single writer, and every connection to
+	// ":memory:" gets its own private database.
+	db.SetMaxOpenConns(1)
+
+	if err := Migrate(ctx, db, schemas.FS); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error migrating database: %w", err)
+	}
+
+	return db, nil
+}
+
+func dsn(path string) string {
+	sep := "?"
+	if strings.Contains(path, "?") {
+		sep = "&"
+	}
+	return path + sep + "_foreign_keys=on"
+}
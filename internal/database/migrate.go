@@ -0,0 +1,261 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	upMarker   = "-- +goose Up"
+	downMarker = "-- +goose Down"
+)
+
+var (
+	ErrChecksumMismatch = errors.New("migration checksum mismatch")
+	ErrUnknownMigration = errors.New("applied migration not found in source")
+)
+
+type Migration struct {
+	Version  int64
+	Name     string
+	Up       string
+	Down     string
+	Checksum string
+}
+
+// LoadMigrations reads every NNN_name.sql file at the root of fsys and splits
+// it into its goose-style Up and Down sections, ordered by version.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(files))
+	seen := make(map[int64]string)
+	for _, file := range files {
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("reading migration %s: %w", file, err)
+		}
+
+		m, err := parseMigration(file, string(content))
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[m.Version]; ok {
+			return nil, fmt.Errorf("duplicate migration version %d in %s and %s", m.Version, other, file)
+		}
+		seen[m.Version] = file
+
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+func parseMigration(file, content string) (Migration, error) {
+	base := strings.TrimSuffix(path.Base(file), ".sql")
+	prefix, name, ok := strings.Cut(base, "_")
+	if !ok {
+		return Migration{}, fmt.Errorf("migration %s: file name must be <version>_<name>.sql", file)
+	}
+	version, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil || version <= 0 {
+		return Migration{}, fmt.Errorf("migration %s: invalid version %q", file, prefix)
+	}
+
+	var up, down strings.Builder
+	var section *strings.Builder
+	for _, line := range strings.SplitAfter(content, "\n") {
+		switch strings.TrimSpace(line) {
+		case upMarker:
+			section = &up
+			continue
+		case downMarker:
+			section = &down
+			continue
+		}
+		if section != nil {
+			section.WriteString(line)
+		}
+	}
+	if strings.TrimSpace(up.String()) == "" {
+		return Migration{}, fmt.Errorf("migration %s: missing %q section", file, upMarker)
+	}
+
+	sum := sha256.Sum256([]byte(content))
+	return Migration{
+		Version:  version,
+		Name:     name,
+		Up:       up.String(),
+		Down:     down.String(),
+		Checksum: hex.EncodeToString(sum[:]),
+	}, nil
+}
+
+// Migrate applies every pending migration in fsys, each inside its own
+// transaction. It refuses to run if a migration that was already applied has
+// since been edited or removed.
+func Migrate(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return err
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyApplied(migrations, applied); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback reverts the most recently applied migration using its Down section.
+func Rollback(ctx context.Context, db *sql.DB, fsys fs.FS) error {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureMigrationsTable(ctx, db); err != nil {
+		return err
+	}
+
+	applied, err := appliedMigrations(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyApplied(migrations, applied); err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		return revertMigration(ctx, db, m)
+	}
+	return nil
+}
+
+func ensureMigrationsTable(ctx context.Context, db *sql.DB) error {
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("creating schema_migrations table: %w", err)
+	}
+	return nil
+}
+
+func appliedMigrations(ctx context.Context, db *sql.DB) (map[int64]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT version, checksum FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("reading schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int64]string)
+	for rows.Next() {
+		var version int64
+		var checksum string
+		if err := rows.Scan(&version, &checksum); err != nil {
+			return nil, err
+		}
+		applied[version] = checksum
+	}
+	return applied, rows.Err()
+}
+
+func verifyApplied(migrations []Migration, applied map[int64]string) error {
+	known := make(map[int64]Migration, len(migrations))
+	for _, m := range migrations {
+		known[m.Version] = m
+	}
+
+	for version, checksum := range applied {
+		m, ok := known[version]
+		if !ok {
+			return fmt.Errorf("%w: version %d", ErrUnknownMigration, version)
+		}
+		if m.Checksum != checksum {
+			return fmt.Errorf("%w: version %d (%s)", ErrChecksumMismatch, version, m.Name)
+		}
+	}
+	return nil
+}
+
+func applyMigration(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.Up); err != nil {
+		return fmt.Errorf("applying migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	query := `INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, query, m.Version, m.Name, m.Checksum); err != nil {
+		return fmt.Errorf("recording migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	return tx.Commit()
+}
+
+func revertMigration(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if strings.TrimSpace(m.Down) != "" {
+		if _, err := tx.ExecContext(ctx, m.Down); err != nil {
+			return fmt.Errorf("reverting migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, m.Version); err != nil {
+		return fmt.Errorf("unrecording migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	return tx.Commit()
+}
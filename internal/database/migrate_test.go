@@ -0,0 +1,351 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"microd-api/sql/schemas"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("Error opening database: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func tableExists(t *testing.T, db *sql.DB, name string) bool {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&count)
+	if err != nil {
+		t.Fatalf("Error checking table %s: %v", name, err)
+	}
+	return count == 1
+}
+
+// schemasBefore returns the embedded migrations whose file names sort before
+// prefix, to set up a database as it was before a migration.
+func schemasBefore(prefix string) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	entries, _ := fs.ReadDir(schemas.FS, ".")
+	for _, entry := range entries {
+		if entry.Name() < prefix {
+			data, _ := fs.ReadFile(schemas.FS, entry.Name())
+			fsys[entry.Name()] = &fstest.MapFile{Data: data}
+		}
+	}
+	return fsys
+}
+
+func testMigrations() fstest.MapFS {
+	return fstest.MapFS{
+		"001_widgets.sql": {Data: []byte("-- +goose Up\nCREATE TABLE widgets (id INTEGER PRIMARY KEY);\n\n-- +goose Down\nDROP TABLE widgets;\n")},
+		"002_gadgets.sql": {Data: []byte("-- +goose Up\nCREATE TABLE gadgets (id INTEGER PRIMARY KEY);\n\n-- +goose Down\nDROP TABLE gadgets;\n")},
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	t.Run("EmbeddedSchemas", func(t *testing.T) {
+		migrations, err := LoadMigrations(schemas.FS)
+		if err != nil {
+			t.Fatalf("LoadMigrations() error = %v", err)
+		}
+		if len(migrations) == 0 {
+			t.Fatal("expected at least one embedded migration")
+		}
+		if migrations[0].Version != 1 || migrations[0].Name != "setup" {
+			t.Errorf("unexpected first migration: %d %s", migrations[0].Version, migrations[0].Name)
+		}
+	})
+
+	t.Run("SplitsSections", func(t *testing.T) {
+		migrations, err := LoadMigrations(testMigrations())
+		if err != nil {
+			t.Fatalf("LoadMigrations() error = %v", err)
+		}
+		if len(migrations) != 2 {
+			t.Fatalf("expected 2 migrations, got %d", len(migrations))
+		}
+		if migrations[0].Up != "CREATE TABLE widgets (id INTEGER PRIMARY KEY);\n\n" {
+			t.Errorf("unexpected Up section: %q", migrations[0].Up)
+		}
+		if migrations[0].Down != "DROP TABLE widgets;\n" {
+			t.Errorf("unexpected Down section: %q", migrations[0].Down)
+		}
+	})
+
+	t.Run("InvalidFileName", func(t *testing.T) {
+		fsys := fstest.MapFS{"setup.sql": {Data: []byte("-- +goose Up\nSELECT 1;")}}
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Error("expected error for file without version prefix")
+		}
+	})
+
+	t.Run("MissingUpSection", func(t *testing.T) {
+		fsys := fstest.MapFS{"001_empty.sql": {Data: []byte("-- +goose Down\nSELECT 1;")}}
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Error("expected error for migration without Up section")
+		}
+	})
+
+	t.Run("DuplicateVersion", func(t *testing.T) {
+		fsys := fstest.MapFS{
+			"001_a.sql":  {Data: []byte("-- +goose Up\nSELECT 1;")},
+			"0001_b.sql": {Data: []byte("-- +goose Up\nSELECT 1;")},
+		}
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Error("expected error for duplicate version")
+		}
+	})
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("EmbeddedSchemas", func(t *testing.T) {
+		db := openTestDB(t)
+		if err := Migrate(ctx, db, schemas.FS); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		for _, table := range []string{"users", "api_categories", "apis", "api_category_mappings", "apis_fts", "api_revisions", "api_versions", "tags", "api_tags", "teams", "api_dependencies"} {
+			if !tableExists(t, db, table) {
+				t.Errorf("expected table %s to exist", table)
+			}
+		}
+	})
+
+	t.Run("SplitsLegacyTags", func(t *testing.T) {
+		db := openTestDB(t)
+		if err := Migrate(ctx, db, schemasBefore("009")); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		_, err := db.Exec(`INSERT INTO apis (name, tags) VALUES ('Orders', ' orders , Public,,'), ('Billing', 'public'), ('Bare', NULL)`)
+		if err != nil {
+			t.Fatalf("Error inserting APIs: %v", err)
+		}
+
+		tagged := schemasBefore("010")
+		if err := Migrate(ctx, db, tagged); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		rows, err := db.Query(`SELECT a.name, t.name FROM api_tags m JOIN apis a ON a.id = m.api_id JOIN tags t ON t.id = m.tag_id ORDER BY a.id, t.name`)
+		if err != nil {
+			t.Fatalf("Error querying tags: %v", err)
+		}
+		defer rows.Close()
+		var got []string
+		for rows.Next() {
+			var api, tag string
+			rows.Scan(&api, &tag)
+			got = append(got, api+":"+tag)
+		}
+		want := []string{"Orders:orders", "Orders:Public", "Billing:Public"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected tags %v, got %v", want, got)
+		}
+
+		var matches int
+		db.QueryRow(`SELECT COUNT(*) FROM apis_fts WHERE apis_fts MATCH 'public'`).Scan(&matches)
+		if matches != 2 {
+			t.Errorf("expected migrated tags to be searchable, got %d matches", matches)
+		}
+
+		if err := Rollback(ctx, db, tagged); err != nil {
+			t.Fatalf("Rollback() error = %v", err)
+		}
+		var tags string
+		db.QueryRow(`SELECT tags FROM apis WHERE name = 'Orders'`).Scan(&tags)
+		if tags != "orders,Public" && tags != "Public,orders" {
+			t.Errorf("expected tags column to be restored, got %q", tags)
+		}
+	})
+
+	t.Run("MovesTeamsToTable", func(t *testing.T) {
+		db := openTestDB(t)
+		if err := Migrate(ctx, db, schemasBefore("010")); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		_, err := db.Exec(`
+			INSERT INTO apis (name, team) VALUES ('Orders', 'Commerce '), ('Billing', 'commerce'), ('Bare', NULL);
+			INSERT INTO users (name, email, team) VALUES ('Ada', 'ada@example.com', 'Identity'), ('Grace', 'grace@example.com', 'COMMERCE');
+			INSERT INTO api_revisions (api_id, revision, action, snapshot) VALUES (1, 1, 'create', '{"name":"Orders","team":"Commerce"}');
+		`)
+		if err != nil {
+			t.Fatalf("Error inserting fixtures: %v", err)
+		}
+
+		teamed := schemasBefore("011")
+		if err := Migrate(ctx, db, teamed); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		var teams []string
+		rows, err := db.Query(`SELECT id || ':' || name FROM teams ORDER BY id`)
+		if err != nil {
+			t.Fatalf("Error querying teams: %v", err)
+		}
+		for rows.Next() {
+			var team string
+			rows.Scan(&team)
+			teams = append(teams, team)
+		}
+		rows.Close()
+		if want := []string{"1:Commerce", "2:Identity"}; !reflect.DeepEqual(teams, want) {
+			t.Errorf("expected teams %v, got %v", want, teams)
+		}
+
+		var apiTeams, userTeams, snapshotTeam string
+		db.QueryRow(`SELECT group_concat(COALESCE(team_id, 0), ',') FROM (SELECT team_id FROM apis ORDER BY id)`).Scan(&apiTeams)
+		db.QueryRow(`SELECT group_concat(team_id, ',') FROM (SELECT team_id FROM users ORDER BY id)`).Scan(&userTeams)
+		db.QueryRow(`SELECT json_extract(snapshot, '$.team_id') FROM api_revisions`).Scan(&snapshotTeam)
+		if apiTeams != "1,1,0" || userTeams != "2,1" || snapshotTeam != "1" {
+			t.Errorf("unexpected team IDs: apis %s, users %s, snapshot %s", apiTeams, userTeams, snapshotTeam)
+		}
+
+		var matches int
+		db.QueryRow(`SELECT COUNT(*) FROM apis_fts WHERE apis_fts MATCH 'team:commerce'`).Scan(&matches)
+		if matches != 2 {
+			t.Errorf("expected team names to stay searchable, got %d matches", matches)
+		}
+
+		if err := Rollback(ctx, db, teamed); err != nil {
+			t.Fatalf("Rollback() error = %v", err)
+		}
+		var team string
+		db.QueryRow(`SELECT team FROM users WHERE name = 'Grace'`).Scan(&team)
+		if team != "Commerce" || tableExists(t, db, "teams") {
+			t.Errorf("expected team names to be restored, got %q", team)
+		}
+	})
+
+	t.Run("Idempotent", func(t *testing.T) {
+		db := openTestDB(t)
+		fsys := testMigrations()
+		if err := Migrate(ctx, db, fsys); err != nil {
+			t.Fatalf("first Migrate() error = %v", err)
+		}
+		if err := Migrate(ctx, db, fsys); err != nil {
+			t.Fatalf("second Migrate() error = %v", err)
+		}
+
+		var count int
+		db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count)
+		if count != 2 {
+			t.Errorf("expected 2 recorded migrations, got %d", count)
+		}
+	})
+
+	t.Run("AppliesOnlyPending", func(t *testing.T) {
+		db := openTestDB(t)
+		fsys := testMigrations()
+		first := fstest.MapFS{"001_widgets.sql": fsys["001_widgets.sql"]}
+		if err := Migrate(ctx, db, first); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		if tableExists(t, db, "gadgets") {
+			t.Fatal("gadgets should not exist yet")
+		}
+		if err := Migrate(ctx, db, fsys); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+		if !tableExists(t, db, "gadgets") {
+			t.Error("expected gadgets to be created")
+		}
+	})
+
+	t.Run("FailedMigrationRollsBack", func(t *testing.T) {
+		db := openTestDB(t)
+		fsys := fstest.MapFS{
+			"001_broken.sql": {Data: []byte("-- +goose Up\nCREATE TABLE widgets (id INTEGER PRIMARY KEY);\nNOT VALID SQL;\n")},
+		}
+		if err := Migrate(ctx, db, fsys); err == nil {
+			t.Fatal("expected error for invalid migration")
+		}
+		if tableExists(t, db, "widgets") {
+			t.Error("expected partial migration to be rolled back")
+		}
+	})
+
+	t.Run("ChecksumMismatch", func(t *testing.T) {
+		db := openTestDB(t)
+		fsys := testMigrations()
+		if err := Migrate(ctx, db, fsys); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+
+		fsys["001_widgets.sql"] = &fstest.MapFile{Data: []byte("-- +goose Up\nCREATE TABLE widgets (id TEXT);\n")}
+		err := Migrate(ctx, db, fsys)
+		if !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("expected ErrChecksumMismatch, got %v", err)
+		}
+	})
+
+	t.Run("UnknownAppliedMigration", func(t *testing.T) {
+		db := openTestDB(t)
+		fsys := testMigrations()
+		if err := Migrate(ctx, db, fsys); err != nil {
+			t.Fatalf("Migrate() error = %v", err)
+		}
+
+		delete(fsys, "002_gadgets.sql")
+		err := Migrate(ctx, db, fsys)
+		if !errors.Is(err, ErrUnknownMigration) {
+			t.Errorf("expected ErrUnknownMigration, got %v", err)
+		}
+	})
+}
+
+func TestRollback(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	fsys := testMigrations()
+
+	if err := Migrate(ctx, db, fsys); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+
+	if err := Rollback(ctx, db, fsys); err != nil {
+		t.Fatalf("Rollback() error = %v", err)
+	}
+	if tableExists(t, db, "gadgets") {
+		t.Error("expected gadgets to be dropped")
+	}
+	if !tableExists(t, db, "widgets") {
+		t.Error("expected widgets to remain")
+	}
+
+	if err := Migrate(ctx, db, fsys); err != nil {
+		t.Fatalf("Migrate() after Rollback() error = %v", err)
+	}
+	if !tableExists(t, db, "gadgets") {
+		t.Error("expected gadgets to be re-created")
+	}
+}
+
+func TestOpen(t *testing.T) {
+	db, err := Open(context.Background(), ":memory:")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	defer db.Close()
+
+	if !tableExists(t, db, "apis") {
+		t.Error("expected apis table to exist")
+	}
+
+	var enabled int
+	if err := db.QueryRow(`PRAGMA foreign_keys`).Scan(&enabled); err != nil {
+		t.Fatalf("Error reading foreign_keys pragma: %v", err)
+	}
+	if enabled != 1 {
+		t.Error("expected foreign keys to be enforced")
+	}
+}
@@ -0,0 +1,128 @@
+package graph
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Edge points from a node to a node it depends on.
+type Edge struct {
+	From int64
+	To   int64
+}
+
+type Node struct {
+	ID    int64
+	Label string
+}
+
+// Cycles returns the groups of nodes that lie on a cycle: the strongly
+// connected components with more than one node, and nodes with an edge to
+// themselves. Each group is sorted and the groups are ordered by their first
+// node. Every cycle of the graph runs through exactly one group.
+func Cycles(edges []Edge) [][]int64 {
+	adjacent := make(map[int64][]int64)
+	selfLoop := make(map[int64]bool)
+	var nodes []int64
+	seen := make(map[int64]bool)
+	for _, e := range edges {
+		adjacent[e.From] = append(adjacent[e.From], e.To)
+		if e.From == e.To {
+			selfLoop[e.From] = true
+		}
+		for _, n := range []int64{e.From, e.To} {
+			if !seen[n] {
+				seen[n] = true
+				nodes = append(nodes, n)
+			}
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i] < nodes[j] })
+
+	// Tarjan's algorithm.
+	index := make(map[int64]int)
+	lowlink := make(map[int64]int)
+	onStack := make(map[int64]bool)
+	var stack []int64
+	var cycles [][]int64
+
+	var visit func(n int64)
+	visit = func(n int64) {
+		index[n] = len(index)
+		lowlink[n] = index[n]
+		stack = append(stack, n)
+		onStack[n] = true
+
+		for _, m := range adjacent[n] {
+			if _, visited := index[m]; !visited {
+				visit(m)
+				lowlink[n] = min(lowlink[n], lowlink[m])
+			} else if onStack[m] {
+				lowlink[n] = min(lowlink[n], index[m])
+			}
+		}
+
+		if lowlink[n] != index[n] {
+			return
+		}
+		var component []int64
+		for {
+			m := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[m] = false
+			component = append(component, m)
+			if m == n {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop[n] {
+			sort.Slice(component, func(i, j int) bool { return component[i] < component[j] })
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, visited := index[n]; !visited {
+			visit(n)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language. Edges that lie on a
+// cycle are drawn in red.
+func WriteDOT(w io.Writer, name string, nodes []Node, edges []Edge) error {
+	group := make(map[int64]int)
+	for i, cycle := range Cycles(edges) {
+		for _, n := range cycle {
+			group[n] = i + 1
+		}
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, "digraph %s {\n", quote(name))
+	fmt.Fprintln(b, "  rankdir=LR;")
+	fmt.Fprintln(b, "  node [shape=box];")
+	for _, n := range nodes {
+		fmt.Fprintf(b, "  %d [label=%s];\n", n.ID, quote(n.Label))
+	}
+	for _, e := range edges {
+		if g := group[e.From]; g != 0 && g == group[e.To] {
+			fmt.Fprintf(b, "  %d -> %d [color=red];\n", e.From, e.To)
+		} else {
+			fmt.Fprintf(b, "  %d -> %d;\n", e.From, e.To)
+		}
+	}
+	fmt.Fprintln(b, "}")
+	return b.Flush()
+}
+
+// quote makes s a DOT double-quoted string.
+func quote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\r", "", "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
@@ -0,0 +1,56 @@
+package graph
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCycles(t *testing.T) {
+	tests := []struct {
+		name     string
+		edges    []Edge
+		expected [][]int64
+	}{
+		{"Empty", nil, nil},
+		{"Chain", []Edge{{1, 2}, {2, 3}}, nil},
+		{"Diamond", []Edge{{1, 2}, {1, 3}, {2, 4}, {3, 4}}, nil},
+		{"SelfLoop", []Edge{{1, 1}, {1, 2}}, [][]int64{{1}}},
+		{"Triangle", []Edge{{3, 1}, {1, 2}, {2, 3}, {3, 4}}, [][]int64{{1, 2, 3}}},
+		{"TwoCycles", []Edge{{5, 6}, {6, 5}, {1, 2}, {2, 1}, {2, 5}}, [][]int64{{1, 2}, {5, 6}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cycles := Cycles(tt.edges)
+			if !reflect.DeepEqual(cycles, tt.expected) {
+				t.Errorf("Cycles() = %v, want %v", cycles, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	nodes := []Node{{ID: 1, Label: "Ledger"}, {ID: 2, Label: `Say "hi"`}, {ID: 3, Label: "Login"}}
+	edges := []Edge{{1, 2}, {2, 1}, {1, 3}}
+
+	var b strings.Builder
+	if err := WriteDOT(&b, "apis", nodes, edges); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+
+	expected := `digraph "apis" {
+  rankdir=LR;
+  node [shape=box];
+  1 [label="Ledger"];
+  2 [label="Say \"hi\""];
+  3 [label="Login"];
+  1 -> 2 [color=red];
+  2 -> 1 [color=red];
+  1 -> 3;
+}
+`
+	if b.String() != expected {
+		t.Errorf("WriteDOT() =\n%s\nwant\n%s", b.String(), expected)
+	}
+}
@@ -2,31 +2,51 @@ package mocks
 
 import (
 	"context"
-	"errors"
 	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
 type MockAPIRepository struct {
-	apis   map[int64]models.API
-	nextID int64
-	mu     sync.Mutex
+	apis       map[int64]models.API
+	categories map[int64]map[int64]bool
+	revisions  map[int64][]models.APIRevision
+	deleted    map[int64]models.DeletedAPI
+	versions   map[int64][]models.APIVersion
+	depends    map[int64]map[int64]time.Time
+	nextID     int64
+	versionID  int64
+	mu         sync.Mutex
 }
 
 func NewMockAPIRepository() *MockAPIRepository {
 	return &MockAPIRepository{
-		apis:   make(map[int64]models.API),
-		nextID: 1,
+		apis:       make(map[int64]models.API),
+		categories: make(map[int64]map[int64]bool),
+		revisions:  make(map[int64][]models.APIRevision),
+		deleted:    make(map[int64]models.DeletedAPI),
+		versions:   make(map[int64][]models.APIVersion),
+		depends:    make(map[int64]map[int64]time.Time),
+		nextID:     1,
 	}
 }
 
-func (m *MockAPIRepository) CreateAPI(ctx context.Context, api models.API) (int64, error) {
+func (m *MockAPIRepository) CreateAPI(ctx context.Context, api models.API, actorID int64) (int64, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	api.ID = m.nextID
+	api.ID, api.RowVersion = m.nextID, 1
+	api.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	api.UpdatedAt = api.CreatedAt
 	m.apis[api.ID] = api
 	m.nextID++
+	m.record(api, models.RevisionCreate, actorID)
 	return api.ID, nil
 }
 
@@ -36,40 +56,673 @@ func (m *MockAPIRepository) GetAPIByID(ctx context.Context, id int64) (models.AP
 
 	api, ok := m.apis[id]
 	if !ok {
-		return models.API{}, errors.New("API not found")
+		return models.API{}, repository.ErrNotFound
 	}
 	return api, nil
 }
 
-func (m *MockAPIRepository) UpdateAPI(ctx context.Context, api models.API) error {
+func (m *MockAPIRepository) UpdateAPI(ctx context.Context, api models.API, actorID int64) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, ok := m.apis[api.ID]; !ok {
-		return errors.New("API not found")
+	current, ok := m.apis[api.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	if api.RowVersion != 0 && api.RowVersion != current.RowVersion {
+		return repository.ErrVersionMismatch
 	}
+	api.CreatedAt, api.UpdatedAt = current.CreatedAt, time.Now().UTC().Truncate(time.Second)
+	api.RowVersion = current.RowVersion + 1
 	m.apis[api.ID] = api
+	m.record(api, models.RevisionUpdate, actorID)
 	return nil
 }
 
-func (m *MockAPIRepository) DeleteAPI(ctx context.Context, id int64) error {
+func (m *MockAPIRepository) DeleteAPI(ctx context.Context, id, version, actorID int64) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	if _, ok := m.apis[id]; !ok {
-		return errors.New("API not found")
+	api, ok := m.apis[id]
+	if !ok {
+		return repository.ErrNotFound
 	}
+	if version != 0 && version != api.RowVersion {
+		return repository.ErrVersionMismatch
+	}
+	api.RowVersion++
+	m.record(api, models.RevisionDelete, actorID)
 	delete(m.apis, id)
+	m.deleted[id] = models.DeletedAPI{API: api, DeletedAt: time.Now().UTC()}
 	return nil
 }
 
-func (m *MockAPIRepository) ListAPIs(ctx context.Context) ([]models.API, error) {
+// ListAPIs ignores the requested sort and pages by ID, using the last ID of
+// the previous page as the cursor.
+func (m *MockAPIRepository) ListAPIs(ctx context.Context, filter models.APIFilter) (models.APIPage, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	var after int64
+	if filter.Cursor != "" {
+		var err error
+		after, err = strconv.ParseInt(filter.Cursor, 10, 64)
+		if err != nil {
+			return models.APIPage{}, repository.ErrInvalidCursor
+		}
+	}
+
 	apis := make([]models.API, 0, len(m.apis))
 	for _, api := range m.apis {
+		if !m.matches(api, filter) {
+			continue
+		}
 		apis = append(apis, api)
 	}
-	return apis, nil
+	sort.Slice(apis, func(i, j int) bool {
+		return apis[i].ID < apis[j].ID
+	})
+
+	page := models.APIPage{Items: []models.API{}, Total: int64(len(apis))}
+	for _, api := range apis {
+		if api.ID <= after {
+			continue
+		}
+		if filter.Limit > 0 && len(page.Items) == filter.Limit {
+			page.NextCursor = strconv.FormatInt(page.Items[len(page.Items)-1].ID, 10)
+			break
+		}
+		page.Items = append(page.Items, api)
+	}
+	return page, nil
+}
+
+func (m *MockAPIRepository) matches(api models.API, filter models.APIFilter) bool {
+	if filter.CategoryID != 0 && !m.categories[api.ID][filter.CategoryID] {
+		return false
+	}
+	if filter.TeamID != 0 && api.TeamID != filter.TeamID {
+		return false
+	}
+	if filter.Version != "" && api.Version != filter.Version {
+		return false
+	}
+	if filter.Lifecycle != "" && api.Lifecycle != filter.Lifecycle {
+		return false
+	}
+	for _, tag := range filter.Tags {
+		if !slices.ContainsFunc(api.Tags, func(t string) bool { return strings.EqualFold(t, tag) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// SearchAPIs matches every word of q case-insensitively against name,
+// description and tags, scoring one point per field that matches. Unlike the
+// SQLite index it cannot match team names, which the mock does not know.
+func (m *MockAPIRepository) SearchAPIs(ctx context.Context, q string, limit int) (models.APISearchPage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	words := strings.Fields(strings.ToLower(q))
+	if len(words) == 0 {
+		return models.APISearchPage{}, repository.ErrInvalidSearchQuery
+	}
+
+	results := []models.APISearchResult{}
+	for _, api := range m.apis {
+		fields := []string{api.Name, api.Description, strings.Join(api.Tags, " ")}
+		var score float64
+		matched := true
+		for _, word := range words {
+			hit := false
+			for _, field := range fields {
+				if strings.Contains(strings.ToLower(field), word) {
+					score++
+					hit = true
+				}
+			}
+			matched = matched && hit
+		}
+		if matched {
+			results = append(results, models.APISearchResult{API: api, Snippet: api.Name, Score: score})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].API.ID < results[j].API.ID
+	})
+
+	page := models.APISearchPage{Items: results, Total: int64(len(results))}
+	if limit > 0 && len(results) > limit {
+		page.Items = results[:limit]
+	}
+	return page, nil
+}
+
+func (m *MockAPIRepository) AttachCategory(ctx context.Context, apiID, categoryID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.apis[apiID]; !ok {
+		return repository.ErrNotFound
+	}
+	if m.categories[apiID] == nil {
+		m.categories[apiID] = make(map[int64]bool)
+	}
+	m.categories[apiID][categoryID] = true
+	return nil
+}
+
+func (m *MockAPIRepository) DetachCategory(ctx context.Context, apiID, categoryID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.categories[apiID], categoryID)
+	return nil
+}
+
+func (m *MockAPIRepository) ListAPICategories(ctx context.Context, apiID int64) ([]models.APICategory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	categories := make([]models.APICategory, 0, len(m.categories[apiID]))
+	for categoryID := range m.categories[apiID] {
+		categories = append(categories, models.APICategory{ID: categoryID})
+	}
+	return categories, nil
+}
+
+func (m *MockAPIRepository) record(api models.API, action string, actorID int64) {
+	rev := models.APIRevision{
+		ID:       int64(len(m.revisions[api.ID]) + 1),
+		APIID:    api.ID,
+		Revision: int64(len(m.revisions[api.ID]) + 1),
+		Action:   action,
+		ActorID:  actorID,
+		Snapshot: api,
+	}
+	m.revisions[api.ID] = append(m.revisions[api.ID], rev)
+}
+
+func (m *MockAPIRepository) ListAPIRevisions(ctx context.Context, apiID int64) ([]models.APIRevision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	revisions := m.revisions[apiID]
+	if len(revisions) == 0 {
+		return nil, repository.ErrNotFound
+	}
+	newestFirst := make([]models.APIRevision, 0, len(revisions))
+	for i := len(revisions) - 1; i >= 0; i-- {
+		newestFirst = append(newestFirst, revisions[i])
+	}
+	return newestFirst, nil
+}
+
+func (m *MockAPIRepository) GetAPIRevision(ctx context.Context, apiID, revision int64) (models.APIRevision, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	revisions := m.revisions[apiID]
+	if revision < 1 || revision > int64(len(revisions)) {
+		return models.APIRevision{}, repository.ErrNotFound
+	}
+	return revisions[revision-1], nil
+}
+
+func (m *MockAPIRepository) RestoreAPIRevision(ctx context.Context, apiID, revision, actorID int64) (models.API, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	revisions := m.revisions[apiID]
+	if revision < 1 || revision > int64(len(revisions)) {
+		return models.API{}, repository.ErrNotFound
+	}
+	api := revisions[revision-1].Snapshot
+	api.RowVersion = 1
+	if current, ok := m.apis[apiID]; ok {
+		api.RowVersion = current.RowVersion + 1
+	} else if d, ok := m.deleted[apiID]; ok {
+		api.RowVersion = d.API.RowVersion + 1
+	}
+	m.apis[apiID] = api
+	delete(m.deleted, apiID)
+	m.record(api, models.RevisionRestore, actorID)
+	return api, nil
+}
+
+func (m *MockAPIRepository) ListDeletedAPIs(ctx context.Context) ([]models.DeletedAPI, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	deleted := make([]models.DeletedAPI, 0, len(m.deleted))
+	for _, d := range m.deleted {
+		deleted = append(deleted, d)
+	}
+	sort.Slice(deleted, func(i, j int) bool {
+		if !deleted[i].DeletedAt.Equal(deleted[j].DeletedAt) {
+			return deleted[i].DeletedAt.After(deleted[j].DeletedAt)
+		}
+		return deleted[i].API.ID > deleted[j].API.ID
+	})
+	return deleted, nil
+}
+
+func (m *MockAPIRepository) GetDeletedAPI(ctx context.Context, id int64) (models.DeletedAPI, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, ok := m.deleted[id]
+	if !ok {
+		return models.DeletedAPI{}, repository.ErrNotFound
+	}
+	return d, nil
+}
+
+func (m *MockAPIRepository) UndeleteAPI(ctx context.Context, id, actorID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	d, ok := m.deleted[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.deleted, id)
+	d.API.RowVersion++
+	m.apis[id] = d.API
+	m.record(d.API, models.RevisionRestore, actorID)
+	return nil
+}
+
+func (m *MockAPIRepository) PurgeDeletedAPIs(ctx context.Context, cutoff time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var purged int64
+	for id, d := range m.deleted {
+		if d.DeletedAt.Before(cutoff) {
+			delete(m.deleted, id)
+			delete(m.categories, id)
+			delete(m.versions, id)
+			delete(m.depends, id)
+			for _, targets := range m.depends {
+				delete(targets, id)
+			}
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// SetDeletedAt backdates an API in the trash so that tests can purge it.
+func (m *MockAPIRepository) SetDeletedAt(id int64, deletedAt time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if d, ok := m.deleted[id]; ok {
+		d.DeletedAt = deletedAt
+		m.deleted[id] = d
+	}
+}
+
+func (m *MockAPIRepository) ListAPIVersions(ctx context.Context, apiID int64) ([]models.APIVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.apis[apiID]; !ok {
+		return []models.APIVersion{}, nil
+	}
+	return append([]models.APIVersion{}, m.versions[apiID]...), nil
+}
+
+func (m *MockAPIRepository) GetAPIVersion(ctx context.Context, apiID int64, version string) (models.APIVersion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.apis[apiID]; !ok {
+		return models.APIVersion{}, repository.ErrNotFound
+	}
+	for _, v := range m.versions[apiID] {
+		if v.Version == version {
+			return v, nil
+		}
+	}
+	return models.APIVersion{}, repository.ErrNotFound
+}
+
+func (m *MockAPIRepository) CreateAPIVersion(ctx context.Context, version models.APIVersion) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.apis[version.APIID]; !ok {
+		return 0, repository.ErrNotFound
+	}
+	for _, v := range m.versions[version.APIID] {
+		if v.Version == version.Version {
+			return 0, repository.ErrConflict
+		}
+	}
+	m.versionID++
+	version.ID = m.versionID
+	version.CreatedAt = time.Now().UTC()
+	version.UpdatedAt = version.CreatedAt
+	m.versions[version.APIID] = append(m.versions[version.APIID], version)
+	return version.ID, nil
+}
+
+func (m *MockAPIRepository) UpdateAPIVersion(ctx context.Context, version models.APIVersion) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.apis[version.APIID]; !ok {
+		return repository.ErrNotFound
+	}
+	for i, v := range m.versions[version.APIID] {
+		if v.Version == version.Version {
+			version.ID, version.CreatedAt = v.ID, v.CreatedAt
+			version.UpdatedAt = time.Now().UTC()
+			m.versions[version.APIID][i] = version
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (m *MockAPIRepository) DeleteAPIVersion(ctx context.Context, apiID int64, version string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.apis[apiID]; !ok {
+		return repository.ErrNotFound
+	}
+	versions := m.versions[apiID]
+	for i, v := range versions {
+		if v.Version == version {
+			m.versions[apiID] = append(versions[:i:i], versions[i+1:]...)
+			return nil
+		}
+	}
+	return repository.ErrNotFound
+}
+
+func (m *MockAPIRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// Tags match case-insensitively and keep the spelling of the oldest API
+	// using them, as the tags table does.
+	ids := make([]int64, 0, len(m.apis))
+	for id := range m.apis {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	counts := map[string]int64{}
+	names := map[string]string{}
+	for _, id := range ids {
+		for _, tag := range m.apis[id].Tags {
+			key := strings.ToLower(tag)
+			if _, ok := names[key]; !ok {
+				names[key] = tag
+			}
+			counts[key]++
+		}
+	}
+	tags := make([]models.Tag, 0, len(counts))
+	for key, count := range counts {
+		tags = append(tags, models.Tag{Name: names[key], APICount: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].APICount != tags[j].APICount {
+			return tags[i].APICount > tags[j].APICount
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (m *MockAPIRepository) RenameTag(ctx context.Context, name, newName string, actorID int64) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.tagExists(name) {
+		return nil, repository.ErrNotFound
+	}
+	if !strings.EqualFold(name, newName) && m.tagExists(newName) {
+		return nil, repository.ErrConflict
+	}
+	return m.retag(name, newName, actorID), nil
+}
+
+func (m *MockAPIRepository) MergeTags(ctx context.Context, name, into string, actorID int64) ([]int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.tagExists(name) || !m.tagExists(into) {
+		return nil, repository.ErrNotFound
+	}
+	if strings.EqualFold(name, into) {
+		return nil, nil
+	}
+	return m.retag(name, into, actorID), nil
+}
+
+func (m *MockAPIRepository) tagExists(name string) bool {
+	for _, api := range m.apis {
+		for _, tag := range api.Tags {
+			if strings.EqualFold(tag, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// retag replaces name with newName on every API, dropping duplicates, and
+// returns the IDs of the APIs it changed.
+func (m *MockAPIRepository) retag(name, newName string, actorID int64) []int64 {
+	var ids []int64
+	for id, api := range m.apis {
+		if !slices.ContainsFunc(api.Tags, func(t string) bool { return strings.EqualFold(t, name) }) {
+			continue
+		}
+		tags := []string{newName}
+		for _, tag := range api.Tags {
+			if !strings.EqualFold(tag, name) && !strings.EqualFold(tag, newName) {
+				tags = append(tags, tag)
+			}
+		}
+		sort.Strings(tags)
+		api.Tags = tags
+		api.RowVersion++
+		m.apis[id] = api
+		m.record(api, models.RevisionUpdate, actorID)
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+func (m *MockAPIRepository) AddAPIDependency(ctx context.Context, apiID, dependsOnID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.exists(apiID) || !m.exists(dependsOnID) {
+		return repository.ErrNotFound
+	}
+	if apiID == dependsOnID {
+		return repository.ErrConflict
+	}
+	if m.depends[apiID] == nil {
+		m.depends[apiID] = make(map[int64]time.Time)
+	}
+	if _, ok := m.depends[apiID][dependsOnID]; !ok {
+		m.depends[apiID][dependsOnID] = time.Now()
+	}
+	return nil
+}
+
+func (m *MockAPIRepository) RemoveAPIDependency(ctx context.Context, apiID, dependsOnID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.depends[apiID][dependsOnID]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.depends[apiID], dependsOnID)
+	return nil
+}
+
+func (m *MockAPIRepository) ListAPIDependencies(ctx context.Context, apiID int64, maxDepth int) ([]models.DependencyNode, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.walk(apiID, maxDepth, m.liveEdges()), nil
+}
+
+func (m *MockAPIRepository) ListAPIDependents(ctx context.Context, apiID int64, maxDepth int) ([]models.DependencyNode, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	edges := m.liveEdges()
+	for i, e := range edges {
+		edges[i] = models.APIDependency{APIID: e.DependsOnID, DependsOnID: e.APIID, CreatedAt: e.CreatedAt}
+	}
+	return m.walk(apiID, maxDepth, edges), nil
+}
+
+func (m *MockAPIRepository) GetDependencyGraph(ctx context.Context) (models.DependencyGraph, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	graph := models.DependencyGraph{Nodes: []models.DependencyNode{}, Edges: m.liveEdges()}
+	seen := make(map[int64]bool)
+	for _, e := range graph.Edges {
+		for _, id := range []int64{e.APIID, e.DependsOnID} {
+			if !seen[id] {
+				seen[id] = true
+				graph.Nodes = append(graph.Nodes, m.dependencyNode(id, 0))
+			}
+		}
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		if graph.Nodes[i].Name != graph.Nodes[j].Name {
+			return graph.Nodes[i].Name < graph.Nodes[j].Name
+		}
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+	return graph, nil
+}
+
+// liveEdges returns the dependencies between APIs outside the trash, sorted.
+func (m *MockAPIRepository) liveEdges() []models.APIDependency {
+	edges := []models.APIDependency{}
+	for apiID, targets := range m.depends {
+		for dependsOnID, createdAt := range targets {
+			if m.exists(apiID) && m.exists(dependsOnID) {
+				edges = append(edges, models.APIDependency{APIID: apiID, DependsOnID: dependsOnID, CreatedAt: createdAt})
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].APIID != edges[j].APIID {
+			return edges[i].APIID < edges[j].APIID
+		}
+		return edges[i].DependsOnID < edges[j].DependsOnID
+	})
+	return edges
+}
+
+// walk visits the graph breadth first from apiID, so each API is found at
+// its smallest depth.
+func (m *MockAPIRepository) walk(apiID int64, maxDepth int, edges []models.APIDependency) []models.DependencyNode {
+	depth := map[int64]int{apiID: 0}
+	nodes := []models.DependencyNode{}
+	queue := []int64{apiID}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if maxDepth > 0 && depth[current] >= maxDepth {
+			continue
+		}
+		for _, e := range edges {
+			if e.APIID != current {
+				continue
+			}
+			if _, seen := depth[e.DependsOnID]; seen {
+				continue
+			}
+			depth[e.DependsOnID] = depth[current] + 1
+			nodes = append(nodes, m.dependencyNode(e.DependsOnID, depth[e.DependsOnID]))
+			queue = append(queue, e.DependsOnID)
+		}
+	}
+	sort.SliceStable(nodes, func(i, j int) bool {
+		if nodes[i].Depth != nodes[j].Depth {
+			return nodes[i].Depth < nodes[j].Depth
+		}
+		return nodes[i].Name < nodes[j].Name
+	})
+	return nodes
+}
+
+func (m *MockAPIRepository) dependencyNode(id int64, depth int) models.DependencyNode {
+	api := m.apis[id]
+	return models.DependencyNode{ID: api.ID, Name: api.Name, TeamID: api.TeamID, Lifecycle: api.Lifecycle, Depth: depth}
+}
+
+func (m *MockAPIRepository) exists(id int64) bool {
+	_, ok := m.apis[id]
+	return ok
+}
+
+// ImportAPIs checks every update before writing anything, so that a failed
+// batch leaves the mock untouched as the transaction would.
+func (m *MockAPIRepository) ImportAPIs(ctx context.Context, apis []models.API, actorID int64) ([]int64, error) {
+	m.mu.Lock()
+	for i, api := range apis {
+		if api.ID != 0 && !m.exists(api.ID) {
+			m.mu.Unlock()
+			return nil, &repository.BatchError{Index: i, Err: repository.ErrNotFound}
+		}
+	}
+	m.mu.Unlock()
+
+	ids := make([]int64, len(apis))
+	for i, api := range apis {
+		if api.ID == 0 {
+			ids[i], _ = m.CreateAPI(ctx, api, actorID)
+		} else {
+			ids[i] = api.ID
+			m.UpdateAPI(ctx, api, actorID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *MockAPIRepository) PatchAPI(ctx context.Context, id int64, patch func(current models.API) (models.API, error), actorID int64) (models.API, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.apis[id]
+	if !ok {
+		return models.API{}, repository.ErrNotFound
+	}
+	api, err := patch(current)
+	if err != nil {
+		return models.API{}, err
+	}
+	api.ID, api.CreatedAt, api.UpdatedAt, api.RowVersion = id, current.CreatedAt, current.UpdatedAt, current.RowVersion
+	if reflect.DeepEqual(api, current) {
+		return current, nil
+	}
+	api.UpdatedAt = time.Now().UTC()
+	api.RowVersion++
+	m.apis[id] = api
+	m.record(api, models.RevisionUpdate, actorID)
+	return api, nil
 }
@@ -0,0 +1,84 @@
+package mocks
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"sort"
+	"sync"
+)
+
+type MockCategoryRepository struct {
+	categories map[int64]models.APICategory
+	nextID     int64
+	mu         sync.Mutex
+}
+
+func NewMockCategoryRepository() *MockCategoryRepository {
+	return &MockCategoryRepository{
+		categories: make(map[int64]models.APICategory),
+		nextID:     1,
+	}
+}
+
+func (m *MockCategoryRepository) CreateCategory(ctx context.Context, category models.APICategory) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.categories {
+		if existing.Name == category.Name {
+			return 0, repository.ErrConflict
+		}
+	}
+	category.ID = m.nextID
+	m.categories[category.ID] = category
+	m.nextID++
+	return category.ID, nil
+}
+
+func (m *MockCategoryRepository) GetCategoryByID(ctx context.Context, id int64) (models.APICategory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	category, ok := m.categories[id]
+	if !ok {
+		return models.APICategory{}, repository.ErrNotFound
+	}
+	return category, nil
+}
+
+func (m *MockCategoryRepository) UpdateCategory(ctx context.Context, category models.APICategory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.categories[category.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	m.categories[category.ID] = category
+	return nil
+}
+
+func (m *MockCategoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.categories[id]; !ok {
+		return repository.ErrNotFound
+	}
+	delete(m.categories, id)
+	return nil
+}
+
+func (m *MockCategoryRepository) ListCategories(ctx context.Context) ([]models.APICategory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	categories := make([]models.APICategory, 0, len(m.categories))
+	for _, category := range m.categories {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		return categories[i].Name < categories[j].Name
+	})
+	return categories, nil
+}
@@ -0,0 +1,141 @@
+package mocks
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MockTeamRepository keeps teams in memory. Memberships are stored on the
+// users of the MockUserRepository and ownership is read from the APIs of the
+// MockAPIRepository, as they are in the database.
+type MockTeamRepository struct {
+	teams  map[int64]models.Team
+	apis   *MockAPIRepository
+	users  *MockUserRepository
+	nextID int64
+	mu     sync.Mutex
+}
+
+func NewMockTeamRepository(apis *MockAPIRepository, users *MockUserRepository) *MockTeamRepository {
+	return &MockTeamRepository{
+		teams:  make(map[int64]models.Team),
+		apis:   apis,
+		users:  users,
+		nextID: 1,
+	}
+}
+
+func (m *MockTeamRepository) CreateTeam(ctx context.Context, team models.Team) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.nameTaken(team) {
+		return 0, repository.ErrConflict
+	}
+	team.ID = m.nextID
+	m.teams[team.ID] = team
+	m.nextID++
+	return team.ID, nil
+}
+
+func (m *MockTeamRepository) GetTeamByID(ctx context.Context, id int64) (models.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	team, ok := m.teams[id]
+	if !ok {
+		return models.Team{}, repository.ErrNotFound
+	}
+	return team, nil
+}
+
+func (m *MockTeamRepository) UpdateTeam(ctx context.Context, team models.Team) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.teams[team.ID]; !ok {
+		return repository.ErrNotFound
+	}
+	if m.nameTaken(team) {
+		return repository.ErrConflict
+	}
+	m.teams[team.ID] = team
+	return nil
+}
+
+func (m *MockTeamRepository) DeleteTeam(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.teams[id]; !ok {
+		return repository.ErrNotFound
+	}
+	page, _ := m.apis.ListAPIs(ctx, models.APIFilter{TeamID: id})
+	if page.Total > 0 {
+		return repository.ErrTeamHasAPIs
+	}
+	delete(m.teams, id)
+
+	members, _ := m.ListTeamMembers(ctx, id)
+	for _, member := range members {
+		m.users.UpdateUserTeam(ctx, member.ID, 0)
+	}
+	return nil
+}
+
+func (m *MockTeamRepository) ListTeams(ctx context.Context) ([]models.Team, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	teams := make([]models.Team, 0, len(m.teams))
+	for _, team := range m.teams {
+		teams = append(teams, team)
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return teams[i].Name < teams[j].Name
+	})
+	return teams, nil
+}
+
+func (m *MockTeamRepository) ListTeamMembers(ctx context.Context, id int64) ([]models.User, error) {
+	users, _ := m.users.ListUsers(ctx)
+	members := []models.User{}
+	for _, user := range users {
+		if user.TeamID == id {
+			members = append(members, user)
+		}
+	}
+	return members, nil
+}
+
+func (m *MockTeamRepository) AddTeamMember(ctx context.Context, id, userID int64) error {
+	m.mu.Lock()
+	_, ok := m.teams[id]
+	m.mu.Unlock()
+
+	if !ok {
+		return repository.ErrUnknownTeam
+	}
+	return m.users.UpdateUserTeam(ctx, userID, id)
+}
+
+func (m *MockTeamRepository) RemoveTeamMember(ctx context.Context, id, userID int64) error {
+	user, err := m.users.GetUserByID(ctx, userID)
+	if err != nil || user.TeamID != id {
+		return repository.ErrNotFound
+	}
+	return m.users.UpdateUserTeam(ctx, userID, 0)
+}
+
+func (m *MockTeamRepository) nameTaken(team models.Team) bool {
+	for _, existing := range m.teams {
+		if existing.ID != team.ID && strings.EqualFold(existing.Name, team.Name) {
+			return true
+		}
+	}
+	return false
+}
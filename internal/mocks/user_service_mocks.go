@@ -0,0 +1,140 @@
+package mocks
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"sort"
+	"sync"
+)
+
+type MockUserRepository struct {
+	users  map[int64]models.User
+	nextID int64
+	mu     sync.Mutex
+}
+
+func NewMockUserRepository() *MockUserRepository {
+	return &MockUserRepository{
+		users:  make(map[int64]models.User),
+		nextID: 1,
+	}
+}
+
+func (m *MockUserRepository) CreateUser(ctx context.Context, user models.User) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, existing := range m.users {
+		if existing.Email == user.Email {
+			return 0, repository.ErrDuplicateEmail
+		}
+	}
+	user.ID = m.nextID
+	m.users[user.ID] = user
+	m.nextID++
+	return user.ID, nil
+}
+
+func (m *MockUserRepository) GetUserByID(ctx context.Context, id int64) (models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return models.User{}, repository.ErrNotFound
+	}
+	return user, nil
+}
+
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return models.User{}, repository.ErrNotFound
+}
+
+func (m *MockUserRepository) UpdateUser(ctx context.Context, user models.User) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.users[user.ID]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	existing.Name = user.Name
+	existing.Avatar = user.Avatar
+	m.users[user.ID] = existing
+	return nil
+}
+
+func (m *MockUserRepository) UpdateUserRole(ctx context.Context, id int64, role int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	existing.Role = role
+	m.users[id] = existing
+	return nil
+}
+
+func (m *MockUserRepository) UpdateUserTeam(ctx context.Context, id int64, teamID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	existing.TeamID = teamID
+	m.users[id] = existing
+	return nil
+}
+
+func (m *MockUserRepository) ListUsers(ctx context.Context) ([]models.User, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	users := make([]models.User, 0, len(m.users))
+	for _, user := range m.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+func (m *MockUserRepository) SetRefreshToken(ctx context.Context, id int64, tokenHash string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.users[id]
+	if !ok {
+		return repository.ErrNotFound
+	}
+	existing.RefreshToken = tokenHash
+	m.users[id] = existing
+	return nil
+}
+
+func (m *MockUserRepository) RotateRefreshToken(ctx context.Context, id int64, oldHash, newHash string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.users[id]
+	if !ok || existing.RefreshToken != oldHash {
+		return false, nil
+	}
+	existing.RefreshToken = newHash
+	m.users[id] = existing
+	return true, nil
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// APIDependency is an edge of the dependency graph: the API APIID consumes
+// the API DependsOnID.
+type APIDependency struct {
+	APIID       int64     `json:"api_id"`
+	DependsOnID int64     `json:"depends_on_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DependencyNode is an API in the dependency graph. When it was reached by
+// walking the graph from another API, Depth is the number of edges on the
+// shortest path between the two.
+type DependencyNode struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	TeamID    int64  `json:"team_id,omitempty"`
+	Lifecycle string `json:"lifecycle"`
+	Depth     int    `json:"depth,omitempty"`
+}
+
+// DependencyGraph holds the APIs that take part in a dependency and the
+// dependencies between them. Each entry of Cycles is a group of APIs that
+// depend on each other, directly or through the rest of the group.
+type DependencyGraph struct {
+	Nodes  []DependencyNode `json:"nodes"`
+	Edges  []APIDependency  `json:"edges"`
+	Cycles [][]int64        `json:"cycles"`
+}
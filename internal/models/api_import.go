@@ -0,0 +1,34 @@
+package models
+
+// What an import does with each record.
+const (
+	ImportCreate    = "create"
+	ImportUpdate    = "update"
+	ImportUnchanged = "unchanged"
+)
+
+// APIImportOptions controls an import. Without Upsert every record has to be
+// a new API; with it, a record replaces the API of the same name. A DryRun
+// reports what the import would do without writing anything.
+type APIImportOptions struct {
+	Upsert bool
+	DryRun bool
+}
+
+// APIImportItem reports what happened to the record at Index of the
+// imported document. Changes names the fields an update replaces.
+type APIImportItem struct {
+	Index   int      `json:"index"`
+	Name    string   `json:"name"`
+	Action  string   `json:"action"`
+	ID      int64    `json:"id,omitempty"`
+	Changes []string `json:"changes,omitempty"`
+}
+
+type APIImportReport struct {
+	DryRun    bool            `json:"dry_run"`
+	Created   int             `json:"created"`
+	Updated   int             `json:"updated"`
+	Unchanged int             `json:"unchanged"`
+	Items     []APIImportItem `json:"items"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+const (
+	RevisionCreate  = "create"
+	RevisionUpdate  = "update"
+	RevisionDelete  = "delete"
+	RevisionRestore = "restore"
+)
+
+// APIRevision is a snapshot of an API taken when it was written. Revision
+// numbers count from 1 for each API; ActorID is 0 when the change was not made
+// by a signed-in user. The snapshot of a delete is the API as it was just
+// before it was removed.
+type APIRevision struct {
+	ID        int64
+	APIID     int64
+	Revision  int64
+	Action    string
+	ActorID   int64
+	Snapshot  API
+	CreatedAt time.Time
+}
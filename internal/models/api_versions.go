@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+)
+
+// APIVersion is one released or planned version of an API. Version is a
+// semantic version and identifies it within the API; Status is one of the
+// lifecycle states. ReleasedAt is zero until the version is released.
+type APIVersion struct {
+	ID                int64
+	APIID             int64
+	Version           string
+	Swagger           string
+	DocumentationLink string
+	Status            string
+	ReleasedAt        time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// APIVersionList holds the versions of an API, highest first. Latest is the
+// highest version that is neither a pre-release nor retired.
+type APIVersionList struct {
+	Latest string       `json:"latest,omitempty"`
+	Items  []APIVersion `json:"items"`
+}
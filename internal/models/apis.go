@@ -4,6 +4,17 @@ import (
 	"time"
 )
 
+// The lifecycle states of an API or one of its versions.
+const (
+	LifecycleExperimental = "experimental"
+	LifecycleStable       = "stable"
+	LifecycleDeprecated   = "deprecated"
+	LifecycleRetired      = "retired"
+)
+
+// API is a catalog entry. DeprecatedAt and SunsetAt are zero unless the API
+// is deprecated or retired; SunsetAt is when it stops, or stopped, serving.
+// RowVersion is assigned by the repository and changes with every write.
 type API struct {
 	ID                int64
 	Name              string
@@ -12,9 +23,75 @@ type API struct {
 	DocumentationLink string
 	ForumReference    string
 	ApmLink           string
-	Team              string
-	Tags              string
+	TeamID            int64
+	Tags              []string
 	Swagger           string
+	Lifecycle         string
+	DeprecatedAt      time.Time
+	SunsetAt          time.Time
 	CreatedAt         time.Time
 	UpdatedAt         time.Time
+	RowVersion        int64
+}
+
+// IfMatch is an If-Match precondition on the RowVersion of an API: it holds
+// when Any is set or the API has one of Versions. A nil *IfMatch always
+// holds.
+type IfMatch struct {
+	Any      bool
+	Versions []int64
+}
+
+func (m *IfMatch) Matches(version int64) bool {
+	if m == nil || m.Any {
+		return true
+	}
+	for _, v := range m.Versions {
+		if v == version {
+			return true
+		}
+	}
+	return false
+}
+
+// APIFilter describes a page of the catalog. Sort is one of "name",
+// "created_at" or "updated_at", optionally prefixed with "-" for descending
+// order; Cursor is the NextCursor of the previous page.
+type APIFilter struct {
+	CategoryID int64
+	TeamID     int64
+	Version    string
+	Lifecycle  string
+	Tags       []string
+	Sort       string
+	Limit      int
+	Cursor     string
+}
+
+type APIPage struct {
+	Items      []API  `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int64  `json:"total"`
+}
+
+// APISearchResult is a catalog entry matched by a full-text search. Snippet is
+// an excerpt of the best matching field with the matched terms wrapped in
+// <mark> tags; a higher Score means a better match.
+type APISearchResult struct {
+	API     API     `json:"api"`
+	Snippet string  `json:"snippet"`
+	Score   float64 `json:"score"`
+}
+
+type APISearchPage struct {
+	Items []APISearchResult `json:"items"`
+	Total int64             `json:"total"`
+}
+
+// DeletedAPI is an API in the trash. It can be restored until PurgeAt, when
+// it is removed for good.
+type DeletedAPI struct {
+	API       API       `json:"api"`
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
 }
@@ -0,0 +1,7 @@
+package models
+
+// Tag is a tag in use together with the number of APIs that carry it.
+type Tag struct {
+	Name     string `json:"name"`
+	APICount int64  `json:"api_count"`
+}
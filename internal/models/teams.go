@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// Team owns APIs. Its members are the users whose TeamID is the team's ID.
+type Team struct {
+	ID          int64
+	Name        string
+	Description string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
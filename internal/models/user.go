@@ -4,13 +4,24 @@ import (
 	"time"
 )
 
+const (
+	RoleUser  int64 = 0
+	RoleAdmin int64 = 1
+)
+
 type User struct {
 	ID           int64
 	Name         string
 	Email        string
 	Avatar       string
-	RefreshToken string
+	RefreshToken string `json:"-"`
+	PasswordHash string `json:"-"`
 	Role         int64
+	TeamID       int64
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
+
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
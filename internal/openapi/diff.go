@@ -0,0 +1,274 @@
+package openapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	ChangePathAdded               = "path-added"
+	ChangePathRemoved             = "path-removed"
+	ChangeOperationAdded          = "operation-added"
+	ChangeOperationRemoved        = "operation-removed"
+	ChangeParameterAdded          = "parameter-added"
+	ChangeParameterRemoved        = "parameter-removed"
+	ChangeParameterBecameRequired = "parameter-became-required"
+	ChangeParameterBecameOptional = "parameter-became-optional"
+	ChangeRequestBodyRequired     = "request-body-became-required"
+	ChangeTypeChanged             = "type-changed"
+	ChangeFieldAdded              = "field-added"
+	ChangeFieldRemoved            = "field-removed"
+	ChangeFieldBecameRequired     = "field-became-required"
+	ChangeFieldBecameOptional     = "field-became-optional"
+	ChangeRequiredFieldAdded      = "required-field-added"
+	ChangeRequiredFieldRemoved    = "required-field-removed"
+	ChangeResponseAdded           = "response-added"
+	ChangeResponseRemoved         = "response-removed"
+)
+
+// Change is one difference between two documents. Location names the
+// parameter, request field or response field involved, such as "query.limit",
+// "request.customer.name" or "response.200.items[].id".
+type Change struct {
+	Type     string `json:"type"`
+	Breaking bool   `json:"breaking"`
+	Method   string `json:"method,omitempty"`
+	Path     string `json:"path"`
+	Location string `json:"location,omitempty"`
+	Message  string `json:"message"`
+}
+
+type Report struct {
+	Breaking bool     `json:"breaking"`
+	Changes  []Change `json:"changes"`
+}
+
+// Diff compares two documents from the point of view of an existing client of
+// old. A change is breaking when such a client may stop working: something it
+// calls or reads disappears or changes type, or the server starts requiring
+// input the client does not send.
+func Diff(old, new *Document) Report {
+	d := &differ{}
+
+	newOps := operationsByPath(new)
+	oldOps := operationsByPath(old)
+
+	for _, path := range old.Paths {
+		ops, ok := newOps[path]
+		if !ok {
+			d.add(Change{Type: ChangePathRemoved, Breaking: true, Path: path, Message: "path was removed"})
+			continue
+		}
+		for _, op := range sortedOperations(oldOps[path]) {
+			next, ok := ops[op.Method]
+			if !ok {
+				d.add(Change{Type: ChangeOperationRemoved, Breaking: true, Method: op.Method, Path: path, Message: "operation was removed"})
+				continue
+			}
+			d.operation(op, next)
+		}
+		for _, op := range sortedOperations(ops) {
+			if _, ok := oldOps[path][op.Method]; !ok {
+				d.add(Change{Type: ChangeOperationAdded, Method: op.Method, Path: path, Message: "operation was added"})
+			}
+		}
+	}
+	for _, path := range new.Paths {
+		if _, ok := oldOps[path]; !ok {
+			d.add(Change{Type: ChangePathAdded, Path: path, Message: "path was added"})
+		}
+	}
+
+	report := Report{Changes: d.changes}
+	if report.Changes == nil {
+		report.Changes = []Change{}
+	}
+	for _, c := range report.Changes {
+		report.Breaking = report.Breaking || c.Breaking
+	}
+	return report
+}
+
+type differ struct {
+	changes []Change
+	method  string
+	path    string
+}
+
+func (d *differ) add(c Change) {
+	d.changes = append(d.changes, c)
+}
+
+func (d *differ) change(kind string, breaking bool, location, format string, args ...interface{}) {
+	d.add(Change{
+		Type:     kind,
+		Breaking: breaking,
+		Method:   d.method,
+		Path:     d.path,
+		Location: location,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (d *differ) operation(old, new Operation) {
+	d.method, d.path = old.Method, old.Path
+	defer func() { d.method, d.path = "", "" }()
+
+	d.parameters(old.Parameters, new.Parameters)
+
+	switch {
+	case old.RequestBody == nil && new.RequestBody != nil && new.RequestBodyRequired:
+		d.change(ChangeRequestBodyRequired, true, "request", "a request body is now required")
+	case old.RequestBody != nil && !old.RequestBodyRequired && new.RequestBodyRequired:
+		d.change(ChangeRequestBodyRequired, true, "request", "the request body is now required")
+	}
+	d.schema(old.RequestBody, new.RequestBody, "request", true)
+
+	for _, code := range sortedResponseCodes(old.Responses) {
+		next, ok := new.Responses[code]
+		location := "response." + code
+		if !ok {
+			d.change(ChangeResponseRemoved, true, location, "response %s was removed", code)
+			continue
+		}
+		d.schema(old.Responses[code], next, location, false)
+	}
+	for _, code := range sortedResponseCodes(new.Responses) {
+		if _, ok := old.Responses[code]; !ok {
+			d.change(ChangeResponseAdded, false, "response."+code, "response %s was added", code)
+		}
+	}
+}
+
+func (d *differ) parameters(old, new []Parameter) {
+	find := func(params []Parameter, p Parameter) (Parameter, bool) {
+		for _, candidate := range params {
+			if candidate.Name == p.Name && candidate.In == p.In {
+				return candidate, true
+			}
+		}
+		return Parameter{}, false
+	}
+
+	for _, p := range old {
+		location := p.In + "." + p.Name
+		next, ok := find(new, p)
+		if !ok {
+			d.change(ChangeParameterRemoved, false, location, "%s parameter %q was removed", p.In, p.Name)
+			continue
+		}
+		if !p.Required && next.Required {
+			d.change(ChangeParameterBecameRequired, true, location, "%s parameter %q is now required", p.In, p.Name)
+		}
+		if p.Required && !next.Required {
+			d.change(ChangeParameterBecameOptional, false, location, "%s parameter %q is now optional", p.In, p.Name)
+		}
+		d.schema(p.Schema, next.Schema, location, true)
+	}
+	for _, p := range new {
+		if _, ok := find(old, p); ok {
+			continue
+		}
+		location := p.In + "." + p.Name
+		if p.Required {
+			d.change(ChangeParameterAdded, true, location, "required %s parameter %q was added", p.In, p.Name)
+		} else {
+			d.change(ChangeParameterAdded, false, location, "optional %s parameter %q was added", p.In, p.Name)
+		}
+	}
+}
+
+// schema compares two schemas. Clients write request schemas and read
+// response schemas, so the same change can be breaking in one direction only.
+func (d *differ) schema(old, new *Schema, location string, request bool) {
+	if old == nil || new == nil || old.Ref != "" || new.Ref != "" {
+		return
+	}
+
+	if old.Type != "" && new.Type != "" && old.Type != new.Type {
+		d.change(ChangeTypeChanged, true, location, "type changed from %s to %s", old.Type, new.Type)
+		return
+	}
+
+	for _, name := range sortedProperties(old.Properties) {
+		field := location + "." + name
+		next, ok := new.Properties[name]
+		wasRequired := contains(old.Required, name)
+		switch {
+		case !ok && !request && wasRequired:
+			d.change(ChangeRequiredFieldRemoved, true, field, "required field %q was removed", name)
+		case !ok && !request:
+			d.change(ChangeFieldRemoved, true, field, "field %q was removed", name)
+		case !ok:
+			d.change(ChangeFieldRemoved, false, field, "field %q was removed", name)
+		default:
+			isRequired := contains(new.Required, name)
+			switch {
+			case request && !wasRequired && isRequired:
+				d.change(ChangeFieldBecameRequired, true, field, "field %q is now required", name)
+			case !request && wasRequired && !isRequired:
+				d.change(ChangeRequiredFieldRemoved, true, field, "field %q is no longer required", name)
+			case wasRequired != isRequired:
+				kind := ChangeFieldBecameOptional
+				if isRequired {
+					kind = ChangeFieldBecameRequired
+				}
+				d.change(kind, false, field, "field %q changed from required=%t to required=%t", name, wasRequired, isRequired)
+			}
+			d.schema(old.Properties[name], next, field, request)
+		}
+	}
+	for _, name := range sortedProperties(new.Properties) {
+		if _, ok := old.Properties[name]; ok {
+			continue
+		}
+		field := location + "." + name
+		if request && contains(new.Required, name) {
+			d.change(ChangeRequiredFieldAdded, true, field, "required field %q was added", name)
+		} else {
+			d.change(ChangeFieldAdded, false, field, "field %q was added", name)
+		}
+	}
+
+	d.schema(old.Items, new.Items, location+"[]", request)
+}
+
+func operationsByPath(doc *Document) map[string]map[string]Operation {
+	byPath := make(map[string]map[string]Operation, len(doc.Paths))
+	for _, path := range doc.Paths {
+		byPath[path] = map[string]Operation{}
+	}
+	for _, op := range doc.Operations {
+		byPath[op.Path][op.Method] = op
+	}
+	return byPath
+}
+
+func sortedOperations(ops map[string]Operation) []Operation {
+	var sorted []Operation
+	for _, method := range Methods {
+		if op, ok := ops[strings.ToUpper(method)]; ok {
+			sorted = append(sorted, op)
+		}
+	}
+	return sorted
+}
+
+func sortedResponseCodes(responses map[string]*Schema) []string {
+	codes := make([]string, 0, len(responses))
+	for code := range responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+func sortedProperties(properties map[string]*Schema) []string {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,172 @@
+package openapi
+
+import (
+	"strings"
+	"testing"
+)
+
+const ordersV1 = `
+openapi: 3.0.0
+info: {title: Orders, version: 1.0.0}
+paths:
+  /orders:
+    get:
+      parameters:
+        - {name: status, in: query, schema: {type: string}}
+      responses:
+        '200':
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: '#/components/schemas/Order'}
+    post:
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required: [item]
+              properties:
+                item: {type: string}
+                note: {type: string}
+      responses:
+        '201': {description: created}
+  /orders/{id}:
+    delete:
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        '204': {description: gone}
+components:
+  schemas:
+    Order:
+      type: object
+      required: [id, total]
+      properties:
+        id: {type: integer}
+        total: {type: number}
+        note: {type: string}
+`
+
+func mustParse(t *testing.T, doc string) *Document {
+	t.Helper()
+	parsed, err := Parse([]byte(doc))
+	if err != nil {
+		t.Fatalf("Error parsing document: %v", err)
+	}
+	return parsed
+}
+
+func TestDiffIdentical(t *testing.T) {
+	report := Diff(mustParse(t, ordersV1), mustParse(t, ordersV1))
+	if report.Breaking || len(report.Changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", report)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     []Change
+	}{
+		{
+			name: "RemovedPath",
+			from: ordersV1,
+			to:   replace(ordersV1, "  /orders/{id}:\n    delete:\n      parameters:\n        - {name: id, in: path, required: true, schema: {type: integer}}\n      responses:\n        '204': {description: gone}\n", ""),
+			want: []Change{{Type: ChangePathRemoved, Breaking: true, Path: "/orders/{id}"}},
+		},
+		{
+			name: "NewOptionalParameter",
+			from: ordersV1,
+			to:   replace(ordersV1, "        - {name: status, in: query, schema: {type: string}}\n", "        - {name: status, in: query, schema: {type: string}}\n        - {name: limit, in: query, schema: {type: integer}}\n"),
+			want: []Change{{Type: ChangeParameterAdded, Method: "GET", Path: "/orders", Location: "query.limit"}},
+		},
+		{
+			name: "NewRequiredParameter",
+			from: ordersV1,
+			to:   replace(ordersV1, "        - {name: status, in: query, schema: {type: string}}\n", "        - {name: status, in: query, schema: {type: string}}\n        - {name: tenant, in: header, required: true, schema: {type: string}}\n"),
+			want: []Change{{Type: ChangeParameterAdded, Breaking: true, Method: "GET", Path: "/orders", Location: "header.tenant"}},
+		},
+		{
+			name: "ChangedParameterType",
+			from: ordersV1,
+			to:   replace(ordersV1, "{name: id, in: path, required: true, schema: {type: integer}}", "{name: id, in: path, required: true, schema: {type: string}}"),
+			want: []Change{{Type: ChangeTypeChanged, Breaking: true, Method: "DELETE", Path: "/orders/{id}", Location: "path.id"}},
+		},
+		{
+			name: "RemovedRequiredResponseField",
+			from: ordersV1,
+			to:   replace(ordersV1, "      required: [id, total]\n      properties:\n        id: {type: integer}\n        total: {type: number}\n", "      required: [id]\n      properties:\n        id: {type: integer}\n"),
+			want: []Change{{Type: ChangeRequiredFieldRemoved, Breaking: true, Method: "GET", Path: "/orders", Location: "response.200[].total"}},
+		},
+		{
+			name: "ChangedResponseFieldType",
+			from: ordersV1,
+			to:   replace(ordersV1, "total: {type: number}", "total: {type: string}"),
+			want: []Change{{Type: ChangeTypeChanged, Breaking: true, Method: "GET", Path: "/orders", Location: "response.200[].total"}},
+		},
+		{
+			name: "RequestFieldChanges",
+			from: ordersV1,
+			to: replace(ordersV1, "              required: [item]\n              properties:\n                item: {type: string}\n                note: {type: string}\n",
+				"              required: [item, quantity]\n              properties:\n                item: {type: string}\n                quantity: {type: integer}\n                gift: {type: boolean}\n"),
+			want: []Change{
+				{Type: ChangeFieldRemoved, Method: "POST", Path: "/orders", Location: "request.note"},
+				{Type: ChangeFieldAdded, Method: "POST", Path: "/orders", Location: "request.gift"},
+				{Type: ChangeRequiredFieldAdded, Breaking: true, Method: "POST", Path: "/orders", Location: "request.quantity"},
+			},
+		},
+		{
+			name: "RemovedOperationAndResponse",
+			from: ordersV1,
+			to:   replace(replace(ordersV1, "    delete:", "    put:"), "'201': {description: created}", "'202': {description: accepted}"),
+			want: []Change{
+				{Type: ChangeResponseRemoved, Breaking: true, Method: "POST", Path: "/orders", Location: "response.201"},
+				{Type: ChangeResponseAdded, Method: "POST", Path: "/orders", Location: "response.202"},
+				{Type: ChangeOperationRemoved, Breaking: true, Method: "DELETE", Path: "/orders/{id}"},
+				{Type: ChangeOperationAdded, Method: "PUT", Path: "/orders/{id}"},
+			},
+		},
+		{
+			name: "AddedPath",
+			from: ordersV1,
+			to:   replace(ordersV1, "components:\n", "  /health:\n    get:\n      responses: {'200': {description: ok}}\ncomponents:\n"),
+			want: []Change{{Type: ChangePathAdded, Path: "/health"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Diff(mustParse(t, tt.from), mustParse(t, tt.to))
+
+			if len(report.Changes) != len(tt.want) {
+				t.Fatalf("Expected %d changes, got %+v", len(tt.want), report.Changes)
+			}
+			breaking := false
+			for i, want := range tt.want {
+				got := report.Changes[i]
+				if got.Message == "" {
+					t.Errorf("Change %d has no message", i)
+				}
+				got.Message = ""
+				if got != want {
+					t.Errorf("Change %d:\n got  %+v\n want %+v", i, got, want)
+				}
+				breaking = breaking || want.Breaking
+			}
+			if report.Breaking != breaking {
+				t.Errorf("Expected Breaking=%t, got %t", breaking, report.Breaking)
+			}
+		})
+	}
+}
+
+func replace(doc, old, new string) string {
+	if !strings.Contains(doc, old) {
+		panic("fixture does not contain " + old)
+	}
+	return strings.Replace(doc, old, new, 1)
+}
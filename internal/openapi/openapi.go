@@ -0,0 +1,647 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Methods lists the HTTP methods a path item may define, in the order
+// operations of the same path are reported.
+var Methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Document is an OpenAPI 2.0 or 3.x description normalized so that both
+// versions look alike: servers are derived from host, basePath and schemes in
+// 2.0, body parameters become request bodies, and every $ref is resolved.
+type Document struct {
+	SpecVersion     string           `json:"spec_version"`
+	Title           string           `json:"title"`
+	Version         string           `json:"version"`
+	Servers         []Server         `json:"servers"`
+	SecuritySchemes []SecurityScheme `json:"security_schemes"`
+	Paths           []string         `json:"paths"`
+	Operations      []Operation      `json:"operations"`
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type SecurityScheme struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	Description  string `json:"description,omitempty"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearer_format,omitempty"`
+	In           string `json:"in,omitempty"`
+	ParamName    string `json:"param_name,omitempty"`
+}
+
+type Operation struct {
+	Method              string             `json:"method"`
+	Path                string             `json:"path"`
+	OperationID         string             `json:"operation_id,omitempty"`
+	Summary             string             `json:"summary,omitempty"`
+	Tags                []string           `json:"tags,omitempty"`
+	Deprecated          bool               `json:"deprecated,omitempty"`
+	Parameters          []Parameter        `json:"parameters,omitempty"`
+	RequestBody         *Schema            `json:"request_body,omitempty"`
+	RequestBodyRequired bool               `json:"request_body_required,omitempty"`
+	Responses           map[string]*Schema `json:"responses"`
+	Security            []string           `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema,omitempty"`
+}
+
+// Schema keeps the parts of a JSON schema that describe the shape of a
+// payload. Ref names the schema a $ref pointed to when it could not be
+// expanded any further because it refers back to itself.
+type Schema struct {
+	Type       string             `json:"type,omitempty"`
+	Format     string             `json:"format,omitempty"`
+	Required   []string           `json:"required,omitempty"`
+	Properties map[string]*Schema `json:"properties,omitempty"`
+	Items      *Schema            `json:"items,omitempty"`
+	Ref        string             `json:"ref,omitempty"`
+}
+
+// Error lists every problem found in a document that is not a valid OpenAPI
+// description.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return "invalid OpenAPI document: " + strings.Join(e.Problems, "; ")
+}
+
+// maxSchemaNodes caps how many schema nodes the schemas of a document may
+// expand to once every $ref is followed. References can nest so that the
+// expansion grows exponentially with the size of the document.
+const maxSchemaNodes = 100000
+
+var (
+	openAPI3Pattern  = regexp.MustCompile(`^3\.[01]\.\d+$`)
+	pathParamPattern = regexp.MustCompile(`\{([^{}]+)\}`)
+)
+
+// Parse reads an OpenAPI 2.0 or 3.x document in JSON or YAML.
+func Parse(data []byte) (*Document, error) {
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, &Error{Problems: []string{"document is not valid JSON or YAML: " + err.Error()}}
+	}
+	root, ok := asMap(raw)
+	if !ok {
+		return nil, &Error{Problems: []string{"document must be an object"}}
+	}
+
+	p := &parser{root: root, refs: make(map[string]expandedRef)}
+	doc := p.parse()
+	if len(p.problems) > 0 {
+		return nil, &Error{Problems: p.problems}
+	}
+	return doc, nil
+}
+
+type parser struct {
+	root     map[string]interface{}
+	v2       bool
+	problems []string
+
+	// refs holds the expansion of each $ref that does not depend on where
+	// it is used, and nodes counts the schema nodes expanded so far.
+	refs  map[string]expandedRef
+	nodes int
+}
+
+type expandedRef struct {
+	schema *Schema
+	nodes  int
+}
+
+// noCycle is the cycle index of a schema that refers to none of the
+// references being expanded around it.
+const noCycle = math.MaxInt
+
+func (p *parser) problem(format string, args ...interface{}) {
+	p.problems = append(p.problems, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parse() *Document {
+	doc := &Document{}
+
+	swagger, openapi := scalar(p.root["swagger"]), scalar(p.root["openapi"])
+	switch {
+	case swagger == "2.0" || swagger == "2":
+		p.v2 = true
+		doc.SpecVersion = "2.0"
+	case openAPI3Pattern.MatchString(openapi):
+		doc.SpecVersion = openapi
+	default:
+		p.problem(`document must declare swagger: "2.0" or openapi: 3.0.x or 3.1.x`)
+		return doc
+	}
+
+	info, _ := asMap(p.root["info"])
+	doc.Title = scalar(info["title"])
+	doc.Version = scalar(info["version"])
+	if doc.Title == "" {
+		p.problem("info.title is required")
+	}
+	if doc.Version == "" {
+		p.problem("info.version is required")
+	}
+
+	doc.Servers = p.servers()
+	doc.SecuritySchemes = p.securitySchemes()
+
+	schemes := make(map[string]bool, len(doc.SecuritySchemes))
+	for _, s := range doc.SecuritySchemes {
+		schemes[s.Name] = true
+	}
+	defaultSecurity := p.security(p.root["security"], "security", schemes)
+
+	paths, ok := asMap(p.root["paths"])
+	if !ok && (p.v2 || strings.HasPrefix(doc.SpecVersion, "3.0")) {
+		p.problem("paths is required")
+	}
+
+	operationIDs := make(map[string]string)
+	for _, path := range sortedKeys(paths) {
+		if !strings.HasPrefix(path, "/") {
+			p.problem("path %q must begin with /", path)
+			continue
+		}
+		doc.Paths = append(doc.Paths, path)
+
+		item, _ := asMap(p.resolve(paths[path]))
+		shared := p.parameters(item["parameters"], path)
+		for _, method := range Methods {
+			raw, ok := asMap(item[method])
+			if !ok {
+				continue
+			}
+			op := p.operation(method, path, raw, shared, schemes, defaultSecurity)
+			if op.OperationID != "" {
+				if other, dup := operationIDs[op.OperationID]; dup {
+					p.problem("operationId %q is used by both %s and %s %s", op.OperationID, other, strings.ToUpper(method), path)
+				}
+				operationIDs[op.OperationID] = strings.ToUpper(method) + " " + path
+			}
+			doc.Operations = append(doc.Operations, op)
+		}
+	}
+
+	if doc.Servers == nil {
+		doc.Servers = []Server{}
+	}
+	if doc.SecuritySchemes == nil {
+		doc.SecuritySchemes = []SecurityScheme{}
+	}
+	if doc.Paths == nil {
+		doc.Paths = []string{}
+	}
+	if doc.Operations == nil {
+		doc.Operations = []Operation{}
+	}
+	return doc
+}
+
+func (p *parser) servers() []Server {
+	if p.v2 {
+		host := scalar(p.root["host"])
+		basePath := scalar(p.root["basePath"])
+		if host == "" {
+			if basePath == "" {
+				return nil
+			}
+			return []Server{{URL: basePath}}
+		}
+		schemes := stringList(p.root["schemes"])
+		if len(schemes) == 0 {
+			schemes = []string{"https"}
+		}
+		var servers []Server
+		for _, scheme := range schemes {
+			servers = append(servers, Server{URL: scheme + "://" + host + basePath})
+		}
+		return servers
+	}
+
+	var servers []Server
+	for i, raw := range asSlice(p.root["servers"]) {
+		server, _ := asMap(raw)
+		url := scalar(server["url"])
+		if url == "" {
+			p.problem("servers[%d].url is required", i)
+			continue
+		}
+		servers = append(servers, Server{URL: url, Description: scalar(server["description"])})
+	}
+	return servers
+}
+
+func (p *parser) securitySchemes() []SecurityScheme {
+	var definitions map[string]interface{}
+	var validTypes []string
+	if p.v2 {
+		definitions, _ = asMap(p.root["securityDefinitions"])
+		validTypes = []string{"basic", "apiKey", "oauth2"}
+	} else {
+		components, _ := asMap(p.root["components"])
+		definitions, _ = asMap(components["securitySchemes"])
+		validTypes = []string{"apiKey", "http", "oauth2", "openIdConnect", "mutualTLS"}
+	}
+
+	var schemes []SecurityScheme
+	for _, name := range sortedKeys(definitions) {
+		raw, _ := asMap(p.resolve(definitions[name]))
+		scheme := SecurityScheme{
+			Name:         name,
+			Type:         scalar(raw["type"]),
+			Description:  scalar(raw["description"]),
+			Scheme:       scalar(raw["scheme"]),
+			BearerFormat: scalar(raw["bearerFormat"]),
+			In:           scalar(raw["in"]),
+			ParamName:    scalar(raw["name"]),
+		}
+		if !contains(validTypes, scheme.Type) {
+			p.problem("security scheme %q has invalid type %q", name, scheme.Type)
+		}
+		if scheme.Type == "apiKey" && (scheme.ParamName == "" || scheme.In == "") {
+			p.problem("security scheme %q must declare name and in", name)
+		}
+		if scheme.Type == "http" && scheme.Scheme == "" {
+			p.problem("security scheme %q must declare scheme", name)
+		}
+		schemes = append(schemes, scheme)
+	}
+	return schemes
+}
+
+// security returns the scheme names used by a list of security requirements.
+func (p *parser) security(raw interface{}, where string, schemes map[string]bool) []string {
+	var names []string
+	for _, requirement := range asSlice(raw) {
+		req, _ := asMap(requirement)
+		for _, name := range sortedKeys(req) {
+			if !schemes[name] {
+				p.problem("%s references undefined security scheme %q", where, name)
+				continue
+			}
+			if !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+func (p *parser) operation(method, path string, raw map[string]interface{}, shared []Parameter, schemes map[string]bool, defaultSecurity []string) Operation {
+	where := strings.ToUpper(method) + " " + path
+	op := Operation{
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		OperationID: scalar(raw["operationId"]),
+		Summary:     scalar(raw["summary"]),
+		Tags:        stringList(raw["tags"]),
+		Deprecated:  raw["deprecated"] == true,
+		Responses:   map[string]*Schema{},
+		Security:    defaultSecurity,
+	}
+	if _, ok := raw["security"]; ok {
+		op.Security = p.security(raw["security"], where+" security", schemes)
+	}
+
+	params := append([]Parameter(nil), shared...)
+	for _, param := range p.parameters(raw["parameters"], where) {
+		replaced := false
+		for i := range params {
+			if params[i].Name == param.Name && params[i].In == param.In {
+				params[i] = param
+				replaced = true
+			}
+		}
+		if !replaced {
+			params = append(params, param)
+		}
+	}
+
+	for _, param := range params {
+		if param.In == "body" {
+			op.RequestBody = param.Schema
+			op.RequestBodyRequired = param.Required
+			continue
+		}
+		op.Parameters = append(op.Parameters, param)
+	}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		declared := false
+		for _, param := range op.Parameters {
+			declared = declared || (param.In == "path" && param.Name == match[1])
+		}
+		if !declared {
+			p.problem("%s does not declare path parameter %q", where, match[1])
+		}
+	}
+
+	if body, ok := asMap(p.resolve(raw["requestBody"])); ok {
+		op.RequestBody = p.contentSchema(body["content"])
+		op.RequestBodyRequired = body["required"] == true
+	}
+
+	responses, ok := asMap(raw["responses"])
+	if (!ok || len(responses) == 0) && (p.v2 || !strings.HasPrefix(scalar(p.root["openapi"]), "3.1")) {
+		p.problem("%s must declare at least one response", where)
+	}
+	for _, code := range sortedKeys(responses) {
+		response, _ := asMap(p.resolve(responses[code]))
+		if p.v2 {
+			op.Responses[code] = p.schema(response["schema"])
+		} else {
+			op.Responses[code] = p.contentSchema(response["content"])
+		}
+	}
+	return op
+}
+
+func (p *parser) parameters(raw interface{}, where string) []Parameter {
+	var params []Parameter
+	for i, item := range asSlice(raw) {
+		param, _ := asMap(p.resolve(item))
+		name, in := scalar(param["name"]), scalar(param["in"])
+		if name == "" || in == "" {
+			p.problem("%s parameters[%d] must declare name and in", where, i)
+			continue
+		}
+
+		valid := []string{"query", "header", "path", "cookie"}
+		if p.v2 {
+			valid = []string{"query", "header", "path", "formData", "body"}
+		}
+		if !contains(valid, in) {
+			p.problem("%s parameter %q has invalid location %q", where, name, in)
+			continue
+		}
+		if in == "path" && param["required"] != true {
+			p.problem("%s path parameter %q must be required", where, name)
+		}
+
+		result := Parameter{Name: name, In: in, Required: param["required"] == true}
+		if _, ok := param["schema"]; ok {
+			result.Schema = p.schema(param["schema"])
+		} else if p.v2 {
+			// 2.0 declares the type of non-body parameters inline.
+			result.Schema = p.schema(param)
+		}
+		params = append(params, result)
+	}
+	return params
+}
+
+// contentSchema picks the JSON media type of a 3.x content map, or the first
+// media type when there is none.
+func (p *parser) contentSchema(raw interface{}) *Schema {
+	content, ok := asMap(raw)
+	if !ok || len(content) == 0 {
+		return nil
+	}
+	mediaType := ""
+	for _, key := range sortedKeys(content) {
+		if key == "application/json" || strings.HasSuffix(key, "+json") {
+			mediaType = key
+			break
+		}
+	}
+	if mediaType == "" {
+		mediaType = sortedKeys(content)[0]
+	}
+	media, _ := asMap(content[mediaType])
+	return p.schema(media["schema"])
+}
+
+// schema converts a JSON schema, following $refs.
+func (p *parser) schema(raw interface{}) *Schema {
+	s, _ := p.expand(raw, nil)
+	return s
+}
+
+// expand converts a JSON schema, following $refs. seen holds the references
+// being expanded so that recursive schemas terminate; the returned index is
+// the lowest one in seen that the schema refers back to, or noCycle. A
+// reference whose expansion refers back to nothing outside it is expanded
+// once and its *Schema shared by every use.
+func (p *parser) expand(raw interface{}, seen []string) (*Schema, int) {
+	node, ok := asMap(raw)
+	if !ok || p.nodes > maxSchemaNodes {
+		return nil, noCycle
+	}
+
+	if ref := scalar(node["$ref"]); ref != "" {
+		for i, s := range seen {
+			if s == ref {
+				p.count(1)
+				return &Schema{Ref: ref}, i
+			}
+		}
+		if expanded, ok := p.refs[ref]; ok {
+			p.count(expanded.nodes)
+			return expanded.schema, noCycle
+		}
+		target, ok := p.lookup(ref)
+		if !ok {
+			p.problem("unresolved reference %q", ref)
+			return nil, noCycle
+		}
+		before := p.nodes
+		s, cycle := p.expand(target, append(seen, ref))
+		if cycle < len(seen) {
+			return s, cycle
+		}
+		if p.nodes <= maxSchemaNodes {
+			p.refs[ref] = expandedRef{schema: s, nodes: p.nodes - before}
+		}
+		return s, noCycle
+	}
+
+	p.count(1)
+	cycle := noCycle
+	expand := func(raw interface{}) *Schema {
+		s, c := p.expand(raw, seen)
+		cycle = min(cycle, c)
+		return s
+	}
+
+	s := &Schema{
+		Type:     scalar(node["type"]),
+		Format:   scalar(node["format"]),
+		Required: stringList(node["required"]),
+	}
+	if properties, ok := asMap(node["properties"]); ok {
+		s.Properties = make(map[string]*Schema, len(properties))
+		for _, name := range sortedKeys(properties) {
+			s.Properties[name] = expand(properties[name])
+		}
+	}
+	if items, ok := node["items"]; ok {
+		s.Items = expand(items)
+	}
+
+	for _, part := range asSlice(node["allOf"]) {
+		merged := expand(part)
+		if merged == nil {
+			continue
+		}
+		if s.Type == "" {
+			s.Type = merged.Type
+		}
+		for _, name := range merged.Required {
+			if !contains(s.Required, name) {
+				s.Required = append(s.Required, name)
+			}
+		}
+		for name, prop := range merged.Properties {
+			if s.Properties == nil {
+				s.Properties = make(map[string]*Schema)
+			}
+			if _, exists := s.Properties[name]; !exists {
+				s.Properties[name] = prop
+			}
+		}
+	}
+
+	if s.Type == "" && s.Properties != nil {
+		s.Type = "object"
+	}
+	return s, cycle
+}
+
+// count adds n expanded schema nodes and reports the document once they
+// exceed maxSchemaNodes.
+func (p *parser) count(n int) {
+	if p.nodes <= maxSchemaNodes && p.nodes+n > maxSchemaNodes {
+		p.problem("schemas expand to more than %d nodes once references are followed", maxSchemaNodes)
+	}
+	p.nodes += n
+}
+
+// resolve follows a $ref on a parameter, response, request body or path item.
+func (p *parser) resolve(raw interface{}) interface{} {
+	for i := 0; i < 16; i++ {
+		node, ok := asMap(raw)
+		if !ok {
+			return raw
+		}
+		ref := scalar(node["$ref"])
+		if ref == "" {
+			return raw
+		}
+		target, ok := p.lookup(ref)
+		if !ok {
+			p.problem("unresolved reference %q", ref)
+			return nil
+		}
+		raw = target
+	}
+	p.problem("reference chain is too long")
+	return nil
+}
+
+// lookup resolves a local JSON pointer reference such as
+// "#/components/schemas/Pet". References to other documents are not followed.
+func (p *parser) lookup(ref string) (interface{}, bool) {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	var node interface{} = p.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := asMap(node)
+		if !ok {
+			return nil, false
+		}
+		if node, ok = m[token]; !ok {
+			return nil, false
+		}
+	}
+	return node, true
+}
+
+// asMap accepts both map types yaml.v3 produces: mappings with non-string
+// keys, such as response codes written as bare numbers, decode into
+// map[interface{}]interface{}.
+func asMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			out[fmt.Sprint(k)] = v
+		}
+		return out, true
+	}
+	return nil, false
+}
+
+func asSlice(v interface{}) []interface{} {
+	s, _ := v.([]interface{})
+	return s
+}
+
+// scalar renders a YAML scalar as a string, so that unquoted values such as
+// version: 1.0 are still usable.
+func scalar(v interface{}) string {
+	switch s := v.(type) {
+	case string:
+		return s
+	case int:
+		return strconv.Itoa(s)
+	case float64:
+		return strconv.FormatFloat(s, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(s)
+	}
+	return ""
+}
+
+func stringList(v interface{}) []string {
+	var out []string
+	for _, item := range asSlice(v) {
+		if s := scalar(item); s != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
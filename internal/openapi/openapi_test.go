@@ -0,0 +1,350 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const petstoreV3 = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1
+security:
+  - bearer: []
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+      responses:
+        200:
+          description: A page of pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: Created
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      operationId: getPet
+      security: []
+      responses:
+        '200':
+          $ref: '#/components/responses/Pet'
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+  responses:
+    Pet:
+      description: A pet
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Pet'
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        parent:
+          $ref: '#/components/schemas/Pet'
+  securitySchemes:
+    bearer:
+      type: http
+      scheme: bearer
+      bearerFormat: JWT
+`
+
+const petstoreV2 = `{
+	"swagger": "2.0",
+	"info": {"title": "Petstore", "version": "1.0.0"},
+	"host": "api.example.com",
+	"basePath": "/v1",
+	"schemes": ["https", "http"],
+	"securityDefinitions": {"key": {"type": "apiKey", "name": "X-API-Key", "in": "header"}},
+	"paths": {
+		"/pets": {
+			"post": {
+				"operationId": "createPet",
+				"security": [{"key": []}],
+				"parameters": [
+					{"name": "dryRun", "in": "query", "type": "boolean"},
+					{"name": "pet", "in": "body", "required": true, "schema": {"$ref": "#/definitions/Pet"}}
+				],
+				"responses": {"201": {"description": "Created", "schema": {"$ref": "#/definitions/Pet"}}}
+			}
+		}
+	},
+	"definitions": {
+		"Pet": {"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}
+	}
+}`
+
+func TestParseOpenAPI3(t *testing.T) {
+	doc, err := Parse([]byte(petstoreV3))
+	if err != nil {
+		t.Fatalf("Expected valid document, got %v", err)
+	}
+
+	if doc.SpecVersion != "3.0.3" || doc.Title != "Petstore" || doc.Version != "1.0.0" {
+		t.Errorf("Unexpected document info: %+v", doc)
+	}
+	if !reflect.DeepEqual(doc.Servers, []Server{{URL: "https://api.example.com/v1"}}) {
+		t.Errorf("Unexpected servers: %+v", doc.Servers)
+	}
+	wantScheme := SecurityScheme{Name: "bearer", Type: "http", Scheme: "bearer", BearerFormat: "JWT"}
+	if !reflect.DeepEqual(doc.SecuritySchemes, []SecurityScheme{wantScheme}) {
+		t.Errorf("Unexpected security schemes: %+v", doc.SecuritySchemes)
+	}
+	if !reflect.DeepEqual(doc.Paths, []string{"/pets", "/pets/{id}"}) {
+		t.Errorf("Unexpected paths: %v", doc.Paths)
+	}
+
+	if len(doc.Operations) != 3 {
+		t.Fatalf("Expected 3 operations, got %d", len(doc.Operations))
+	}
+	list, create, get := doc.Operations[0], doc.Operations[1], doc.Operations[2]
+
+	if list.Method != "GET" || list.OperationID != "listPets" || !reflect.DeepEqual(list.Security, []string{"bearer"}) {
+		t.Errorf("Unexpected listPets operation: %+v", list)
+	}
+	if len(list.Parameters) != 1 || list.Parameters[0].Name != "limit" || list.Parameters[0].Schema.Type != "integer" {
+		t.Errorf("Expected $ref parameter to resolve, got %+v", list.Parameters)
+	}
+	if items := list.Responses["200"]; items == nil || items.Type != "array" || items.Items.Properties["name"].Type != "string" {
+		t.Errorf("Expected array of pets for 200, got %+v", items)
+	}
+
+	if create.Method != "POST" || !create.RequestBodyRequired || create.RequestBody == nil {
+		t.Fatalf("Expected required request body, got %+v", create)
+	}
+	if !reflect.DeepEqual(create.RequestBody.Required, []string{"name"}) {
+		t.Errorf("Unexpected request body: %+v", create.RequestBody)
+	}
+	if parent := create.RequestBody.Properties["parent"]; parent == nil || parent.Ref != "#/components/schemas/Pet" {
+		t.Errorf("Expected recursive schema to stop at its $ref, got %+v", parent)
+	}
+
+	if get.Path != "/pets/{id}" || len(get.Security) != 0 {
+		t.Errorf("Expected security override to clear requirements, got %+v", get)
+	}
+	if len(get.Parameters) != 1 || get.Parameters[0].In != "path" {
+		t.Errorf("Expected path-level parameter to apply, got %+v", get.Parameters)
+	}
+	if get.Responses["200"] == nil || get.Responses["200"].Type != "object" {
+		t.Errorf("Expected $ref response to resolve, got %+v", get.Responses["200"])
+	}
+}
+
+func TestParseSwagger2(t *testing.T) {
+	doc, err := Parse([]byte(petstoreV2))
+	if err != nil {
+		t.Fatalf("Expected valid document, got %v", err)
+	}
+
+	wantServers := []Server{{URL: "https://api.example.com/v1"}, {URL: "http://api.example.com/v1"}}
+	if !reflect.DeepEqual(doc.Servers, wantServers) {
+		t.Errorf("Unexpected servers: %+v", doc.Servers)
+	}
+	if len(doc.SecuritySchemes) != 1 || doc.SecuritySchemes[0].ParamName != "X-API-Key" {
+		t.Errorf("Unexpected security schemes: %+v", doc.SecuritySchemes)
+	}
+
+	if len(doc.Operations) != 1 {
+		t.Fatalf("Expected 1 operation, got %d", len(doc.Operations))
+	}
+	op := doc.Operations[0]
+	if len(op.Parameters) != 1 || op.Parameters[0].Schema.Type != "boolean" {
+		t.Errorf("Expected inline-typed query parameter only, got %+v", op.Parameters)
+	}
+	if op.RequestBody == nil || !op.RequestBodyRequired || op.RequestBody.Properties["name"] == nil {
+		t.Errorf("Expected body parameter as request body, got %+v", op)
+	}
+	if op.Responses["201"] == nil || op.Responses["201"].Type != "object" {
+		t.Errorf("Unexpected responses: %+v", op.Responses)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+		problems []string
+	}{
+		{
+			name:     "NotADocument",
+			document: "- just\n- a list",
+			problems: []string{"document must be an object"},
+		},
+		{
+			name:     "MissingVersion",
+			document: `{"info": {"title": "x", "version": "1"}, "paths": {}}`,
+			problems: []string{`document must declare swagger: "2.0" or openapi: 3.0.x or 3.1.x`},
+		},
+		{
+			name:     "UnsupportedVersion",
+			document: `{"openapi": "4.0.0", "info": {"title": "x", "version": "1"}, "paths": {}}`,
+			problems: []string{`document must declare swagger: "2.0" or openapi: 3.0.x or 3.1.x`},
+		},
+		{
+			name: "Many",
+			document: `
+openapi: 3.0.0
+info: {}
+paths:
+  pets:
+    get:
+      responses: {}
+  /pets/{id}:
+    get:
+      operationId: getPet
+      security:
+        - oauth: []
+      parameters:
+        - in: query
+      responses:
+        '200': {description: ok}
+    delete:
+      operationId: getPet
+      responses:
+        '204': {description: gone}
+`,
+			problems: []string{
+				"info.title is required",
+				"info.version is required",
+				`GET /pets/{id} security references undefined security scheme "oauth"`,
+				"GET /pets/{id} parameters[0] must declare name and in",
+				`GET /pets/{id} does not declare path parameter "id"`,
+				`DELETE /pets/{id} does not declare path parameter "id"`,
+				`operationId "getPet" is used by both GET /pets/{id} and DELETE /pets/{id}`,
+				`path "pets" must begin with /`,
+			},
+		},
+		{
+			name:     "MissingResponses",
+			document: `{"swagger": "2.0", "info": {"title": "x", "version": "1"}, "paths": {"/a": {"get": {}}}}`,
+			problems: []string{"GET /a must declare at least one response"},
+		},
+		{
+			name:     "UnresolvedReference",
+			document: `{"openapi": "3.1.0", "info": {"title": "x", "version": "1"}, "paths": {"/a": {"get": {"parameters": [{"$ref": "#/components/parameters/Nope"}]}}}}`,
+			problems: []string{
+				`unresolved reference "#/components/parameters/Nope"`,
+				"GET /a parameters[0] must declare name and in",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.document))
+			specErr, ok := err.(*Error)
+			if !ok {
+				t.Fatalf("Expected *Error, got %v", err)
+			}
+			if !reflect.DeepEqual(specErr.Problems, tt.problems) {
+				t.Errorf("Unexpected problems:\n got  %q\n want %q", specErr.Problems, tt.problems)
+			}
+		})
+	}
+
+	if _, err := Parse([]byte("{not: [valid")); err == nil || !strings.Contains(err.Error(), "not valid JSON or YAML") {
+		t.Errorf("Expected syntax error, got %v", err)
+	}
+}
+
+func TestParseOpenAPI31WithoutPaths(t *testing.T) {
+	doc, err := Parse([]byte(`{"openapi": "3.1.0", "info": {"title": "Hooks", "version": "1.0.0"}, "webhooks": {}}`))
+	if err != nil {
+		t.Fatalf("Expected 3.1 document without paths to be valid, got %v", err)
+	}
+	if len(doc.Paths) != 0 || len(doc.Operations) != 0 {
+		t.Errorf("Expected no paths or operations, got %+v", doc)
+	}
+}
+
+// nestedRefs returns a document of depth schemas that each refer to the next
+// one twice, so that following every $ref doubles the size at each level.
+// With cyclic set, the last schema refers back to the first.
+func nestedRefs(depth int, cyclic bool) []byte {
+	var b strings.Builder
+	b.WriteString(`{"openapi": "3.0.3", "info": {"title": "Nested", "version": "1.0.0"}, "paths": {"/a": {"get": {"responses": {"200": {"description": "ok", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/S0"}}}}}}}}, "components": {"schemas": {`)
+	for i := 0; i < depth; i++ {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		next := fmt.Sprintf(`{"$ref": "#/components/schemas/S%d"}`, i+1)
+		if i == depth-1 {
+			next = `{"type": "string"}`
+			if cyclic {
+				next = `{"$ref": "#/components/schemas/S0"}`
+			}
+		}
+		fmt.Fprintf(&b, `"S%d": {"type": "object", "properties": {"left": %s, "right": %s}}`, i, next, next)
+	}
+	b.WriteString(`}}}`)
+	return []byte(b.String())
+}
+
+func TestParseNestedReferences(t *testing.T) {
+	t.Run("SharesExpandedSchemas", func(t *testing.T) {
+		doc, err := Parse(nestedRefs(6, false))
+		if err != nil {
+			t.Fatalf("Expected document to parse, got %v", err)
+		}
+		s0 := doc.Operations[0].Responses["200"]
+		if s0.Properties["left"] != s0.Properties["right"] {
+			t.Errorf("Expected both uses of S1 to share one expansion")
+		}
+		if leaf := s0.Properties["left"].Properties["left"].Properties["left"].Properties["left"].Properties["left"].Properties["left"]; leaf == nil || leaf.Type != "string" {
+			t.Errorf("Expected the innermost schema to be expanded, got %+v", leaf)
+		}
+	})
+
+	for _, cyclic := range []bool{false, true} {
+		t.Run(fmt.Sprintf("TooLarge/cyclic=%v", cyclic), func(t *testing.T) {
+			start := time.Now()
+			_, err := Parse(nestedRefs(30, cyclic))
+			var perr *Error
+			if !errors.As(err, &perr) || !strings.Contains(err.Error(), "schemas expand to more than") {
+				t.Errorf("Expected the expansion to be capped, got %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("Expected parsing to stop early, took %v", elapsed)
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"microd-api/internal/models"
+)
+
+// liveDependencies is a common table expression of the dependencies whose
+// ends are both outside the trash.
+const liveDependencies = `live_dependencies(api_id, depends_on_id, created_at) AS (
+		SELECT d.api_id, d.depends_on_id, d.created_at FROM api_dependencies d
+		JOIN apis a ON a.id = d.api_id AND a.deleted_at IS NULL
+		JOIN apis b ON b.id = d.depends_on_id AND b.deleted_at IS NULL
+	)`
+
+func scanDependencyNode(row rowScanner) (models.DependencyNode, error) {
+	var node models.DependencyNode
+	err := row.Scan(&node.ID, &node.Name, &node.TeamID, &node.Lifecycle, &node.Depth)
+	return node, err
+}
+
+// AddAPIDependency records that apiID consumes dependsOnID. Recording it
+// again is not an error. It returns ErrNotFound when either API does not
+// exist.
+func (r *SQLiteAPIRepository) AddAPIDependency(ctx context.Context, apiID, dependsOnID int64) error {
+	query := `INSERT OR IGNORE INTO api_dependencies (api_id, depends_on_id) VALUES (?, ?)`
+	_, err := r.db.ExecContext(ctx, query, apiID, dependsOnID)
+	if isForeignKeyViolation(err) {
+		return ErrNotFound
+	}
+	return translateError(err)
+}
+
+func (r *SQLiteAPIRepository) RemoveAPIDependency(ctx context.Context, apiID, dependsOnID int64) error {
+	query := `DELETE FROM api_dependencies WHERE api_id = ? AND depends_on_id = ?`
+	return checkAffected(r.db.ExecContext(ctx, query, apiID, dependsOnID))
+}
+
+// ListAPIDependencies returns the APIs that apiID consumes, directly or
+// through other APIs, up to maxDepth edges away. A maxDepth of 0 follows
+// every path. The nearest APIs come first.
+func (r *SQLiteAPIRepository) ListAPIDependencies(ctx context.Context, apiID int64, maxDepth int) ([]models.DependencyNode, error) {
+	return r.walkDependencies(ctx, "api_id", "depends_on_id", apiID, maxDepth)
+}
+
+// ListAPIDependents is ListAPIDependencies in the other direction: the APIs
+// that consume apiID, directly or through other APIs.
+func (r *SQLiteAPIRepository) ListAPIDependents(ctx context.Context, apiID int64, maxDepth int) ([]models.DependencyNode, error) {
+	return r.walkDependencies(ctx, "depends_on_id", "api_id", apiID, maxDepth)
+}
+
+// walkDependencies follows edges from the column from to the column to. The
+// walk keeps one row per API and depth, so on a cycle it stops once the depth
+// exceeds the number of APIs, by which point every shortest path is known.
+func (r *SQLiteAPIRepository) walkDependencies(ctx context.Context, from, to string, apiID int64, maxDepth int) ([]models.DependencyNode, error) {
+	query := `WITH RECURSIVE ` + liveDependencies + `,
+		walk(id, depth) AS (
+			SELECT ` + to + `, 1 FROM live_dependencies WHERE ` + from + ` = ?
+			UNION
+			SELECT d.` + to + `, w.depth + 1 FROM live_dependencies d
+			JOIN walk w ON d.` + from + ` = w.id
+			WHERE w.depth < COALESCE(NULLIF(?, 0), (SELECT COUNT(*) FROM apis))
+		)
+		SELECT a.id, a.name, COALESCE(a.team_id, 0), a.lifecycle, MIN(w.depth)
+		FROM walk w
+		JOIN apis a ON a.id = w.id
+		WHERE a.id != ?
+		GROUP BY a.id
+		ORDER BY MIN(w.depth), a.name, a.id`
+	rows, err := r.db.QueryContext(ctx, query, apiID, maxDepth, apiID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nodes := []models.DependencyNode{}
+	for rows.Next() {
+		node, err := scanDependencyNode(rows)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, rows.Err()
+}
+
+// GetDependencyGraph returns every dependency between APIs outside the trash
+// together with the APIs they connect. Cycles is left for the caller.
+func (r *SQLiteAPIRepository) GetDependencyGraph(ctx context.Context) (models.DependencyGraph, error) {
+	graph := models.DependencyGraph{Nodes: []models.DependencyNode{}, Edges: []models.APIDependency{}}
+
+	query := `WITH ` + liveDependencies + `
+		SELECT api_id, depends_on_id, created_at FROM live_dependencies
+		ORDER BY api_id, depends_on_id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return models.DependencyGraph{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var edge models.APIDependency
+		if err := rows.Scan(&edge.APIID, &edge.DependsOnID, nullable(&edge.CreatedAt)); err != nil {
+			return models.DependencyGraph{}, err
+		}
+		graph.Edges = append(graph.Edges, edge)
+	}
+	if err := rows.Err(); err != nil {
+		return models.DependencyGraph{}, err
+	}
+
+	query = `WITH ` + liveDependencies + `
+		SELECT a.id, a.name, COALESCE(a.team_id, 0), a.lifecycle, 0 FROM apis a
+		WHERE a.id IN (SELECT api_id FROM live_dependencies UNION SELECT depends_on_id FROM live_dependencies)
+		ORDER BY a.name, a.id`
+	nodeRows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return models.DependencyGraph{}, err
+	}
+	defer nodeRows.Close()
+
+	for nodeRows.Next() {
+		node, err := scanDependencyNode(nodeRows)
+		if err != nil {
+			return models.DependencyGraph{}, err
+		}
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	return graph, nodeRows.Err()
+}
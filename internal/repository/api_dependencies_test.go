@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestAPIDependencies(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	// Checkout consumes Orders and Payments, Orders consumes Payments and
+	// Payments consumes Ledger.
+	checkout, _ := repo.CreateAPI(ctx, models.API{Name: "Checkout"}, 0)
+	orders, _ := repo.CreateAPI(ctx, models.API{Name: "Orders"}, 0)
+	payments, _ := repo.CreateAPI(ctx, models.API{Name: "Payments"}, 0)
+	ledger, _ := repo.CreateAPI(ctx, models.API{Name: "Ledger"}, 0)
+	for _, edge := range [][2]int64{{checkout, orders}, {checkout, payments}, {orders, payments}, {payments, ledger}} {
+		if err := repo.AddAPIDependency(ctx, edge[0], edge[1]); err != nil {
+			t.Fatalf("Error adding dependency %v: %v", edge, err)
+		}
+	}
+
+	ids := func(nodes []models.DependencyNode) [][2]int64 {
+		result := [][2]int64{}
+		for _, node := range nodes {
+			result = append(result, [2]int64{node.ID, int64(node.Depth)})
+		}
+		return result
+	}
+
+	t.Run("Add", func(t *testing.T) {
+		if err := repo.AddAPIDependency(ctx, checkout, orders); err != nil {
+			t.Errorf("Expected adding a dependency twice to succeed, got %v", err)
+		}
+		if err := repo.AddAPIDependency(ctx, checkout, 999); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound for a missing API, got %v", err)
+		}
+	})
+
+	t.Run("Dependencies", func(t *testing.T) {
+		nodes, err := repo.ListAPIDependencies(ctx, checkout, 0)
+		if err != nil {
+			t.Fatalf("Error listing dependencies: %v", err)
+		}
+		expected := [][2]int64{{orders, 1}, {payments, 1}, {ledger, 2}}
+		if !reflect.DeepEqual(ids(nodes), expected) {
+			t.Errorf("Expected %v, got %v", expected, ids(nodes))
+		}
+		if nodes[0].Name != "Orders" || nodes[0].Lifecycle != models.LifecycleStable {
+			t.Errorf("Unexpected node: %+v", nodes[0])
+		}
+
+		direct, _ := repo.ListAPIDependencies(ctx, checkout, 1)
+		if expected := [][2]int64{{orders, 1}, {payments, 1}}; !reflect.DeepEqual(ids(direct), expected) {
+			t.Errorf("Expected direct dependencies %v, got %v", expected, ids(direct))
+		}
+	})
+
+	t.Run("Dependents", func(t *testing.T) {
+		nodes, err := repo.ListAPIDependents(ctx, ledger, 0)
+		if err != nil {
+			t.Fatalf("Error listing dependents: %v", err)
+		}
+		expected := [][2]int64{{payments, 1}, {checkout, 2}, {orders, 2}}
+		if !reflect.DeepEqual(ids(nodes), expected) {
+			t.Errorf("Expected %v, got %v", expected, ids(nodes))
+		}
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		if err := repo.AddAPIDependency(ctx, ledger, checkout); err != nil {
+			t.Fatalf("Error adding dependency: %v", err)
+		}
+		defer repo.RemoveAPIDependency(ctx, ledger, checkout)
+
+		nodes, err := repo.ListAPIDependencies(ctx, payments, 0)
+		if err != nil {
+			t.Fatalf("Error listing dependencies: %v", err)
+		}
+		expected := [][2]int64{{ledger, 1}, {checkout, 2}, {orders, 3}}
+		if !reflect.DeepEqual(ids(nodes), expected) {
+			t.Errorf("Expected the walk to stop at the start, got %v", ids(nodes))
+		}
+	})
+
+	t.Run("Graph", func(t *testing.T) {
+		graph, err := repo.GetDependencyGraph(ctx)
+		if err != nil {
+			t.Fatalf("Error getting graph: %v", err)
+		}
+		if len(graph.Nodes) != 4 || graph.Nodes[0].Name != "Checkout" || graph.Nodes[1].Name != "Ledger" {
+			t.Errorf("Expected the four APIs by name, got %+v", graph.Nodes)
+		}
+		if len(graph.Edges) != 4 || graph.Edges[0].APIID != checkout || graph.Edges[0].DependsOnID != orders || graph.Edges[0].CreatedAt.IsZero() {
+			t.Errorf("Unexpected edges: %+v", graph.Edges)
+		}
+	})
+
+	t.Run("Trash", func(t *testing.T) {
+		if err := repo.DeleteAPI(ctx, payments, 0, 0); err != nil {
+			t.Fatalf("Error deleting API: %v", err)
+		}
+		nodes, _ := repo.ListAPIDependencies(ctx, checkout, 0)
+		if expected := [][2]int64{{orders, 1}}; !reflect.DeepEqual(ids(nodes), expected) {
+			t.Errorf("Expected the trashed API to cut its edges, got %v", ids(nodes))
+		}
+		graph, _ := repo.GetDependencyGraph(ctx)
+		if len(graph.Nodes) != 2 || len(graph.Edges) != 1 {
+			t.Errorf("Expected only Checkout -> Orders, got %+v", graph)
+		}
+
+		if err := repo.UndeleteAPI(ctx, payments, 0); err != nil {
+			t.Fatalf("Error restoring API: %v", err)
+		}
+		nodes, _ = repo.ListAPIDependencies(ctx, checkout, 0)
+		if len(nodes) != 3 {
+			t.Errorf("Expected the edges back after a restore, got %v", ids(nodes))
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		if err := repo.RemoveAPIDependency(ctx, orders, payments); err != nil {
+			t.Fatalf("Error removing dependency: %v", err)
+		}
+		if err := repo.RemoveAPIDependency(ctx, orders, payments); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		nodes, _ := repo.ListAPIDependents(ctx, payments, 0)
+		if expected := [][2]int64{{checkout, 1}}; !reflect.DeepEqual(ids(nodes), expected) {
+			t.Errorf("Expected %v, got %v", expected, ids(nodes))
+		}
+	})
+}
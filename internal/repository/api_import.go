@@ -0,0 +1,33 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"microd-api/internal/models"
+)
+
+// ImportAPIs writes a batch of APIs in one transaction: those with an ID
+// replace the live API with that ID, the others are created. It returns the
+// ID of every API in the order given. When one write fails the whole batch is
+// rolled back and the error is a *BatchError naming it.
+func (r *SQLiteAPIRepository) ImportAPIs(ctx context.Context, apis []models.API, actorID int64) ([]int64, error) {
+	ids := make([]int64, len(apis))
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		for i, api := range apis {
+			var err error
+			if api.ID == 0 {
+				ids[i], err = createAPI(ctx, tx, api, actorID)
+			} else {
+				ids[i], err = api.ID, updateAPI(ctx, tx, api, actorID)
+			}
+			if err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestImportAPIs(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+	db.Exec(`INSERT INTO teams (name) VALUES ('Payments')`)
+
+	ledger, _ := repo.CreateAPI(ctx, models.API{Name: "Ledger", TeamID: 1}, 0)
+
+	t.Run("Writes", func(t *testing.T) {
+		ids, err := repo.ImportAPIs(ctx, []models.API{
+			{Name: "Payouts", TeamID: 1, Tags: []string{"billing"}},
+			{ID: ledger, Name: "Ledger", Version: "2.0.0", TeamID: 1},
+		}, 0)
+		if err != nil {
+			t.Fatalf("Error importing APIs: %v", err)
+		}
+		if len(ids) != 2 || ids[0] == ledger || ids[1] != ledger {
+			t.Fatalf("Unexpected IDs: %v", ids)
+		}
+
+		payouts, _ := repo.GetAPIByID(ctx, ids[0])
+		if !reflect.DeepEqual(payouts.Tags, []string{"billing"}) {
+			t.Errorf("Expected the tags to be imported, got %v", payouts.Tags)
+		}
+		api, _ := repo.GetAPIByID(ctx, ledger)
+		if api.Version != "2.0.0" {
+			t.Errorf("Expected Ledger to be replaced, got %+v", api)
+		}
+		revisions, _ := repo.ListAPIRevisions(ctx, ledger)
+		if len(revisions) != 2 || revisions[0].Action != models.RevisionUpdate {
+			t.Errorf("Expected an update revision, got %+v", revisions)
+		}
+	})
+
+	t.Run("RollsBack", func(t *testing.T) {
+		_, err := repo.ImportAPIs(ctx, []models.API{
+			{Name: "Refunds", TeamID: 1},
+			{ID: ledger, Name: "Ledger", Version: "3.0.0", TeamID: 1},
+			{Name: "Orphan", TeamID: 99},
+		}, 0)
+		var batchErr *BatchError
+		if !errors.As(err, &batchErr) || batchErr.Index != 2 || !errors.Is(err, ErrUnknownTeam) {
+			t.Fatalf("Expected a BatchError for item 2, got %v", err)
+		}
+
+		page, _ := repo.ListAPIs(ctx, models.APIFilter{})
+		if page.Total != 2 {
+			t.Errorf("Expected no API to be created, got %d APIs", page.Total)
+		}
+		api, _ := repo.GetAPIByID(ctx, ledger)
+		if api.Version != "2.0.0" {
+			t.Errorf("Expected the update to be rolled back, got version %q", api.Version)
+		}
+	})
+
+	t.Run("MissingAPI", func(t *testing.T) {
+		_, err := repo.ImportAPIs(ctx, []models.API{{ID: 999, Name: "Ghost"}}, 0)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"microd-api/internal/models"
+	"sort"
+	"strings"
+)
+
+// PatchAPI reads the live API id and passes it to patch, which returns the
+// API as it should be stored, in one transaction. Only the columns that patch
+// changed are written, together with row_version. When nothing changed,
+// nothing is written: updated_at is left alone and no revision is recorded.
+// An error from patch rolls the transaction back and is returned as is.
+func (r *SQLiteAPIRepository) PatchAPI(ctx context.Context, id int64, patch func(current models.API) (models.API, error), actorID int64) (models.API, error) {
+	var patched models.API
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		query := `SELECT ` + apiSelectList("") + ` FROM apis WHERE id = ? AND deleted_at IS NULL`
+		current, err := scanAPI(tx.QueryRowContext(ctx, query, id))
+		if err != nil {
+			return translateError(err)
+		}
+
+		api, err := patch(current)
+		if err != nil {
+			return err
+		}
+		api.ID = id
+
+		names, before := apiWritable(current)
+		_, after := apiWritable(api)
+		var set []string
+		var values []interface{}
+		for i, name := range names {
+			if before[i] != after[i] {
+				set = append(set, name+" = ?")
+				values = append(values, after[i])
+			}
+		}
+		tagsChanged := !sameTags(current.Tags, api.Tags)
+		if len(set) == 0 && !tagsChanged {
+			patched = current
+			return nil
+		}
+
+		set = append(set, "row_version = row_version + 1")
+		query = `UPDATE apis SET ` + strings.Join(set, ", ") + ` WHERE id = ?`
+		if _, err := tx.ExecContext(ctx, query, append(values, id)...); err != nil {
+			return apiWriteError(err)
+		}
+		if tagsChanged {
+			if err := setAPITags(ctx, tx, id, api.Tags); err != nil {
+				return err
+			}
+		}
+		if err := recordRevision(ctx, tx, id, models.RevisionUpdate, actorID); err != nil {
+			return err
+		}
+
+		query = `SELECT ` + apiSelectList("") + ` FROM apis WHERE id = ?`
+		patched, err = scanAPI(tx.QueryRowContext(ctx, query, id))
+		return translateError(err)
+	})
+	if err != nil {
+		return models.API{}, err
+	}
+	return patched, nil
+}
+
+// sameTags reports whether two tag lists name the same tags, ignoring order
+// and case the way setAPITags does.
+func sameTags(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	lower := func(tags []string) []string {
+		result := make([]string, len(tags))
+		for i, tag := range tags {
+			result[i] = strings.ToLower(tag)
+		}
+		sort.Strings(result)
+		return result
+	}
+	x, y := lower(a), lower(b)
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
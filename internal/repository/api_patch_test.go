@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestPatchAPI(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+	db.Exec(`INSERT INTO teams (name) VALUES ('Payments')`)
+
+	id, _ := repo.CreateAPI(ctx, models.API{
+		Name: "Ledger", Version: "1.0.0", Swagger: "openapi: 3.0.0", TeamID: 1, Tags: []string{"billing"},
+	}, 0)
+	// Backdate the row so that a bumped updated_at is visible.
+	db.Exec(`UPDATE apis SET updated_at = '2020-01-01 00:00:00' WHERE id = ?`, id)
+	original, _ := repo.GetAPIByID(ctx, id)
+
+	t.Run("WritesChangedColumns", func(t *testing.T) {
+		var seen models.API
+		patched, err := repo.PatchAPI(ctx, id, func(current models.API) (models.API, error) {
+			seen = current
+			current.Description = "Double-entry books"
+			current.Tags = []string{"billing", "finance"}
+			return current, nil
+		}, 0)
+		if err != nil {
+			t.Fatalf("Error patching API: %v", err)
+		}
+		if !reflect.DeepEqual(seen, original) {
+			t.Errorf("Expected patch to see the stored API %+v, got %+v", original, seen)
+		}
+		if patched.Description != "Double-entry books" || patched.Swagger != "openapi: 3.0.0" || patched.TeamID != 1 {
+			t.Errorf("Unexpected patched API: %+v", patched)
+		}
+		if !reflect.DeepEqual(patched.Tags, []string{"billing", "finance"}) {
+			t.Errorf("Expected the tags to be written, got %v", patched.Tags)
+		}
+		if !patched.UpdatedAt.After(original.UpdatedAt) {
+			t.Errorf("Expected updated_at to be bumped, got %v", patched.UpdatedAt)
+		}
+		revisions, _ := repo.ListAPIRevisions(ctx, id)
+		if len(revisions) != 2 || revisions[0].Action != models.RevisionUpdate {
+			t.Errorf("Expected an update revision, got %+v", revisions)
+		}
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		db.Exec(`UPDATE apis SET updated_at = '2020-01-01 00:00:00' WHERE id = ?`, id)
+		before, _ := repo.GetAPIByID(ctx, id)
+		patched, err := repo.PatchAPI(ctx, id, func(current models.API) (models.API, error) {
+			current.Tags = []string{"Finance", "billing"}
+			return current, nil
+		}, 0)
+		if err != nil {
+			t.Fatalf("Error patching API: %v", err)
+		}
+		if !reflect.DeepEqual(patched, before) {
+			t.Errorf("Expected nothing to be written, got %+v", patched)
+		}
+		if revisions, _ := repo.ListAPIRevisions(ctx, id); len(revisions) != 2 {
+			t.Errorf("Expected no new revision, got %d", len(revisions))
+		}
+	})
+
+	t.Run("RollsBack", func(t *testing.T) {
+		failure := errors.New("rejected")
+		_, err := repo.PatchAPI(ctx, id, func(current models.API) (models.API, error) {
+			return models.API{}, failure
+		}, 0)
+		if err != failure {
+			t.Errorf("Expected the patch error, got %v", err)
+		}
+
+		_, err = repo.PatchAPI(ctx, id, func(current models.API) (models.API, error) {
+			current.Name = "Renamed"
+			current.TeamID = 99
+			return current, nil
+		}, 0)
+		if err != ErrUnknownTeam {
+			t.Errorf("Expected ErrUnknownTeam, got %v", err)
+		}
+		if api, _ := repo.GetAPIByID(ctx, id); api.Name != "Ledger" {
+			t.Errorf("Expected the write to be rolled back, got %+v", api)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo.DeleteAPI(ctx, id, 0, 0)
+		_, err := repo.PatchAPI(ctx, id, func(current models.API) (models.API, error) {
+			t.Error("Expected patch not to be called for a trashed API")
+			return current, nil
+		}, 0)
+		if err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
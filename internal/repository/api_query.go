@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"microd-api/internal/models"
+	"strings"
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidSort   = errors.New("invalid sort field")
+)
+
+const timestampLayout = "2006-01-02 15:04:05"
+
+// sortColumns maps the public sort keys to the expression the catalog is
+// ordered by. Timestamps go through datetime() so that values written by
+// CURRENT_TIMESTAMP and by the driver compare consistently.
+var sortColumns = map[string]string{
+	"name":       "name",
+	"created_at": "datetime(created_at)",
+	"updated_at": "datetime(updated_at)",
+}
+
+type cursor struct {
+	Key string `json:"k"`
+	ID  int64  `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(s string) (cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID <= 0 {
+		return cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// parseSort splits a sort parameter such as "-created_at" into its column
+// expression and direction, defaulting to ascending order by name.
+func parseSort(sort string) (column string, desc bool, ok bool) {
+	if sort == "" {
+		return sortColumns["name"], false, true
+	}
+	desc = strings.HasPrefix(sort, "-")
+	column, ok = sortColumns[strings.TrimPrefix(sort, "-")]
+	return column, desc, ok
+}
+
+func sortKey(api models.API, sort string) string {
+	switch strings.TrimPrefix(sort, "-") {
+	case "created_at":
+		return api.CreatedAt.UTC().Format(timestampLayout)
+	case "updated_at":
+		return api.UpdatedAt.UTC().Format(timestampLayout)
+	default:
+		return api.Name
+	}
+}
+
+// filterClause builds the WHERE clause shared by the page and count queries.
+// APIs in the trash never match.
+func filterClause(filter models.APIFilter) (string, []interface{}) {
+	conditions := []string{`deleted_at IS NULL`}
+	var args []interface{}
+
+	if filter.CategoryID != 0 {
+		conditions = append(conditions, `id IN (SELECT api_id FROM api_category_mappings WHERE category_id = ?)`)
+		args = append(args, filter.CategoryID)
+	}
+	if filter.TeamID != 0 {
+		conditions = append(conditions, `team_id = ?`)
+		args = append(args, filter.TeamID)
+	}
+	if filter.Version != "" {
+		conditions = append(conditions, `version = ?`)
+		args = append(args, filter.Version)
+	}
+	if filter.Lifecycle != "" {
+		conditions = append(conditions, `lifecycle = ?`)
+		args = append(args, filter.Lifecycle)
+	}
+	for _, tag := range filter.Tags {
+		conditions = append(conditions, `id IN (SELECT m.api_id FROM api_tags m JOIN tags t ON t.id = m.tag_id WHERE t.name = ?)`)
+		args = append(args, tag)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
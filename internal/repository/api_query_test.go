@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"testing"
+)
+
+func TestListAPIsPagination(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+	seedTeams(t, db)
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	fixtures := []struct {
+		api       models.API
+		createdAt string
+	}{
+		{models.API{Name: "Delta", TeamID: 1, Version: "1.0.0", Tags: []string{"billing", "public"}}, "2024-01-04 00:00:00"},
+		{models.API{Name: "Alpha", TeamID: 2, Version: "2.0.0", Tags: []string{"auth"}, Lifecycle: models.LifecycleDeprecated}, "2024-01-02 00:00:00"},
+		{models.API{Name: "Charlie", TeamID: 1, Version: "1.0.0", Tags: []string{"billing"}}, "2024-01-01 00:00:00"},
+		{models.API{Name: "Bravo", TeamID: 2, Version: "1.0.0", Tags: []string{"auth", "public"}}, "2024-01-03 00:00:00"},
+		{models.API{Name: "Echo", TeamID: 1, Version: "3.0.0", Tags: []string{"public"}, Lifecycle: models.LifecycleExperimental}, "2024-01-05 00:00:00"},
+	}
+	for _, f := range fixtures {
+		id, err := repo.CreateAPI(ctx, f.api, 0)
+		if err != nil {
+			t.Fatalf("Error creating API: %v", err)
+		}
+		if _, err := db.Exec(`UPDATE apis SET created_at = ? WHERE id = ?`, f.createdAt, id); err != nil {
+			t.Fatalf("Error setting created_at: %v", err)
+		}
+	}
+
+	names := func(apis []models.API) []string {
+		var out []string
+		for _, api := range apis {
+			out = append(out, api.Name)
+		}
+		return out
+	}
+
+	collect := func(t *testing.T, filter models.APIFilter) []string {
+		var all []string
+		for i := 0; i < 10; i++ {
+			page, err := repo.ListAPIs(ctx, filter)
+			if err != nil {
+				t.Fatalf("Error listing APIs: %v", err)
+			}
+			if page.Total != 5 && filter.TeamID == 0 && len(filter.Tags) == 0 {
+				t.Errorf("Expected total 5, got %d", page.Total)
+			}
+			all = append(all, names(page.Items)...)
+			if page.NextCursor == "" {
+				return all
+			}
+			filter.Cursor = page.NextCursor
+		}
+		t.Fatal("Pagination did not terminate")
+		return nil
+	}
+
+	equal := func(a, b []string) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	t.Run("DefaultSortByName", func(t *testing.T) {
+		got := collect(t, models.APIFilter{Limit: 2})
+		want := []string{"Alpha", "Bravo", "Charlie", "Delta", "Echo"}
+		if !equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("SortByNameDescending", func(t *testing.T) {
+		got := collect(t, models.APIFilter{Limit: 2, Sort: "-name"})
+		want := []string{"Echo", "Delta", "Charlie", "Bravo", "Alpha"}
+		if !equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("SortByCreatedAt", func(t *testing.T) {
+		got := collect(t, models.APIFilter{Limit: 3, Sort: "created_at"})
+		want := []string{"Charlie", "Alpha", "Bravo", "Delta", "Echo"}
+		if !equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("SortByCreatedAtDescending", func(t *testing.T) {
+		got := collect(t, models.APIFilter{Limit: 1, Sort: "-created_at"})
+		want := []string{"Echo", "Delta", "Bravo", "Alpha", "Charlie"}
+		if !equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("TiesBrokenByID", func(t *testing.T) {
+		if _, err := db.Exec(`UPDATE apis SET updated_at = '2024-02-01 00:00:00'`); err != nil {
+			t.Fatalf("Error setting updated_at: %v", err)
+		}
+		got := collect(t, models.APIFilter{Limit: 2, Sort: "updated_at"})
+		want := []string{"Delta", "Alpha", "Charlie", "Bravo", "Echo"}
+		if !equal(got, want) {
+			t.Errorf("Expected insertion order for equal timestamps %v, got %v", want, got)
+		}
+	})
+
+	t.Run("FilterByTeamAndVersion", func(t *testing.T) {
+		page, err := repo.ListAPIs(ctx, models.APIFilter{TeamID: 1, Version: "1.0.0"})
+		if err != nil {
+			t.Fatalf("Error listing APIs: %v", err)
+		}
+		if got := names(page.Items); !equal(got, []string{"Charlie", "Delta"}) {
+			t.Errorf("Unexpected APIs for team and version filter: %v", got)
+		}
+		if page.Total != 2 {
+			t.Errorf("Expected total 2, got %d", page.Total)
+		}
+	})
+
+	t.Run("FilterByLifecycle", func(t *testing.T) {
+		page, err := repo.ListAPIs(ctx, models.APIFilter{Lifecycle: models.LifecycleStable, Sort: "name"})
+		if err != nil {
+			t.Fatalf("Error listing APIs: %v", err)
+		}
+		if got := names(page.Items); !equal(got, []string{"Bravo", "Charlie", "Delta"}) {
+			t.Errorf("Unexpected APIs for lifecycle filter: %v", got)
+		}
+	})
+
+	t.Run("FilterByTags", func(t *testing.T) {
+		page, err := repo.ListAPIs(ctx, models.APIFilter{Tags: []string{"public"}})
+		if err != nil {
+			t.Fatalf("Error listing APIs: %v", err)
+		}
+		if got := names(page.Items); !equal(got, []string{"Bravo", "Delta", "Echo"}) {
+			t.Errorf("Unexpected APIs for tag filter: %v", got)
+		}
+
+		page, err = repo.ListAPIs(ctx, models.APIFilter{Tags: []string{"public", "auth"}})
+		if err != nil {
+			t.Fatalf("Error listing APIs: %v", err)
+		}
+		if got := names(page.Items); !equal(got, []string{"Bravo"}) {
+			t.Errorf("Expected every tag to be required, got %v", got)
+		}
+
+		page, err = repo.ListAPIs(ctx, models.APIFilter{Tags: []string{"pub"}})
+		if err != nil {
+			t.Fatalf("Error listing APIs: %v", err)
+		}
+		if len(page.Items) != 0 {
+			t.Errorf("Expected tag filter to match whole tags only, got %v", names(page.Items))
+		}
+	})
+
+	t.Run("InvalidSort", func(t *testing.T) {
+		_, err := repo.ListAPIs(ctx, models.APIFilter{Sort: "description"})
+		if err != ErrInvalidSort {
+			t.Errorf("Expected ErrInvalidSort, got %v", err)
+		}
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		_, err := repo.ListAPIs(ctx, models.APIFilter{Cursor: "not-a-cursor"})
+		if err != ErrInvalidCursor {
+			t.Errorf("Expected ErrInvalidCursor, got %v", err)
+		}
+	})
+}
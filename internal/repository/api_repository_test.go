@@ -4,7 +4,9 @@ import (
 	"context"
 	"database/sql"
 	"microd-api/internal/models"
+	"reflect"
 	"testing"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
@@ -14,6 +16,7 @@ func setupTestDB(t *testing.T) *sql.DB {
 	if err != nil {
 		t.Fatalf("Error opening database: %v", err)
 	}
+	db.SetMaxOpenConns(1)
 
 	_, err = db.Exec(`
 		CREATE TABLE apis (
@@ -24,11 +27,42 @@ func setupTestDB(t *testing.T) *sql.DB {
 			documentation_link TEXT,
 			forum_reference TEXT,
 			apm_link TEXT,
-			team TEXT,
-			tags TEXT,
+			team_id INTEGER,
 			swagger TEXT,
+			lifecycle TEXT NOT NULL DEFAULT 'stable',
+			deprecated_at TIMESTAMP,
+			sunset_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			deleted_at TIMESTAMP,
+			row_version INTEGER NOT NULL DEFAULT 1
+		);
+		CREATE TABLE api_revisions (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			api_id INTEGER NOT NULL,
+			revision INTEGER NOT NULL,
+			action TEXT NOT NULL,
+			actor_id INTEGER,
+			snapshot TEXT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (api_id, revision)
+		);
+		CREATE TABLE teams (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			description TEXT,
 			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE tags (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+		CREATE TABLE api_tags (
+			api_id INTEGER NOT NULL,
+			tag_id INTEGER NOT NULL,
+			PRIMARY KEY (api_id, tag_id)
 		)
 	`)
 	if err != nil {
@@ -53,12 +87,12 @@ func TestAPIRepository(t *testing.T) {
 			DocumentationLink: "http://docs.example.com",
 			ForumReference:    "http://forum.example.com",
 			ApmLink:           "http://apm.example.com",
-			Team:              "Test Team",
-			Tags:              "test,api",
+			TeamID:            1,
+			Tags:              []string{"test", "api"},
 			Swagger:           "http://swagger.example.com",
 		}
 
-		id, err := repo.CreateAPI(ctx, api)
+		id, err := repo.CreateAPI(ctx, api, 0)
 		if err != nil {
 			t.Fatalf("Error creating API: %v", err)
 		}
@@ -86,12 +120,12 @@ func TestAPIRepository(t *testing.T) {
 			DocumentationLink: "http://updated-docs.example.com",
 			ForumReference:    "http://updated-forum.example.com",
 			ApmLink:           "http://updated-apm.example.com",
-			Team:              "Updated Team",
-			Tags:              "updated,api",
+			TeamID:            2,
+			Tags:              []string{"updated", "api"},
 			Swagger:           "http://updated-swagger.example.com",
 		}
 
-		err := repo.UpdateAPI(ctx, api)
+		err := repo.UpdateAPI(ctx, api, 0)
 		if err != nil {
 			t.Fatalf("Error updating API: %v", err)
 		}
@@ -106,27 +140,291 @@ func TestAPIRepository(t *testing.T) {
 	})
 
 	t.Run("ListAPIs", func(t *testing.T) {
-		apis, err := repo.ListAPIs(ctx)
+		page, err := repo.ListAPIs(ctx, models.APIFilter{})
 		if err != nil {
 			t.Fatalf("Error listing APIs: %v", err)
 		}
-		if len(apis) != 1 {
-			t.Errorf("Expected 1 API, got %d", len(apis))
+		if len(page.Items) != 1 {
+			t.Errorf("Expected 1 API, got %d", len(page.Items))
 		}
 	})
 
 	t.Run("DeleteAPI", func(t *testing.T) {
-		err := repo.DeleteAPI(ctx, 1)
+		err := repo.DeleteAPI(ctx, 1, 0, 0)
 		if err != nil {
 			t.Fatalf("Error deleting API: %v", err)
 		}
 
-		apis, err := repo.ListAPIs(ctx)
+		page, err := repo.ListAPIs(ctx, models.APIFilter{})
 		if err != nil {
 			t.Fatalf("Error listing APIs after deletion: %v", err)
 		}
-		if len(apis) != 0 {
-			t.Errorf("Expected 0 APIs after deletion, got %d", len(apis))
+		if len(page.Items) != 0 {
+			t.Errorf("Expected 0 APIs after deletion, got %d", len(page.Items))
 		}
 	})
 }
+
+func TestAPIRepositoryRoundTrip(t *testing.T) {
+	// The migrated schema declares swagger, apm_link and team_id in a
+	// different order than setupTestDB, so both must map columns by name.
+	databases := map[string]func(t *testing.T) *sql.DB{
+		"MigratedSchema": setupMigratedDB,
+		"TestSchema":     setupTestDB,
+	}
+
+	for name, setup := range databases {
+		t.Run(name, func(t *testing.T) {
+			db := setup(t)
+			defer db.Close()
+			seedTeams(t, db)
+
+			repo := NewSQLiteAPIRepository(db)
+			ctx := context.Background()
+
+			want := models.API{
+				Name:              "Orders",
+				Version:           "1.2.3",
+				Description:       "Order management",
+				DocumentationLink: "https://docs.example.com/orders",
+				ForumReference:    "https://forum.example.com/orders",
+				ApmLink:           "https://apm.example.com/orders",
+				TeamID:            3,
+				Tags:              []string{"orders", "public"},
+				Swagger:           `{"openapi":"3.0.0"}`,
+				Lifecycle:         models.LifecycleStable,
+			}
+
+			id, err := repo.CreateAPI(ctx, want, 0)
+			if err != nil {
+				t.Fatalf("Error creating API: %v", err)
+			}
+			want.ID, want.RowVersion = id, 1
+
+			assertAPI := func(t *testing.T, got models.API) {
+				t.Helper()
+				if got.CreatedAt.IsZero() || got.UpdatedAt.IsZero() {
+					t.Errorf("Expected timestamps to be set, got %v and %v", got.CreatedAt, got.UpdatedAt)
+				}
+				got.CreatedAt, got.UpdatedAt = time.Time{}, time.Time{}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Round trip mismatch:\n got  %+v\n want %+v", got, want)
+				}
+			}
+
+			got, err := repo.GetAPIByID(ctx, id)
+			if err != nil {
+				t.Fatalf("Error getting API: %v", err)
+			}
+			assertAPI(t, got)
+
+			want.Tags = []string{"orders"}
+			want.TeamID = 6
+			want.Swagger = `{"openapi":"3.1.0"}`
+			want.ApmLink = ""
+			want.Lifecycle = models.LifecycleDeprecated
+			want.DeprecatedAt = time.Date(2024, 2, 1, 9, 30, 0, 0, time.UTC)
+			want.SunsetAt = time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+			if err := repo.UpdateAPI(ctx, want, 0); err != nil {
+				t.Fatalf("Error updating API: %v", err)
+			}
+			want.RowVersion = 2
+
+			got, err = repo.GetAPIByID(ctx, id)
+			if err != nil {
+				t.Fatalf("Error getting API: %v", err)
+			}
+			assertAPI(t, got)
+
+			page, err := repo.ListAPIs(ctx, models.APIFilter{})
+			if err != nil {
+				t.Fatalf("Error listing APIs: %v", err)
+			}
+			if len(page.Items) != 1 {
+				t.Fatalf("Expected 1 API, got %d", len(page.Items))
+			}
+			assertAPI(t, page.Items[0])
+		})
+	}
+}
+
+func TestAPIRepositoryNullColumns(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	result, err := db.Exec(`INSERT INTO apis (name, created_at, updated_at) VALUES ('Bare', NULL, NULL)`)
+	if err != nil {
+		t.Fatalf("Error inserting API: %v", err)
+	}
+	id, _ := result.LastInsertId()
+
+	got, err := repo.GetAPIByID(ctx, id)
+	if err != nil {
+		t.Fatalf("Expected NULL columns to scan, got %v", err)
+	}
+	want := models.API{ID: id, Name: "Bare", Tags: []string{}, Lifecycle: models.LifecycleStable, RowVersion: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected NULL columns as zero values:\n got  %+v\n want %+v", got, want)
+	}
+
+	page, err := repo.ListAPIs(ctx, models.APIFilter{Sort: "-updated_at"})
+	if err != nil {
+		t.Fatalf("Error listing APIs: %v", err)
+	}
+	if len(page.Items) != 1 || !reflect.DeepEqual(page.Items[0], want) {
+		t.Errorf("Unexpected listing: %+v", page.Items)
+	}
+
+	search, err := repo.SearchAPIs(ctx, "bare", 0)
+	if err != nil {
+		t.Fatalf("Error searching APIs: %v", err)
+	}
+	if len(search.Items) != 1 || !reflect.DeepEqual(search.Items[0].API, want) {
+		t.Errorf("Unexpected search results: %+v", search.Items)
+	}
+}
+
+func TestAPIRepositoryNotFound(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	if _, err := repo.GetAPIByID(ctx, 42); err != ErrNotFound {
+		t.Errorf("GetAPIByID: expected ErrNotFound, got %v", err)
+	}
+	if err := repo.UpdateAPI(ctx, models.API{ID: 42, Name: "Ghost"}, 0); err != ErrNotFound {
+		t.Errorf("UpdateAPI: expected ErrNotFound, got %v", err)
+	}
+	if err := repo.DeleteAPI(ctx, 42, 0, 0); err != ErrNotFound {
+		t.Errorf("DeleteAPI: expected ErrNotFound, got %v", err)
+	}
+
+	id, err := repo.CreateAPI(ctx, models.API{Name: "Orders"}, 0)
+	if err != nil {
+		t.Fatalf("Error creating API: %v", err)
+	}
+	if err := repo.AttachCategory(ctx, id, 42); err != ErrNotFound {
+		t.Errorf("AttachCategory: expected ErrNotFound for unknown category, got %v", err)
+	}
+}
+
+func TestAPIRepositoryRowVersion(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	id, _ := repo.CreateAPI(ctx, models.API{Name: "Orders", Tags: []string{"orders"}}, 0)
+	version := func() int64 {
+		t.Helper()
+		api, err := repo.GetAPIByID(ctx, id)
+		if err != nil {
+			t.Fatalf("Error getting API: %v", err)
+		}
+		return api.RowVersion
+	}
+	if v := version(); v != 1 {
+		t.Fatalf("Expected a new API at version 1, got %d", v)
+	}
+
+	if err := repo.UpdateAPI(ctx, models.API{ID: id, Name: "Orders", Version: "1.0.0", RowVersion: 1}, 0); err != nil {
+		t.Fatalf("Error updating API: %v", err)
+	}
+	if err := repo.UpdateAPI(ctx, models.API{ID: id, Name: "Orders", Version: "2.0.0", RowVersion: 1}, 0); err != ErrVersionMismatch {
+		t.Errorf("Expected ErrVersionMismatch for a stale version, got %v", err)
+	}
+	if err := repo.UpdateAPI(ctx, models.API{ID: id, Name: "Orders", Tags: []string{"orders"}}, 0); err != nil {
+		t.Fatalf("Error updating API without a version: %v", err)
+	}
+	if v := version(); v != 3 {
+		t.Errorf("Expected version 3 after two updates, got %d", v)
+	}
+
+	repo.RenameTag(ctx, "orders", "commerce", 0)
+	if v := version(); v != 4 {
+		t.Errorf("Expected a tag rename to bump the version, got %d", v)
+	}
+	repo.PatchAPI(ctx, id, func(current models.API) (models.API, error) {
+		current.Description = "Order management"
+		return current, nil
+	}, 0)
+	if v := version(); v != 5 {
+		t.Errorf("Expected a patch to bump the version, got %d", v)
+	}
+
+	if err := repo.DeleteAPI(ctx, id, 4, 0); err != ErrVersionMismatch {
+		t.Errorf("Expected ErrVersionMismatch deleting a stale version, got %v", err)
+	}
+	if err := repo.DeleteAPI(ctx, id, 5, 0); err != nil {
+		t.Fatalf("Error deleting API: %v", err)
+	}
+	if err := repo.DeleteAPI(ctx, id, 6, 0); err != ErrNotFound {
+		t.Errorf("Expected ErrNotFound deleting a trashed API, got %v", err)
+	}
+	if err := repo.UndeleteAPI(ctx, id, 0); err != nil {
+		t.Fatalf("Error restoring API: %v", err)
+	}
+	if v := version(); v != 7 {
+		t.Errorf("Expected the delete and restore to bump the version, got %d", v)
+	}
+}
+
+func TestAPIRepositoryUpdatedAt(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	id, _ := repo.CreateAPI(ctx, models.API{Name: "Orders", Tags: []string{"orders"}}, 0)
+	backdate := func() {
+		t.Helper()
+		if _, err := db.Exec(`UPDATE apis SET updated_at = '2020-01-01 00:00:00' WHERE id = ?`, id); err != nil {
+			t.Fatalf("Error backdating API: %v", err)
+		}
+	}
+	updatedAt := func() time.Time {
+		t.Helper()
+		var updated time.Time
+		if err := db.QueryRow(`SELECT updated_at FROM apis WHERE id = ?`, id).Scan(&updated); err != nil {
+			t.Fatalf("Error reading updated_at: %v", err)
+		}
+		return updated
+	}
+	epoch := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	backdate()
+	if got := updatedAt(); !got.Equal(epoch) {
+		t.Fatalf("Expected an explicit updated_at to be kept, got %v", got)
+	}
+
+	writes := []struct {
+		name  string
+		write func() error
+	}{
+		{"UpdateAPI", func() error {
+			return repo.UpdateAPI(ctx, models.API{ID: id, Name: "Orders", Version: "1.0.0", Tags: []string{"orders"}}, 0)
+		}},
+		{"RenameTag", func() error {
+			_, err := repo.RenameTag(ctx, "orders", "commerce", 0)
+			return err
+		}},
+		{"DeleteAPI", func() error { return repo.DeleteAPI(ctx, id, 0, 0) }},
+		{"UndeleteAPI", func() error { return repo.UndeleteAPI(ctx, id, 0) }},
+	}
+	for _, w := range writes {
+		backdate()
+		if err := w.write(); err != nil {
+			t.Fatalf("%s: %v", w.name, err)
+		}
+		if got := updatedAt(); !got.After(epoch) {
+			t.Errorf("%s: expected updated_at to be bumped, got %v", w.name, got)
+		}
+	}
+}
@@ -3,7 +3,12 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"microd-api/internal/models"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
 )
 
 type SQLiteAPIRepository struct {
@@ -14,68 +19,386 @@ func NewSQLiteAPIRepository(db *sql.DB) APIRepository {
 	return &SQLiteAPIRepository{db: db}
 }
 
-func (r *SQLiteAPIRepository) CreateAPI(ctx context.Context, api models.API) (int64, error) {
-	query := `
-		INSERT INTO apis (name, version, description, documentation_link, forum_reference, apm_link, team, tags, swagger)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`
-	result, err := r.db.ExecContext(ctx, query,
-		api.Name, api.Version, api.Description, api.DocumentationLink,
-		api.ForumReference, api.ApmLink, api.Team, api.Tags, api.Swagger)
+// apiColumn maps one column of the apis table to its field in models.API.
+// Every query reads and writes through apiColumns, so the order in which the
+// table happens to declare its columns never matters. A column with an expr is
+// not stored in apis: expr selects it given the name or alias of the apis
+// table, and the repository writes it separately.
+type apiColumn struct {
+	name     string
+	readOnly bool
+	expr     func(table string) string
+	field    func(api *models.API) interface{}
+}
+
+var apiColumns = []apiColumn{
+	{name: "id", readOnly: true, field: func(api *models.API) interface{} { return &api.ID }},
+	{name: "name", field: func(api *models.API) interface{} { return &api.Name }},
+	{name: "version", field: func(api *models.API) interface{} { return &api.Version }},
+	{name: "description", field: func(api *models.API) interface{} { return &api.Description }},
+	{name: "documentation_link", field: func(api *models.API) interface{} { return &api.DocumentationLink }},
+	{name: "forum_reference", field: func(api *models.API) interface{} { return &api.ForumReference }},
+	{name: "tags", expr: tagsExpr, field: func(api *models.API) interface{} { return &api.Tags }},
+	{name: "swagger", field: func(api *models.API) interface{} { return &api.Swagger }},
+	{name: "apm_link", field: func(api *models.API) interface{} { return &api.ApmLink }},
+	{name: "team_id", field: func(api *models.API) interface{} { return &api.TeamID }},
+	{name: "lifecycle", field: func(api *models.API) interface{} { return &api.Lifecycle }},
+	{name: "deprecated_at", field: func(api *models.API) interface{} { return &api.DeprecatedAt }},
+	{name: "sunset_at", field: func(api *models.API) interface{} { return &api.SunsetAt }},
+	{name: "created_at", readOnly: true, field: func(api *models.API) interface{} { return &api.CreatedAt }},
+	{name: "updated_at", readOnly: true, field: func(api *models.API) interface{} { return &api.UpdatedAt }},
+	{name: "row_version", readOnly: true, field: func(api *models.API) interface{} { return &api.RowVersion }},
+}
+
+// apiSelectList returns the column list for a SELECT, qualified with alias
+// when the query joins other tables.
+func apiSelectList(alias string) string {
+	names := make([]string, len(apiColumns))
+	for i, column := range apiColumns {
+		switch {
+		case column.expr != nil && alias != "":
+			names[i] = column.expr(alias)
+		case column.expr != nil:
+			names[i] = column.expr("apis")
+		case alias != "":
+			names[i] = alias + "." + column.name
+		default:
+			names[i] = column.name
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// apiWritable returns the columns a client may set together with their values
+// taken from api. Zero IDs and timestamps are written as NULL, and an API
+// without a lifecycle, such as a snapshot taken before lifecycles existed, is
+// stable.
+func apiWritable(api models.API) ([]string, []interface{}) {
+	if api.Lifecycle == "" {
+		api.Lifecycle = models.LifecycleStable
+	}
+
+	var names []string
+	var values []interface{}
+	for _, column := range apiColumns {
+		if column.readOnly || column.expr != nil {
+			continue
+		}
+		names = append(names, column.name)
+		switch v := column.field(&api).(type) {
+		case *string:
+			values = append(values, *v)
+		case *int64:
+			if *v == 0 {
+				values = append(values, nil)
+			} else {
+				values = append(values, *v)
+			}
+		case *time.Time:
+			if v.IsZero() {
+				values = append(values, nil)
+			} else {
+				values = append(values, v.UTC().Format(timestampLayout))
+			}
+		default:
+			panic(fmt.Sprintf("repository: unsupported type %T for apis.%s", v, column.name))
+		}
+	}
+	return names, values
+}
+
+// scanAPI scans a row selected with apiSelectList. NULL columns become zero
+// values. Any extra destinations are scanned from the
+// columns that follow the API columns.
+func scanAPI(row rowScanner, extra ...interface{}) (models.API, error) {
+	var api models.API
+	dest := make([]interface{}, 0, len(apiColumns)+len(extra))
+	for _, column := range apiColumns {
+		dest = append(dest, nullable(column.field(&api)))
+	}
+	dest = append(dest, extra...)
+
+	err := row.Scan(dest...)
+	return api, err
+}
+
+func nullable(dest interface{}) interface{} {
+	switch v := dest.(type) {
+	case *string:
+		return nullString{v}
+	case *int64:
+		return nullInt64{v}
+	case *time.Time:
+		return nullTime{v}
+	case *[]string:
+		return tagList{v}
+	default:
+		return dest
+	}
+}
+
+type nullString struct{ dest *string }
+
+func (n nullString) Scan(src interface{}) error {
+	var s sql.NullString
+	if err := s.Scan(src); err != nil {
+		return err
+	}
+	*n.dest = s.String
+	return nil
+}
+
+type nullInt64 struct{ dest *int64 }
+
+func (n nullInt64) Scan(src interface{}) error {
+	var i sql.NullInt64
+	if err := i.Scan(src); err != nil {
+		return err
+	}
+	*n.dest = i.Int64
+	return nil
+}
+
+type nullTime struct{ dest *time.Time }
+
+// Scan also accepts timestamps as text, which is how SQLite returns them from
+// expressions such as json_extract where the column type is unknown.
+func (n nullTime) Scan(src interface{}) error {
+	if s, ok := src.(string); ok {
+		for _, format := range sqlite3.SQLiteTimestampFormats {
+			if t, err := time.ParseInLocation(format, s, time.UTC); err == nil {
+				*n.dest = t
+				return nil
+			}
+		}
+	}
+
+	var t sql.NullTime
+	if err := t.Scan(src); err != nil {
+		return err
+	}
+	*n.dest = t.Time
+	return nil
+}
+
+func (r *SQLiteAPIRepository) CreateAPI(ctx context.Context, api models.API, actorID int64) (int64, error) {
+	var id int64
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var err error
+		id, err = createAPI(ctx, tx, api, actorID)
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
-	return result.LastInsertId()
+	return id, nil
+}
+
+// createAPI inserts api with its tags and records the first revision.
+func createAPI(ctx context.Context, tx *sql.Tx, api models.API, actorID int64) (int64, error) {
+	names, values := apiWritable(api)
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+	query := `INSERT INTO apis (` + strings.Join(names, ", ") + `) VALUES (` + placeholders + `)`
+
+	result, err := tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		return 0, apiWriteError(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+	if err := setAPITags(ctx, tx, id, api.Tags); err != nil {
+		return 0, err
+	}
+	return id, recordRevision(ctx, tx, id, models.RevisionCreate, actorID)
+}
+
+// apiWriteError translates a failed insert or update of apis. team_id is the
+// only foreign key of apis, so a violation means the team does not exist.
+func apiWriteError(err error) error {
+	if isForeignKeyViolation(err) {
+		return ErrUnknownTeam
+	}
+	return translateError(err)
 }
 
 func (r *SQLiteAPIRepository) GetAPIByID(ctx context.Context, id int64) (models.API, error) {
-	query := `SELECT * FROM apis WHERE id = ?`
-	var api models.API
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&api.ID, &api.Name, &api.Version, &api.Description, &api.DocumentationLink,
-		&api.ForumReference, &api.ApmLink, &api.Team, &api.Tags, &api.Swagger,
-		&api.CreatedAt, &api.UpdatedAt)
-	return api, err
+	query := `SELECT ` + apiSelectList("") + ` FROM apis WHERE id = ? AND deleted_at IS NULL`
+	api, err := scanAPI(r.db.QueryRowContext(ctx, query, id))
+	return api, translateError(err)
 }
 
-func (r *SQLiteAPIRepository) UpdateAPI(ctx context.Context, api models.API) error {
-	query := `
-		UPDATE apis
-		SET name = ?, version = ?, description = ?, documentation_link = ?,
-			forum_reference = ?, apm_link = ?, team = ?, tags = ?, swagger = ?
-		WHERE id = ?
-	`
-	_, err := r.db.ExecContext(ctx, query,
-		api.Name, api.Version, api.Description, api.DocumentationLink,
-		api.ForumReference, api.ApmLink, api.Team, api.Tags, api.Swagger, api.ID)
-	return err
+func (r *SQLiteAPIRepository) UpdateAPI(ctx context.Context, api models.API, actorID int64) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		return updateAPI(ctx, tx, api, actorID)
+	})
+}
+
+// updateAPI replaces a live API and its tags and records a revision. When
+// api.RowVersion is set the API must still be at that version.
+func updateAPI(ctx context.Context, tx *sql.Tx, api models.API, actorID int64) error {
+	names, values := apiWritable(api)
+	query := `UPDATE apis SET ` + strings.Join(names, " = ?, ") + ` = ?, row_version = row_version + 1
+		WHERE id = ? AND deleted_at IS NULL`
+	values = append(values, api.ID)
+	if api.RowVersion != 0 {
+		query += ` AND row_version = ?`
+		values = append(values, api.RowVersion)
+	}
+	result, err := tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		return apiWriteError(err)
+	}
+	if err := checkVersion(ctx, tx, api.ID, api.RowVersion, result); err != nil {
+		return err
+	}
+	if err := setAPITags(ctx, tx, api.ID, api.Tags); err != nil {
+		return err
+	}
+	return recordRevision(ctx, tx, api.ID, models.RevisionUpdate, actorID)
+}
+
+// checkVersion checks the result of a conditional write to the live API id.
+// When no row was written it tells an API that has moved past version apart
+// from one that does not exist.
+func checkVersion(ctx context.Context, tx *sql.Tx, id, version int64, result sql.Result) error {
+	err := checkAffected(result, nil)
+	if err != ErrNotFound || version == 0 {
+		return err
+	}
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM apis WHERE id = ? AND deleted_at IS NULL)`
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return ErrVersionMismatch
+	}
+	return ErrNotFound
+}
+
+// DeleteAPI moves an API to the trash. It disappears from every other query
+// but keeps its category mappings until PurgeDeletedAPIs removes it. When
+// version is not zero the API must still be at that row version.
+func (r *SQLiteAPIRepository) DeleteAPI(ctx context.Context, id, version, actorID int64) error {
+	query := `UPDATE apis SET deleted_at = CURRENT_TIMESTAMP, row_version = row_version + 1
+		WHERE id = ? AND deleted_at IS NULL`
+	args := []interface{}{id}
+	if version != 0 {
+		query += ` AND row_version = ?`
+		args = append(args, version)
+	}
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if err := checkVersion(ctx, tx, id, version, result); err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, id, models.RevisionDelete, actorID)
+	})
+}
+
+func (r *SQLiteAPIRepository) ListAPIs(ctx context.Context, filter models.APIFilter) (models.APIPage, error) {
+	column, desc, ok := parseSort(filter.Sort)
+	if !ok {
+		return models.APIPage{}, ErrInvalidSort
+	}
+
+	where, args := filterClause(filter)
+
+	var page models.APIPage
+	countQuery := `SELECT COUNT(*) FROM apis` + where
+	if err := r.db.QueryRowContext(ctx, countQuery, args...).Scan(&page.Total); err != nil {
+		return models.APIPage{}, err
+	}
+
+	direction, comparison := "ASC", ">"
+	if desc {
+		direction, comparison = "DESC", "<"
+	}
+
+	query := `SELECT ` + apiSelectList("") + ` FROM apis` + where
+	if filter.Cursor != "" {
+		c, err := decodeCursor(filter.Cursor)
+		if err != nil {
+			return models.APIPage{}, err
+		}
+		keyset := fmt.Sprintf(`(%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?))`, column, comparison)
+		query += ` AND ` + keyset
+		args = append(args, c.Key, c.Key, c.ID)
+	}
+	query += fmt.Sprintf(` ORDER BY %s %s, id %s`, column, direction, direction)
+	if filter.Limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, filter.Limit+1)
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.APIPage{}, err
+	}
+	defer rows.Close()
+
+	page.Items = []models.API{}
+	for rows.Next() {
+		api, err := scanAPI(rows)
+		if err != nil {
+			return models.APIPage{}, err
+		}
+		page.Items = append(page.Items, api)
+	}
+	if err := rows.Err(); err != nil {
+		return models.APIPage{}, err
+	}
+
+	if filter.Limit > 0 && len(page.Items) > filter.Limit {
+		page.Items = page.Items[:filter.Limit]
+		last := page.Items[len(page.Items)-1]
+		page.NextCursor = encodeCursor(cursor{Key: sortKey(last, filter.Sort), ID: last.ID})
+	}
+	return page, nil
+}
+
+func (r *SQLiteAPIRepository) AttachCategory(ctx context.Context, apiID, categoryID int64) error {
+	query := `INSERT OR IGNORE INTO api_category_mappings (api_id, category_id) VALUES (?, ?)`
+	_, err := r.db.ExecContext(ctx, query, apiID, categoryID)
+	if isForeignKeyViolation(err) {
+		return ErrNotFound
+	}
+	return translateError(err)
 }
 
-func (r *SQLiteAPIRepository) DeleteAPI(ctx context.Context, id int64) error {
-	query := `DELETE FROM apis WHERE id = ?`
-	_, err := r.db.ExecContext(ctx, query, id)
+func (r *SQLiteAPIRepository) DetachCategory(ctx context.Context, apiID, categoryID int64) error {
+	query := `DELETE FROM api_category_mappings WHERE api_id = ? AND category_id = ?`
+	_, err := r.db.ExecContext(ctx, query, apiID, categoryID)
 	return err
 }
 
-func (r *SQLiteAPIRepository) ListAPIs(ctx context.Context) ([]models.API, error) {
-	query := `SELECT * FROM apis`
-	rows, err := r.db.QueryContext(ctx, query)
+func (r *SQLiteAPIRepository) ListAPICategories(ctx context.Context, apiID int64) ([]models.APICategory, error) {
+	query := `
+		SELECT c.id, c.name, c.created_at, c.updated_at
+		FROM api_categories c
+		JOIN api_category_mappings m ON m.category_id = c.id
+		WHERE m.api_id = ?
+		ORDER BY c.name
+	`
+	rows, err := r.db.QueryContext(ctx, query, apiID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var apis []models.API
+	var categories []models.APICategory
 	for rows.Next() {
-		var api models.API
-		err := rows.Scan(
-			&api.ID, &api.Name, &api.Version, &api.Description, &api.DocumentationLink,
-			&api.ForumReference, &api.ApmLink, &api.Team, &api.Tags, &api.Swagger,
-			&api.CreatedAt, &api.UpdatedAt)
+		var category models.APICategory
+		err := rows.Scan(&category.ID, &category.Name, &category.CreatedAt, &category.UpdatedAt)
 		if err != nil {
 			return nil, err
 		}
-		apis = append(apis, api)
+		categories = append(categories, category)
 	}
-	return apis, rows.Err()
+	return categories, rows.Err()
 }
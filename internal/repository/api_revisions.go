@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"microd-api/internal/models"
+	"strings"
+)
+
+// snapshotObject builds the JSON snapshot of an apis row, keyed by column.
+func snapshotObject() string {
+	pairs := make([]string, len(apiColumns))
+	for i, column := range apiColumns {
+		if column.expr != nil {
+			pairs[i] = "'" + column.name + "', json(" + column.expr("apis") + ")"
+		} else {
+			pairs[i] = "'" + column.name + "', " + column.name
+		}
+	}
+	return "json_object(" + strings.Join(pairs, ", ") + ")"
+}
+
+// snapshotSelectList reads a snapshot back in the column order scanAPI
+// expects.
+func snapshotSelectList() string {
+	names := make([]string, len(apiColumns))
+	for i, column := range apiColumns {
+		names[i] = "json_extract(snapshot, '$." + column.name + "')"
+	}
+	return strings.Join(names, ", ")
+}
+
+const revisionColumns = `id, api_id, revision, action, actor_id, created_at`
+
+// recordRevision snapshots the current row of apiID as written by actorID,
+// or by nobody in particular when actorID is zero. The caller must run it in
+// the same transaction as the write it records, after the write.
+func recordRevision(ctx context.Context, tx *sql.Tx, apiID int64, action string, actorID int64) error {
+	var actor sql.NullInt64
+	if actorID != 0 {
+		actor = sql.NullInt64{Int64: actorID, Valid: true}
+	}
+
+	query := `
+		INSERT INTO api_revisions (api_id, revision, action, actor_id, snapshot)
+		SELECT id, COALESCE((SELECT MAX(revision) FROM api_revisions WHERE api_id = apis.id), 0) + 1, ?, ?, ` + snapshotObject() + `
+		FROM apis WHERE id = ?
+	`
+	_, err := tx.ExecContext(ctx, query, action, actor, apiID)
+	return translateError(err)
+}
+
+func scanRevision(row rowScanner) (models.APIRevision, error) {
+	var rev models.APIRevision
+	var actor sql.NullInt64
+	snapshot, err := scanAPI(row, &rev.ID, &rev.APIID, &rev.Revision, &rev.Action, &actor, nullable(&rev.CreatedAt))
+	if err != nil {
+		return models.APIRevision{}, err
+	}
+	rev.Snapshot = snapshot
+	rev.ActorID = actor.Int64
+	return rev, nil
+}
+
+// ListAPIRevisions returns the history of an API, newest first. The history
+// of a deleted API is kept, so that it can still be restored.
+func (r *SQLiteAPIRepository) ListAPIRevisions(ctx context.Context, apiID int64) ([]models.APIRevision, error) {
+	query := `SELECT ` + snapshotSelectList() + `, ` + revisionColumns + `
+		FROM api_revisions WHERE api_id = ? ORDER BY revision DESC`
+	rows, err := r.db.QueryContext(ctx, query, apiID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.APIRevision{}
+	for rows.Next() {
+		rev, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, ErrNotFound
+	}
+	return revisions, nil
+}
+
+func (r *SQLiteAPIRepository) GetAPIRevision(ctx context.Context, apiID, revision int64) (models.APIRevision, error) {
+	return getRevision(ctx, r.db, apiID, revision)
+}
+
+// rowQuerier is implemented by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func getRevision(ctx context.Context, q rowQuerier, apiID, revision int64) (models.APIRevision, error) {
+	query := `SELECT ` + snapshotSelectList() + `, ` + revisionColumns + `
+		FROM api_revisions WHERE api_id = ? AND revision = ?`
+	rev, err := scanRevision(q.QueryRowContext(ctx, query, apiID, revision))
+	return rev, translateError(err)
+}
+
+// RestoreAPIRevision writes the snapshot of a revision back to the API, taking
+// it out of the trash or recreating it under its original ID when it has been
+// purged, and records the restore as a new revision.
+func (r *SQLiteAPIRepository) RestoreAPIRevision(ctx context.Context, apiID, revision, actorID int64) (models.API, error) {
+	var restored models.API
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		rev, err := getRevision(ctx, tx, apiID, revision)
+		if err != nil {
+			return err
+		}
+
+		names, values := apiWritable(rev.Snapshot)
+		update := `UPDATE apis SET ` + strings.Join(names, " = ?, ") + ` = ?, deleted_at = NULL, row_version = row_version + 1 WHERE id = ?`
+		result, err := tx.ExecContext(ctx, update, append(values, apiID)...)
+		if err != nil {
+			return apiWriteError(err)
+		}
+		err = checkAffected(result, nil)
+		if err == ErrNotFound {
+			var createdAt interface{}
+			if !rev.Snapshot.CreatedAt.IsZero() {
+				createdAt = rev.Snapshot.CreatedAt
+			}
+			names = append([]string{"id", "created_at"}, names...)
+			values = append([]interface{}{apiID, createdAt}, values...)
+			placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+			insert := `INSERT INTO apis (` + strings.Join(names, ", ") + `) VALUES (` + placeholders + `)`
+			_, err = tx.ExecContext(ctx, insert, values...)
+			err = apiWriteError(err)
+		}
+		if err != nil {
+			return err
+		}
+		if err := setAPITags(ctx, tx, apiID, rev.Snapshot.Tags); err != nil {
+			return err
+		}
+
+		if err := recordRevision(ctx, tx, apiID, models.RevisionRestore, actorID); err != nil {
+			return err
+		}
+
+		query := `SELECT ` + apiSelectList("") + ` FROM apis WHERE id = ?`
+		restored, err = scanAPI(tx.QueryRowContext(ctx, query, apiID))
+		return translateError(err)
+	})
+	return restored, err
+}
+
+// withTx runs fn in a transaction that is committed when fn succeeds and
+// rolled back otherwise.
+func (r *SQLiteAPIRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAPIRevisions(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+	seedTeams(t, db)
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	original := models.API{Name: "Orders", Version: "1.0.0", TeamID: 3, Tags: []string{"orders"}}
+	id, err := repo.CreateAPI(ctx, original, 7)
+	if err != nil {
+		t.Fatalf("Error creating API: %v", err)
+	}
+	created, _ := repo.GetAPIByID(ctx, id)
+
+	updated := created
+	updated.Version = "2.0.0"
+	updated.Description = "Second edition"
+	if err := repo.UpdateAPI(ctx, updated, 0); err != nil {
+		t.Fatalf("Error updating API: %v", err)
+	}
+	if err := repo.DeleteAPI(ctx, id, 0, 7); err != nil {
+		t.Fatalf("Error deleting API: %v", err)
+	}
+
+	t.Run("List", func(t *testing.T) {
+		revisions, err := repo.ListAPIRevisions(ctx, id)
+		if err != nil {
+			t.Fatalf("Error listing revisions: %v", err)
+		}
+		if len(revisions) != 3 {
+			t.Fatalf("Expected 3 revisions, got %d", len(revisions))
+		}
+
+		want := []struct {
+			revision int64
+			action   string
+			actor    int64
+			version  string
+		}{
+			{3, models.RevisionDelete, 7, "2.0.0"},
+			{2, models.RevisionUpdate, 0, "2.0.0"},
+			{1, models.RevisionCreate, 7, "1.0.0"},
+		}
+		for i, w := range want {
+			rev := revisions[i]
+			if rev.APIID != id || rev.Revision != w.revision || rev.Action != w.action || rev.ActorID != w.actor || rev.Snapshot.Version != w.version {
+				t.Errorf("Revision %d: unexpected %+v", i, rev)
+			}
+			if rev.CreatedAt.IsZero() {
+				t.Errorf("Revision %d has no timestamp", i)
+			}
+		}
+	})
+
+	t.Run("SnapshotMatchesRow", func(t *testing.T) {
+		rev, err := repo.GetAPIRevision(ctx, id, 1)
+		if err != nil {
+			t.Fatalf("Error getting revision: %v", err)
+		}
+		if !rev.Snapshot.CreatedAt.Equal(created.CreatedAt) {
+			t.Errorf("Expected snapshot timestamp %v, got %v", created.CreatedAt, rev.Snapshot.CreatedAt)
+		}
+		rev.Snapshot.CreatedAt, rev.Snapshot.UpdatedAt = time.Time{}, time.Time{}
+		want := created
+		want.CreatedAt, want.UpdatedAt = time.Time{}, time.Time{}
+		if !reflect.DeepEqual(rev.Snapshot, want) {
+			t.Errorf("Snapshot mismatch:\n got  %+v\n want %+v", rev.Snapshot, want)
+		}
+	})
+
+	t.Run("RestoreDeleted", func(t *testing.T) {
+		restored, err := repo.RestoreAPIRevision(ctx, id, 2, 0)
+		if err != nil {
+			t.Fatalf("Error restoring revision: %v", err)
+		}
+		if restored.ID != id || restored.Description != "Second edition" || !restored.CreatedAt.Equal(created.CreatedAt) {
+			t.Errorf("Unexpected restored API: %+v", restored)
+		}
+
+		results, err := repo.SearchAPIs(ctx, "orders", 0)
+		if err != nil || results.Total != 1 {
+			t.Errorf("Expected restored API to be searchable, got %+v, %v", results, err)
+		}
+	})
+
+	t.Run("RestoreExisting", func(t *testing.T) {
+		restored, err := repo.RestoreAPIRevision(ctx, id, 1, 0)
+		if err != nil {
+			t.Fatalf("Error restoring revision: %v", err)
+		}
+		if restored.Version != "1.0.0" || restored.Description != "" {
+			t.Errorf("Unexpected restored API: %+v", restored)
+		}
+
+		revisions, _ := repo.ListAPIRevisions(ctx, id)
+		if len(revisions) != 5 || revisions[0].Action != models.RevisionRestore || revisions[0].Snapshot.Version != "1.0.0" {
+			t.Errorf("Expected restore to be recorded, got %+v", revisions[0])
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		if _, err := repo.ListAPIRevisions(ctx, 42); err != ErrNotFound {
+			t.Errorf("ListAPIRevisions: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.GetAPIRevision(ctx, id, 42); err != ErrNotFound {
+			t.Errorf("GetAPIRevision: expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.RestoreAPIRevision(ctx, id, 42, 0); err != ErrNotFound {
+			t.Errorf("RestoreAPIRevision: expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("FailedWriteRecordsNothing", func(t *testing.T) {
+		if err := repo.UpdateAPI(ctx, models.API{ID: 42, Name: "Ghost"}, 0); err != ErrNotFound {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+		if err := repo.DeleteAPI(ctx, 42, 0, 0); err != ErrNotFound {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.ListAPIRevisions(ctx, 42); err != ErrNotFound {
+			t.Errorf("Expected no revisions for a missing API, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"microd-api/internal/models"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var ErrInvalidSearchQuery = errors.New("search query contains no searchable terms")
+
+// searchWeights boosts matches in apis_fts columns, in declaration order:
+// name, description, tags, team.
+var searchWeights = []float64{10, 1, 5, 3}
+
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// SearchAPIs runs q against the apis_fts index and returns matches ordered by
+// relevance, best first. Only the first limit results are returned when limit
+// is positive, while Total counts every match.
+//
+// FTS4 has no ranking function, so every match is ranked from its matchinfo
+// first, and the rows and snippets are read for the returned page only.
+func (r *SQLiteAPIRepository) SearchAPIs(ctx context.Context, q string, limit int) (models.APISearchPage, error) {
+	match, err := buildMatchQuery(q)
+	if err != nil {
+		return models.APISearchPage{}, err
+	}
+
+	ranked, err := r.rankSearch(ctx, match)
+	if err != nil {
+		return models.APISearchPage{}, err
+	}
+	page := models.APISearchPage{Items: []models.APISearchResult{}, Total: int64(len(ranked))}
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+	if len(ranked) == 0 {
+		return page, nil
+	}
+
+	args := []interface{}{match}
+	for _, result := range ranked {
+		args = append(args, result.API.ID)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(ranked)), ", ")
+	query := `
+		SELECT ` + apiSelectList("a") + `,
+			snippet(apis_fts, '<mark>', '</mark>', '…', -1, 12)
+		FROM apis_fts
+		JOIN apis a ON a.id = apis_fts.docid
+		WHERE apis_fts MATCH ? AND apis_fts.docid IN (` + placeholders + `)
+	`
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.APISearchPage{}, err
+	}
+	defer rows.Close()
+
+	found := make(map[int64]models.APISearchResult, len(ranked))
+	for rows.Next() {
+		var result models.APISearchResult
+		result.API, err = scanAPI(rows, &result.Snippet)
+		if err != nil {
+			return models.APISearchPage{}, err
+		}
+		found[result.API.ID] = result
+	}
+	if err := rows.Err(); err != nil {
+		return models.APISearchPage{}, err
+	}
+
+	for _, result := range ranked {
+		if row, ok := found[result.API.ID]; ok {
+			row.Score = result.Score
+			page.Items = append(page.Items, row)
+		}
+	}
+	return page, nil
+}
+
+// rankSearch returns the IDs and scores of the live APIs matching match, best
+// first.
+func (r *SQLiteAPIRepository) rankSearch(ctx context.Context, match string) ([]models.APISearchResult, error) {
+	query := `
+		SELECT a.id, matchinfo(apis_fts, 'pcnalx')
+		FROM apis_fts
+		JOIN apis a ON a.id = apis_fts.docid
+		WHERE apis_fts MATCH ? AND a.deleted_at IS NULL
+	`
+	rows, err := r.db.QueryContext(ctx, query, match)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ranked []models.APISearchResult
+	for rows.Next() {
+		var result models.APISearchResult
+		var info []byte
+		if err := rows.Scan(&result.API.ID, &info); err != nil {
+			return nil, err
+		}
+		result.Score = bm25(info, searchWeights)
+		ranked = append(ranked, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].API.ID < ranked[j].API.ID
+	})
+	return ranked, nil
+}
+
+// buildMatchQuery turns free text into an FTS MATCH expression. Every word
+// becomes a prefix term and all of them must match. Punctuation is dropped and
+// words are lowercased so that user input can never form FTS operators such as
+// OR, NEAR or a quoted phrase.
+func buildMatchQuery(q string) (string, error) {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "", ErrInvalidSearchQuery
+	}
+
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = word + "*"
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// bm25 scores a row from its matchinfo 'pcnalx' blob. FTS4 has no built-in
+// ranking function, so this follows the Okapi BM25 formula with a weight per
+// column, as suggested in the SQLite FTS4 documentation.
+func bm25(info []byte, weights []float64) float64 {
+	values := make([]uint32, len(info)/4)
+	for i := range values {
+		values[i] = binary.NativeEndian.Uint32(info[i*4:])
+	}
+	if len(values) < 3 {
+		return 0
+	}
+
+	phrases, columns, docs := int(values[0]), int(values[1]), float64(values[2])
+	if len(values) < 3+2*columns+3*phrases*columns {
+		return 0
+	}
+	avgLengths := values[3 : 3+columns]
+	lengths := values[3+columns : 3+2*columns]
+	hits := values[3+2*columns:]
+
+	var score float64
+	for p := 0; p < phrases; p++ {
+		for c := 0; c < columns && c < len(weights); c++ {
+			x := hits[3*(p*columns+c):]
+			tf, docsWithHits := float64(x[0]), float64(x[2])
+			if tf == 0 {
+				continue
+			}
+
+			idf := math.Log((docs - docsWithHits + 0.5) / (docsWithHits + 0.5))
+			if idf < 1e-6 {
+				idf = 1e-6
+			}
+
+			norm := 1.0
+			if avgLengths[c] > 0 {
+				norm = 1 - bm25B + bm25B*float64(lengths[c])/float64(avgLengths[c])
+			}
+			score += weights[c] * idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+		}
+	}
+	return score
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"strings"
+	"testing"
+)
+
+func TestSearchAPIs(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+	seedTeams(t, db)
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	ledgerID, err := repo.CreateAPI(ctx, models.API{
+		Name: "Ledger", Description: "Double-entry bookkeeping for payments", TeamID: 4, Tags: []string{"accounting"},
+	}, 0)
+	if err != nil {
+		t.Fatalf("Error creating API: %v", err)
+	}
+	paymentsID, err := repo.CreateAPI(ctx, models.API{
+		Name: "Payments Gateway", Description: "Card processing", TeamID: 5, Tags: []string{"billing", "public"},
+	}, 0)
+	if err != nil {
+		t.Fatalf("Error creating API: %v", err)
+	}
+	if _, err := repo.CreateAPI(ctx, models.API{
+		Name: "Login", Description: "Session management", TeamID: 2, Tags: []string{"auth"},
+	}, 0); err != nil {
+		t.Fatalf("Error creating API: %v", err)
+	}
+
+	ids := func(page models.APISearchPage) []int64 {
+		var out []int64
+		for _, item := range page.Items {
+			out = append(out, item.API.ID)
+		}
+		return out
+	}
+
+	t.Run("RanksNameMatchesFirst", func(t *testing.T) {
+		page, err := repo.SearchAPIs(ctx, "payments", 0)
+		if err != nil {
+			t.Fatalf("Error searching APIs: %v", err)
+		}
+		if page.Total != 2 || len(page.Items) != 2 {
+			t.Fatalf("Expected 2 results, got %v", ids(page))
+		}
+		if page.Items[0].API.ID != paymentsID || page.Items[1].API.ID != ledgerID {
+			t.Errorf("Expected name match to outrank description match, got %v", ids(page))
+		}
+		if page.Items[0].Score <= page.Items[1].Score {
+			t.Errorf("Expected descending scores, got %v and %v", page.Items[0].Score, page.Items[1].Score)
+		}
+		if page.Items[0].API.Name != "Payments Gateway" || page.Items[0].API.TeamID != 5 {
+			t.Errorf("Unexpected API in result: %+v", page.Items[0].API)
+		}
+	})
+
+	t.Run("HighlightsSnippet", func(t *testing.T) {
+		page, err := repo.SearchAPIs(ctx, "bookkeeping", 0)
+		if err != nil {
+			t.Fatalf("Error searching APIs: %v", err)
+		}
+		if len(page.Items) != 1 {
+			t.Fatalf("Expected 1 result, got %v", ids(page))
+		}
+		if !strings.Contains(page.Items[0].Snippet, "<mark>bookkeeping</mark>") {
+			t.Errorf("Expected highlighted snippet, got %q", page.Items[0].Snippet)
+		}
+	})
+
+	t.Run("PrefixAndAllTerms", func(t *testing.T) {
+		page, err := repo.SearchAPIs(ctx, "pay gate", 0)
+		if err != nil {
+			t.Fatalf("Error searching APIs: %v", err)
+		}
+		if got := ids(page); len(got) != 1 || got[0] != paymentsID {
+			t.Errorf("Expected only the gateway to match every prefix, got %v", got)
+		}
+	})
+
+	t.Run("TagsAndTeam", func(t *testing.T) {
+		page, err := repo.SearchAPIs(ctx, "public", 0)
+		if err != nil || len(page.Items) != 1 {
+			t.Errorf("Expected tag match, got %v (%v)", ids(page), err)
+		}
+		page, err = repo.SearchAPIs(ctx, "identity", 0)
+		if err != nil || len(page.Items) != 1 {
+			t.Errorf("Expected team match, got %v (%v)", ids(page), err)
+		}
+	})
+
+	t.Run("Limit", func(t *testing.T) {
+		page, err := repo.SearchAPIs(ctx, "payments", 1)
+		if err != nil {
+			t.Fatalf("Error searching APIs: %v", err)
+		}
+		if len(page.Items) != 1 || page.Total != 2 {
+			t.Errorf("Expected 1 of 2 results, got %d of %d", len(page.Items), page.Total)
+		}
+	})
+
+	t.Run("SyntaxIsEscaped", func(t *testing.T) {
+		if _, err := repo.SearchAPIs(ctx, `"payments* OR (`, 0); err != nil {
+			t.Errorf("Expected FTS operators to be treated as text, got %v", err)
+		}
+		if _, err := repo.SearchAPIs(ctx, `*()"`, 0); err != ErrInvalidSearchQuery {
+			t.Errorf("Expected ErrInvalidSearchQuery, got %v", err)
+		}
+	})
+
+	t.Run("TriggersKeepIndexInSync", func(t *testing.T) {
+		api, err := repo.GetAPIByID(ctx, ledgerID)
+		if err != nil {
+			t.Fatalf("Error getting API: %v", err)
+		}
+		api.Name = "General Ledger"
+		api.Description = "Journal entries"
+		if err := repo.UpdateAPI(ctx, api, 0); err != nil {
+			t.Fatalf("Error updating API: %v", err)
+		}
+
+		page, _ := repo.SearchAPIs(ctx, "bookkeeping", 0)
+		if len(page.Items) != 0 {
+			t.Errorf("Expected stale text to be removed from the index, got %v", ids(page))
+		}
+		page, _ = repo.SearchAPIs(ctx, "journal", 0)
+		if got := ids(page); len(got) != 1 || got[0] != ledgerID {
+			t.Errorf("Expected updated text to be indexed, got %v", got)
+		}
+
+		if err := repo.DeleteAPI(ctx, paymentsID, 0, 0); err != nil {
+			t.Fatalf("Error deleting API: %v", err)
+		}
+		page, _ = repo.SearchAPIs(ctx, "gateway", 0)
+		if len(page.Items) != 0 {
+			t.Errorf("Expected deleted API to be removed from the index, got %v", ids(page))
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"microd-api/internal/models"
+	"strings"
+)
+
+// tagsExpr selects the tags of the API in table as a JSON array, sorted by
+// name.
+func tagsExpr(table string) string {
+	return `(SELECT json_group_array(name) FROM (
+		SELECT t.name FROM api_tags m JOIN tags t ON t.id = m.tag_id
+		WHERE m.api_id = ` + table + `.id ORDER BY t.name))`
+}
+
+// tagList scans the JSON array selected by tagsExpr. Revisions recorded
+// before tags had their own table hold a comma-separated string instead,
+// which is split the way the old column was.
+type tagList struct{ dest *[]string }
+
+func (l tagList) Scan(src interface{}) error {
+	var s sql.NullString
+	if err := s.Scan(src); err != nil {
+		return err
+	}
+
+	tags := []string{}
+	if strings.HasPrefix(s.String, "[") {
+		if err := json.Unmarshal([]byte(s.String), &tags); err != nil {
+			return err
+		}
+	} else {
+		for _, tag := range strings.Split(s.String, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	*l.dest = tags
+	return nil
+}
+
+// setAPITags replaces the tags of an API, creating tags that do not exist
+// yet. A tag that differs from an existing one only in case is the existing
+// tag.
+func setAPITags(ctx context.Context, tx *sql.Tx, apiID int64, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM api_tags WHERE api_id = ?`, apiID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO tags (name) VALUES (?)`, tag); err != nil {
+			return err
+		}
+		query := `INSERT OR IGNORE INTO api_tags (api_id, tag_id) SELECT ?, id FROM tags WHERE name = ?`
+		if _, err := tx.ExecContext(ctx, query, apiID, tag); err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+// ListTags returns the tags used by live APIs with the number of APIs using
+// each, most used first.
+func (r *SQLiteAPIRepository) ListTags(ctx context.Context) ([]models.Tag, error) {
+	query := `
+		SELECT t.name, COUNT(*) FROM tags t
+		JOIN api_tags m ON m.tag_id = t.id
+		JOIN apis a ON a.id = m.api_id AND a.deleted_at IS NULL
+		GROUP BY t.id
+		ORDER BY COUNT(*) DESC, t.name
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		var tag models.Tag
+		if err := rows.Scan(&tag.Name, &tag.APICount); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+// RenameTag renames a tag on every API that uses it and returns the IDs of
+// those APIs. Renaming onto another existing tag is a conflict; MergeTags
+// combines two tags.
+func (r *SQLiteAPIRepository) RenameTag(ctx context.Context, name, newName string, actorID int64) ([]int64, error) {
+	var affected []int64
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		id, err := tagID(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `UPDATE tags SET name = ? WHERE id = ?`, newName, id); err != nil {
+			return translateError(err)
+		}
+		affected, err = taggedAPIs(ctx, tx, id)
+		if err != nil {
+			return err
+		}
+		return recordRevisions(ctx, tx, affected, models.RevisionUpdate, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+// MergeTags moves every API tagged name onto the tag into, removes name and
+// returns the IDs of the APIs it moved.
+func (r *SQLiteAPIRepository) MergeTags(ctx context.Context, name, into string, actorID int64) ([]int64, error) {
+	var affected []int64
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		from, err := tagID(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		to, err := tagID(ctx, tx, into)
+		if err != nil {
+			return err
+		}
+		if from == to {
+			return nil
+		}
+		affected, err = taggedAPIs(ctx, tx, from)
+		if err != nil {
+			return err
+		}
+
+		query := `INSERT OR IGNORE INTO api_tags (api_id, tag_id) SELECT api_id, ? FROM api_tags WHERE tag_id = ?`
+		if _, err := tx.ExecContext(ctx, query, to, from); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id = ?`, from); err != nil {
+			return err
+		}
+		return recordRevisions(ctx, tx, affected, models.RevisionUpdate, actorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return affected, nil
+}
+
+func tagID(ctx context.Context, tx *sql.Tx, name string) (int64, error) {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM tags WHERE name = ?`, name).Scan(&id)
+	return id, translateError(err)
+}
+
+// taggedAPIs returns the live APIs tagged tagID, whose revisions and row
+// versions change when the tag does.
+func taggedAPIs(ctx context.Context, tx *sql.Tx, tagID int64) ([]int64, error) {
+	query := `SELECT m.api_id FROM api_tags m JOIN apis a ON a.id = m.api_id
+		WHERE m.tag_id = ? AND a.deleted_at IS NULL ORDER BY m.api_id`
+	rows, err := tx.QueryContext(ctx, query, tagID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// recordRevisions bumps the row version of every API in ids and records a
+// revision of each.
+func recordRevisions(ctx context.Context, tx *sql.Tx, ids []int64, action string, actorID int64) error {
+	for _, id := range ids {
+		if _, err := tx.ExecContext(ctx, `UPDATE apis SET row_version = row_version + 1 WHERE id = ?`, id); err != nil {
+			return err
+		}
+		if err := recordRevision(ctx, tx, id, action, actorID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
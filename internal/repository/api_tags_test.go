@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/models"
+	"reflect"
+	"testing"
+)
+
+func TestAPITags(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	orders, _ := repo.CreateAPI(ctx, models.API{Name: "Orders", Tags: []string{"public", "orders"}}, 0)
+	billing, _ := repo.CreateAPI(ctx, models.API{Name: "Billing", Tags: []string{"Public", "payments"}}, 0)
+	trashed, _ := repo.CreateAPI(ctx, models.API{Name: "Legacy", Tags: []string{"orders"}}, 0)
+	repo.DeleteAPI(ctx, trashed, 0, 0)
+
+	t.Run("SharedCaseInsensitively", func(t *testing.T) {
+		api, err := repo.GetAPIByID(ctx, billing)
+		if err != nil {
+			t.Fatalf("Error getting API: %v", err)
+		}
+		if want := []string{"payments", "public"}; !reflect.DeepEqual(api.Tags, want) {
+			t.Errorf("Expected tags %v, got %v", want, api.Tags)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		tags, err := repo.ListTags(ctx)
+		if err != nil {
+			t.Fatalf("Error listing tags: %v", err)
+		}
+		want := []models.Tag{{Name: "public", APICount: 2}, {Name: "orders", APICount: 1}, {Name: "payments", APICount: 1}}
+		if !reflect.DeepEqual(tags, want) {
+			t.Errorf("Expected tags %+v, got %+v", want, tags)
+		}
+	})
+
+	t.Run("Rename", func(t *testing.T) {
+		if _, err := repo.RenameTag(ctx, "missing", "other", 0); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if _, err := repo.RenameTag(ctx, "orders", "Payments", 0); !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict renaming onto an existing tag, got %v", err)
+		}
+		affected, err := repo.RenameTag(ctx, "public", "external", 0)
+		if err != nil {
+			t.Fatalf("Error renaming tag: %v", err)
+		}
+		if want := []int64{orders, billing}; !reflect.DeepEqual(affected, want) {
+			t.Errorf("Expected the rename to affect %v, got %v", want, affected)
+		}
+
+		api, _ := repo.GetAPIByID(ctx, orders)
+		if want := []string{"external", "orders"}; !reflect.DeepEqual(api.Tags, want) {
+			t.Errorf("Expected tags %v, got %v", want, api.Tags)
+		}
+		page, _ := repo.ListAPIs(ctx, models.APIFilter{Tags: []string{"External"}})
+		if page.Total != 2 {
+			t.Errorf("Expected 2 APIs filtered by the renamed tag, got %+v", page)
+		}
+		results, _ := repo.SearchAPIs(ctx, "external", 0)
+		if results.Total != 2 {
+			t.Errorf("Expected the renamed tag to be searchable, got %+v", results)
+		}
+		if results, _ := repo.SearchAPIs(ctx, "public", 0); results.Total != 0 {
+			t.Errorf("Expected the old tag to be gone from the search index, got %+v", results)
+		}
+
+		revisions, _ := repo.ListAPIRevisions(ctx, billing)
+		if len(revisions) != 2 || revisions[0].Action != models.RevisionUpdate {
+			t.Errorf("Expected the rename to be recorded as an update, got %+v", revisions)
+		}
+	})
+
+	t.Run("Merge", func(t *testing.T) {
+		if _, err := repo.MergeTags(ctx, "payments", "missing", 0); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		affected, err := repo.MergeTags(ctx, "orders", "external", 0)
+		if err != nil {
+			t.Fatalf("Error merging tags: %v", err)
+		}
+		if want := []int64{orders}; !reflect.DeepEqual(affected, want) {
+			t.Errorf("Expected the merge to affect %v, got %v", want, affected)
+		}
+
+		api, _ := repo.GetAPIByID(ctx, orders)
+		if want := []string{"external"}; !reflect.DeepEqual(api.Tags, want) {
+			t.Errorf("Expected tags %v, got %v", want, api.Tags)
+		}
+		tags, _ := repo.ListTags(ctx)
+		want := []models.Tag{{Name: "external", APICount: 2}, {Name: "payments", APICount: 1}}
+		if !reflect.DeepEqual(tags, want) {
+			t.Errorf("Expected tags %+v, got %+v", want, tags)
+		}
+		if results, _ := repo.SearchAPIs(ctx, "orders", 0); results.Total != 1 || results.Items[0].API.ID != orders {
+			t.Errorf("Expected only the API name to match after the merge, got %+v", results)
+		}
+	})
+
+	t.Run("RestoreRevision", func(t *testing.T) {
+		restored, err := repo.RestoreAPIRevision(ctx, orders, 1, 0)
+		if err != nil {
+			t.Fatalf("Error restoring revision: %v", err)
+		}
+		if want := []string{"orders", "public"}; !reflect.DeepEqual(restored.Tags, want) {
+			t.Errorf("Expected restored tags %v, got %v", want, restored.Tags)
+		}
+	})
+
+	t.Run("LegacySnapshot", func(t *testing.T) {
+		_, err := db.Exec(`UPDATE api_revisions SET snapshot = json_set(snapshot, '$.tags', ' beta, Orders ,') WHERE api_id = ? AND revision = 1`, billing)
+		if err != nil {
+			t.Fatalf("Error rewriting snapshot: %v", err)
+		}
+		rev, err := repo.GetAPIRevision(ctx, billing, 1)
+		if err != nil {
+			t.Fatalf("Error getting revision: %v", err)
+		}
+		if want := []string{"beta", "Orders"}; !reflect.DeepEqual(rev.Snapshot.Tags, want) {
+			t.Errorf("Expected comma-separated tags to be split, got %v", rev.Snapshot.Tags)
+		}
+	})
+}
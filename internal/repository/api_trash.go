@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"microd-api/internal/models"
+	"time"
+)
+
+// ListDeletedAPIs returns the APIs in the trash, most recently deleted first.
+// PurgeAt is left for the caller, which knows the retention period.
+func (r *SQLiteAPIRepository) ListDeletedAPIs(ctx context.Context) ([]models.DeletedAPI, error) {
+	query := `SELECT ` + apiSelectList("") + `, deleted_at FROM apis
+		WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id DESC`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deleted := []models.DeletedAPI{}
+	for rows.Next() {
+		var d models.DeletedAPI
+		if d.API, err = scanAPI(rows, nullable(&d.DeletedAt)); err != nil {
+			return nil, err
+		}
+		deleted = append(deleted, d)
+	}
+	return deleted, rows.Err()
+}
+
+func (r *SQLiteAPIRepository) GetDeletedAPI(ctx context.Context, id int64) (models.DeletedAPI, error) {
+	query := `SELECT ` + apiSelectList("") + `, deleted_at FROM apis WHERE id = ? AND deleted_at IS NOT NULL`
+	var d models.DeletedAPI
+	var err error
+	d.API, err = scanAPI(r.db.QueryRowContext(ctx, query, id), nullable(&d.DeletedAt))
+	return d, translateError(err)
+}
+
+// UndeleteAPI takes an API out of the trash and records the restore as a new
+// revision.
+func (r *SQLiteAPIRepository) UndeleteAPI(ctx context.Context, id, actorID int64) error {
+	query := `UPDATE apis SET deleted_at = NULL, row_version = row_version + 1 WHERE id = ? AND deleted_at IS NOT NULL`
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := checkAffected(tx.ExecContext(ctx, query, id)); err != nil {
+			return err
+		}
+		return recordRevision(ctx, tx, id, models.RevisionRestore, actorID)
+	})
+}
+
+// PurgeDeletedAPIs permanently removes the APIs deleted before cutoff, along
+// with their category mappings, and returns how many were removed. Their
+// revisions are kept.
+func (r *SQLiteAPIRepository) PurgeDeletedAPIs(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `DELETE FROM apis WHERE deleted_at IS NOT NULL AND deleted_at < ?`
+	result, err := r.db.ExecContext(ctx, query, cutoff.UTC().Format(timestampLayout))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestAPITrash(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+	seedTeams(t, db)
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	keep, _ := repo.CreateAPI(ctx, models.API{Name: "Ledger"}, 0)
+	trashed, _ := repo.CreateAPI(ctx, models.API{Name: "Legacy Ledger", TeamID: 4}, 0)
+	if err := repo.DeleteAPI(ctx, trashed, 0, 0); err != nil {
+		t.Fatalf("Error deleting API: %v", err)
+	}
+
+	t.Run("HiddenFromQueries", func(t *testing.T) {
+		if _, err := repo.GetAPIByID(ctx, trashed); err != ErrNotFound {
+			t.Errorf("GetAPIByID: expected ErrNotFound, got %v", err)
+		}
+		if err := repo.UpdateAPI(ctx, models.API{ID: trashed, Name: "Revived"}, 0); err != ErrNotFound {
+			t.Errorf("UpdateAPI: expected ErrNotFound, got %v", err)
+		}
+		if err := repo.DeleteAPI(ctx, trashed, 0, 0); err != ErrNotFound {
+			t.Errorf("DeleteAPI: expected ErrNotFound for an API already in the trash, got %v", err)
+		}
+
+		page, err := repo.ListAPIs(ctx, models.APIFilter{Limit: 1})
+		if err != nil {
+			t.Fatalf("Error listing APIs: %v", err)
+		}
+		if page.Total != 1 || page.Items[0].ID != keep || page.NextCursor != "" {
+			t.Errorf("Expected only the live API, got %+v", page)
+		}
+
+		results, err := repo.SearchAPIs(ctx, "ledger", 0)
+		if err != nil {
+			t.Fatalf("Error searching APIs: %v", err)
+		}
+		if results.Total != 1 || results.Items[0].API.ID != keep {
+			t.Errorf("Expected only the live API in search results, got %+v", results)
+		}
+	})
+
+	t.Run("ListDeleted", func(t *testing.T) {
+		deleted, err := repo.ListDeletedAPIs(ctx)
+		if err != nil {
+			t.Fatalf("Error listing deleted APIs: %v", err)
+		}
+		if len(deleted) != 1 || deleted[0].API.ID != trashed || deleted[0].API.TeamID != 4 || deleted[0].DeletedAt.IsZero() {
+			t.Errorf("Unexpected trash: %+v", deleted)
+		}
+
+		if _, err := repo.GetDeletedAPI(ctx, keep); err != ErrNotFound {
+			t.Errorf("GetDeletedAPI: expected ErrNotFound for a live API, got %v", err)
+		}
+	})
+
+	t.Run("Undelete", func(t *testing.T) {
+		if err := repo.UndeleteAPI(ctx, trashed, 0); err != nil {
+			t.Fatalf("Error undeleting API: %v", err)
+		}
+		if _, err := repo.GetAPIByID(ctx, trashed); err != nil {
+			t.Errorf("Expected API to be back, got %v", err)
+		}
+		if err := repo.UndeleteAPI(ctx, trashed, 0); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound for an API not in the trash, got %v", err)
+		}
+
+		revisions, _ := repo.ListAPIRevisions(ctx, trashed)
+		if len(revisions) != 3 || revisions[0].Action != models.RevisionRestore || revisions[1].Action != models.RevisionDelete {
+			t.Errorf("Expected delete and restore revisions, got %+v", revisions)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		old, _ := repo.CreateAPI(ctx, models.API{Name: "Old"}, 0)
+		recent, _ := repo.CreateAPI(ctx, models.API{Name: "Recent"}, 0)
+		repo.DeleteAPI(ctx, old, 0, 0)
+		repo.DeleteAPI(ctx, recent, 0, 0)
+		if _, err := db.Exec(`UPDATE apis SET deleted_at = '2024-01-01 00:00:00' WHERE id = ?`, old); err != nil {
+			t.Fatalf("Error backdating API: %v", err)
+		}
+
+		purged, err := repo.PurgeDeletedAPIs(ctx, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+		if err != nil {
+			t.Fatalf("Error purging APIs: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("Expected 1 purged API, got %d", purged)
+		}
+
+		deleted, _ := repo.ListDeletedAPIs(ctx)
+		if len(deleted) != 1 || deleted[0].API.ID != recent {
+			t.Errorf("Expected only the recent API to remain in the trash, got %+v", deleted)
+		}
+		if _, err := repo.ListAPIRevisions(ctx, old); err != nil {
+			t.Errorf("Expected history of a purged API to be kept, got %v", err)
+		}
+	})
+}
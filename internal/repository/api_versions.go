@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"time"
+)
+
+const versionSelectList = `v.id, v.api_id, v.version, v.swagger, v.documentation_link, v.status,
+	v.released_at, v.created_at, v.updated_at`
+
+func scanVersion(row rowScanner) (models.APIVersion, error) {
+	var v models.APIVersion
+	err := row.Scan(&v.ID, &v.APIID, &v.Version, nullable(&v.Swagger), nullable(&v.DocumentationLink),
+		&v.Status, nullable(&v.ReleasedAt), nullable(&v.CreatedAt), nullable(&v.UpdatedAt))
+	return v, err
+}
+
+// releasedAt stores an unreleased version as NULL rather than the zero time.
+func releasedAt(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC().Format(timestampLayout)
+}
+
+// ListAPIVersions returns the versions of a live API in the order they were
+// created. Ordering by precedence is left to the caller, since SQLite cannot
+// compare semantic versions.
+func (r *SQLiteAPIRepository) ListAPIVersions(ctx context.Context, apiID int64) ([]models.APIVersion, error) {
+	query := `SELECT ` + versionSelectList + ` FROM api_versions v
+		JOIN apis a ON a.id = v.api_id
+		WHERE v.api_id = ? AND a.deleted_at IS NULL
+		ORDER BY v.id`
+	rows, err := r.db.QueryContext(ctx, query, apiID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []models.APIVersion{}
+	for rows.Next() {
+		v, err := scanVersion(rows)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, v)
+	}
+	return versions, rows.Err()
+}
+
+func (r *SQLiteAPIRepository) GetAPIVersion(ctx context.Context, apiID int64, version string) (models.APIVersion, error) {
+	query := `SELECT ` + versionSelectList + ` FROM api_versions v
+		JOIN apis a ON a.id = v.api_id
+		WHERE v.api_id = ? AND v.version = ? AND a.deleted_at IS NULL`
+	v, err := scanVersion(r.db.QueryRowContext(ctx, query, apiID, version))
+	return v, translateError(err)
+}
+
+// CreateAPIVersion adds a version to a live API. It returns ErrNotFound when
+// the API does not exist or is in the trash, and ErrConflict when the API
+// already has that version.
+func (r *SQLiteAPIRepository) CreateAPIVersion(ctx context.Context, v models.APIVersion) (int64, error) {
+	query := `INSERT INTO api_versions (api_id, version, swagger, documentation_link, status, released_at)
+		SELECT id, ?, ?, ?, ?, ? FROM apis WHERE id = ? AND deleted_at IS NULL`
+	result, err := r.db.ExecContext(ctx, query,
+		v.Version, v.Swagger, v.DocumentationLink, v.Status, releasedAt(v.ReleasedAt), v.APIID)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, ErrNotFound
+	}
+	return result.LastInsertId()
+}
+
+// UpdateAPIVersion replaces everything but the version string, which
+// identifies the row together with APIID.
+func (r *SQLiteAPIRepository) UpdateAPIVersion(ctx context.Context, v models.APIVersion) error {
+	query := `UPDATE api_versions
+		SET swagger = ?, documentation_link = ?, status = ?, released_at = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE api_id = ? AND version = ?
+		AND api_id IN (SELECT id FROM apis WHERE deleted_at IS NULL)`
+	return checkAffected(r.db.ExecContext(ctx, query,
+		v.Swagger, v.DocumentationLink, v.Status, releasedAt(v.ReleasedAt), v.APIID, v.Version))
+}
+
+func (r *SQLiteAPIRepository) DeleteAPIVersion(ctx context.Context, apiID int64, version string) error {
+	query := `DELETE FROM api_versions WHERE api_id = ? AND version = ?
+		AND api_id IN (SELECT id FROM apis WHERE deleted_at IS NULL)`
+	return checkAffected(r.db.ExecContext(ctx, query, apiID, version))
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestAPIVersions(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAPIRepository(db)
+	ctx := context.Background()
+
+	apiID, _ := repo.CreateAPI(ctx, models.API{Name: "Orders"}, 0)
+	released := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Create", func(t *testing.T) {
+		id, err := repo.CreateAPIVersion(ctx, models.APIVersion{
+			APIID: apiID, Version: "1.0.0", Status: models.LifecycleStable, ReleasedAt: released,
+			DocumentationLink: "https://docs.example.com/orders/v1",
+		})
+		if err != nil {
+			t.Fatalf("Error creating version: %v", err)
+		}
+		if id <= 0 {
+			t.Errorf("Expected positive ID, got %d", id)
+		}
+		if _, err := repo.CreateAPIVersion(ctx, models.APIVersion{APIID: apiID, Version: "2.0.0-rc.1", Status: models.LifecycleExperimental}); err != nil {
+			t.Fatalf("Error creating version: %v", err)
+		}
+
+		if _, err := repo.CreateAPIVersion(ctx, models.APIVersion{APIID: apiID, Version: "1.0.0", Status: models.LifecycleStable}); !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict for a duplicate version, got %v", err)
+		}
+		if _, err := repo.CreateAPIVersion(ctx, models.APIVersion{APIID: 999, Version: "1.0.0", Status: models.LifecycleStable}); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound for a missing API, got %v", err)
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		v, err := repo.GetAPIVersion(ctx, apiID, "1.0.0")
+		if err != nil {
+			t.Fatalf("Error getting version: %v", err)
+		}
+		if !v.ReleasedAt.Equal(released) || v.DocumentationLink != "https://docs.example.com/orders/v1" || v.CreatedAt.IsZero() {
+			t.Errorf("Unexpected version: %+v", v)
+		}
+
+		rc, _ := repo.GetAPIVersion(ctx, apiID, "2.0.0-rc.1")
+		if !rc.ReleasedAt.IsZero() || rc.Status != models.LifecycleExperimental {
+			t.Errorf("Expected unreleased experimental version, got %+v", rc)
+		}
+
+		if _, err := repo.GetAPIVersion(ctx, apiID, "3.0.0"); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		err := repo.UpdateAPIVersion(ctx, models.APIVersion{APIID: apiID, Version: "1.0.0", Status: models.LifecycleDeprecated})
+		if err != nil {
+			t.Fatalf("Error updating version: %v", err)
+		}
+		v, _ := repo.GetAPIVersion(ctx, apiID, "1.0.0")
+		if v.Status != models.LifecycleDeprecated || v.DocumentationLink != "" || !v.ReleasedAt.IsZero() {
+			t.Errorf("Expected every field to be replaced, got %+v", v)
+		}
+
+		if err := repo.UpdateAPIVersion(ctx, models.APIVersion{APIID: apiID, Version: "9.9.9", Status: models.LifecycleStable}); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		versions, err := repo.ListAPIVersions(ctx, apiID)
+		if err != nil {
+			t.Fatalf("Error listing versions: %v", err)
+		}
+		if len(versions) != 2 || versions[0].Version != "1.0.0" || versions[1].Version != "2.0.0-rc.1" {
+			t.Errorf("Unexpected versions: %+v", versions)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := repo.DeleteAPIVersion(ctx, apiID, "2.0.0-rc.1"); err != nil {
+			t.Fatalf("Error deleting version: %v", err)
+		}
+		if err := repo.DeleteAPIVersion(ctx, apiID, "2.0.0-rc.1"); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("TrashedAPI", func(t *testing.T) {
+		repo.DeleteAPI(ctx, apiID, 0, 0)
+
+		versions, err := repo.ListAPIVersions(ctx, apiID)
+		if err != nil || len(versions) != 0 {
+			t.Errorf("Expected no versions for an API in the trash, got %+v, %v", versions, err)
+		}
+		if _, err := repo.CreateAPIVersion(ctx, models.APIVersion{APIID: apiID, Version: "3.0.0", Status: models.LifecycleStable}); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound when adding to an API in the trash, got %v", err)
+		}
+
+		repo.UndeleteAPI(ctx, apiID, 0)
+		if versions, _ := repo.ListAPIVersions(ctx, apiID); len(versions) != 1 {
+			t.Errorf("Expected versions to come back with the API, got %+v", versions)
+		}
+
+		db.Exec(`UPDATE apis SET deleted_at = '2024-01-01 00:00:00' WHERE id = ?`, apiID)
+		repo.PurgeDeletedAPIs(ctx, time.Now())
+		var count int
+		db.QueryRow(`SELECT COUNT(*) FROM api_versions WHERE api_id = ?`, apiID).Scan(&count)
+		if count != 0 {
+			t.Errorf("Expected versions to be purged with the API, got %d", count)
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"microd-api/internal/models"
+)
+
+type SQLiteCategoryRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteCategoryRepository(db *sql.DB) CategoryRepository {
+	return &SQLiteCategoryRepository{db: db}
+}
+
+func (r *SQLiteCategoryRepository) CreateCategory(ctx context.Context, category models.APICategory) (int64, error) {
+	query := `INSERT INTO api_categories (name) VALUES (?)`
+	result, err := r.db.ExecContext(ctx, query, category.Name)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *SQLiteCategoryRepository) GetCategoryByID(ctx context.Context, id int64) (models.APICategory, error) {
+	query := `SELECT id, name, created_at, updated_at FROM api_categories WHERE id = ?`
+	var category models.APICategory
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&category.ID, &category.Name, &category.CreatedAt, &category.UpdatedAt)
+	return category, translateError(err)
+}
+
+func (r *SQLiteCategoryRepository) UpdateCategory(ctx context.Context, category models.APICategory) error {
+	query := `UPDATE api_categories SET name = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	return checkAffected(r.db.ExecContext(ctx, query, category.Name, category.ID))
+}
+
+func (r *SQLiteCategoryRepository) DeleteCategory(ctx context.Context, id int64) error {
+	query := `DELETE FROM api_categories WHERE id = ?`
+	return checkAffected(r.db.ExecContext(ctx, query, id))
+}
+
+func (r *SQLiteCategoryRepository) ListCategories(ctx context.Context) ([]models.APICategory, error) {
+	query := `SELECT id, name, created_at, updated_at FROM api_categories ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []models.APICategory
+	for rows.Next() {
+		var category models.APICategory
+		err := rows.Scan(&category.ID, &category.Name, &category.CreatedAt, &category.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"microd-api/internal/database"
+	"microd-api/internal/models"
+	"testing"
+	"time"
+)
+
+func setupMigratedDB(t *testing.T) *sql.DB {
+	db, err := database.Open(context.Background(), ":memory:")
+	if err != nil {
+		t.Fatalf("Error opening database: %v", err)
+	}
+	return db
+}
+
+func TestCategoryRepository(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteCategoryRepository(db)
+	ctx := context.Background()
+
+	t.Run("CreateCategory", func(t *testing.T) {
+		id, err := repo.CreateCategory(ctx, models.APICategory{Name: "Payments"})
+		if err != nil {
+			t.Fatalf("Error creating category: %v", err)
+		}
+		if id <= 0 {
+			t.Errorf("Expected positive ID, got %d", id)
+		}
+	})
+
+	t.Run("CreateCategory_DuplicateName", func(t *testing.T) {
+		_, err := repo.CreateCategory(ctx, models.APICategory{Name: "Payments"})
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict creating duplicate category, got %v", err)
+		}
+	})
+
+	t.Run("GetCategoryByID", func(t *testing.T) {
+		category, err := repo.GetCategoryByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("Error getting category: %v", err)
+		}
+		if category.Name != "Payments" {
+			t.Errorf("Expected category name 'Payments', got '%s'", category.Name)
+		}
+	})
+
+	t.Run("UpdateCategory", func(t *testing.T) {
+		err := repo.UpdateCategory(ctx, models.APICategory{ID: 1, Name: "Billing"})
+		if err != nil {
+			t.Fatalf("Error updating category: %v", err)
+		}
+
+		category, err := repo.GetCategoryByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("Error getting updated category: %v", err)
+		}
+		if category.Name != "Billing" {
+			t.Errorf("Expected updated category name 'Billing', got '%s'", category.Name)
+		}
+	})
+
+	t.Run("ListCategories", func(t *testing.T) {
+		if _, err := repo.CreateCategory(ctx, models.APICategory{Name: "Auth"}); err != nil {
+			t.Fatalf("Error creating category: %v", err)
+		}
+
+		categories, err := repo.ListCategories(ctx)
+		if err != nil {
+			t.Fatalf("Error listing categories: %v", err)
+		}
+		if len(categories) != 2 {
+			t.Fatalf("Expected 2 categories, got %d", len(categories))
+		}
+		if categories[0].Name != "Auth" {
+			t.Errorf("Expected categories ordered by name, got '%s' first", categories[0].Name)
+		}
+	})
+
+	t.Run("DeleteCategory", func(t *testing.T) {
+		err := repo.DeleteCategory(ctx, 2)
+		if err != nil {
+			t.Fatalf("Error deleting category: %v", err)
+		}
+
+		_, err = repo.GetCategoryByID(ctx, 2)
+		if err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound after deletion, got %v", err)
+		}
+
+		if err := repo.DeleteCategory(ctx, 2); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound deleting a missing category, got %v", err)
+		}
+	})
+}
+
+func TestAPICategoryMappings(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	apiRepo := NewSQLiteAPIRepository(db)
+	categoryRepo := NewSQLiteCategoryRepository(db)
+	ctx := context.Background()
+
+	apiID, err := apiRepo.CreateAPI(ctx, models.API{Name: "Payments API"}, 0)
+	if err != nil {
+		t.Fatalf("Error creating API: %v", err)
+	}
+	otherAPIID, err := apiRepo.CreateAPI(ctx, models.API{Name: "Users API"}, 0)
+	if err != nil {
+		t.Fatalf("Error creating API: %v", err)
+	}
+	categoryID, err := categoryRepo.CreateCategory(ctx, models.APICategory{Name: "Finance"})
+	if err != nil {
+		t.Fatalf("Error creating category: %v", err)
+	}
+
+	t.Run("AttachCategory", func(t *testing.T) {
+		if err := apiRepo.AttachCategory(ctx, apiID, categoryID); err != nil {
+			t.Fatalf("Error attaching category: %v", err)
+		}
+		if err := apiRepo.AttachCategory(ctx, apiID, categoryID); err != nil {
+			t.Fatalf("Expected attaching twice to be a no-op, got %v", err)
+		}
+
+		categories, err := apiRepo.ListAPICategories(ctx, apiID)
+		if err != nil {
+			t.Fatalf("Error listing API categories: %v", err)
+		}
+		if len(categories) != 1 || categories[0].Name != "Finance" {
+			t.Errorf("Expected API to have category 'Finance', got %v", categories)
+		}
+	})
+
+	t.Run("AttachCategory_UnknownCategory", func(t *testing.T) {
+		if err := apiRepo.AttachCategory(ctx, apiID, 999); err == nil {
+			t.Error("Expected foreign key error attaching unknown category")
+		}
+	})
+
+	t.Run("ListAPIsByCategory", func(t *testing.T) {
+		page, err := apiRepo.ListAPIs(ctx, models.APIFilter{CategoryID: categoryID})
+		if err != nil {
+			t.Fatalf("Error listing APIs: %v", err)
+		}
+		if len(page.Items) != 1 || page.Items[0].ID != apiID {
+			t.Errorf("Expected only API %d in category, got %v", apiID, page.Items)
+		}
+	})
+
+	t.Run("DetachCategory", func(t *testing.T) {
+		if err := apiRepo.AttachCategory(ctx, otherAPIID, categoryID); err != nil {
+			t.Fatalf("Error attaching category: %v", err)
+		}
+		if err := apiRepo.DetachCategory(ctx, otherAPIID, categoryID); err != nil {
+			t.Fatalf("Error detaching category: %v", err)
+		}
+
+		categories, err := apiRepo.ListAPICategories(ctx, otherAPIID)
+		if err != nil {
+			t.Fatalf("Error listing API categories: %v", err)
+		}
+		if len(categories) != 0 {
+			t.Errorf("Expected no categories after detaching, got %d", len(categories))
+		}
+	})
+
+	t.Run("PurgeAPICascades", func(t *testing.T) {
+		if err := apiRepo.DeleteAPI(ctx, apiID, 0, 0); err != nil {
+			t.Fatalf("Error deleting API: %v", err)
+		}
+
+		var count int
+		db.QueryRow(`SELECT COUNT(*) FROM api_category_mappings WHERE api_id = ?`, apiID).Scan(&count)
+		if count != 1 {
+			t.Errorf("Expected mappings to be kept while the API is in the trash, got %d", count)
+		}
+
+		if _, err := apiRepo.PurgeDeletedAPIs(ctx, time.Now().Add(time.Hour)); err != nil {
+			t.Fatalf("Error purging APIs: %v", err)
+		}
+		db.QueryRow(`SELECT COUNT(*) FROM api_category_mappings WHERE api_id = ?`, apiID).Scan(&count)
+		if count != 0 {
+			t.Errorf("Expected mappings to be removed with the API, got %d", count)
+		}
+	})
+
+	t.Run("DeleteCategoryCascades", func(t *testing.T) {
+		if err := apiRepo.AttachCategory(ctx, otherAPIID, categoryID); err != nil {
+			t.Fatalf("Error attaching category: %v", err)
+		}
+		if err := categoryRepo.DeleteCategory(ctx, categoryID); err != nil {
+			t.Fatalf("Error deleting category: %v", err)
+		}
+
+		categories, err := apiRepo.ListAPICategories(ctx, otherAPIID)
+		if err != nil {
+			t.Fatalf("Error listing API categories: %v", err)
+		}
+		if len(categories) != 0 {
+			t.Errorf("Expected mappings to be removed with the category, got %d", len(categories))
+		}
+	})
+}
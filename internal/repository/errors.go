@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+var (
+	ErrNotFound       = errors.New("record not found")
+	ErrConflict       = errors.New("record conflicts with existing data")
+	ErrDuplicateEmail = fmt.Errorf("%w: email already registered", ErrConflict)
+	ErrUnknownTeam    = fmt.Errorf("%w: team does not exist", ErrConflict)
+	ErrTeamHasAPIs    = fmt.Errorf("%w: team still owns APIs", ErrConflict)
+
+	// ErrVersionMismatch means a conditional write found the record at a
+	// different row version than the one expected.
+	ErrVersionMismatch = errors.New("record has been modified")
+)
+
+// BatchError reports the item of a batch write that failed. None of the
+// batch was written.
+type BatchError struct {
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return fmt.Sprintf("item %d: %v", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
+
+// translateError maps driver errors onto the repository errors so that callers
+// never need to know about database/sql or SQLite.
+func translateError(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case isUniqueViolation(err), isForeignKeyViolation(err):
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+	return err
+}
+
+// checkAffected turns a write that matched no rows into ErrNotFound.
+func checkAffected(result sql.Result, err error) error {
+	if err != nil {
+		return translateError(err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+}
+
+func isForeignKeyViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintForeignKey
+}
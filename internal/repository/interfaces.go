@@ -3,12 +3,70 @@ package repository
 import (
 	"context"
 	"microd-api/internal/models"
+	"time"
 )
 
 type APIRepository interface {
-	CreateAPI(ctx context.Context, api models.API) (int64, error)
+	CreateAPI(ctx context.Context, api models.API, actorID int64) (int64, error)
 	GetAPIByID(ctx context.Context, id int64) (models.API, error)
-	UpdateAPI(ctx context.Context, api models.API) error
-	DeleteAPI(ctx context.Context, id int64) error
-	ListAPIs(ctx context.Context) ([]models.API, error)
+	UpdateAPI(ctx context.Context, api models.API, actorID int64) error
+	DeleteAPI(ctx context.Context, id, version, actorID int64) error
+	ListAPIs(ctx context.Context, filter models.APIFilter) (models.APIPage, error)
+	SearchAPIs(ctx context.Context, q string, limit int) (models.APISearchPage, error)
+	AttachCategory(ctx context.Context, apiID, categoryID int64) error
+	DetachCategory(ctx context.Context, apiID, categoryID int64) error
+	ListAPICategories(ctx context.Context, apiID int64) ([]models.APICategory, error)
+	ListAPIRevisions(ctx context.Context, apiID int64) ([]models.APIRevision, error)
+	GetAPIRevision(ctx context.Context, apiID, revision int64) (models.APIRevision, error)
+	RestoreAPIRevision(ctx context.Context, apiID, revision, actorID int64) (models.API, error)
+	ListDeletedAPIs(ctx context.Context) ([]models.DeletedAPI, error)
+	GetDeletedAPI(ctx context.Context, id int64) (models.DeletedAPI, error)
+	UndeleteAPI(ctx context.Context, id, actorID int64) error
+	PurgeDeletedAPIs(ctx context.Context, cutoff time.Time) (int64, error)
+	ListAPIVersions(ctx context.Context, apiID int64) ([]models.APIVersion, error)
+	GetAPIVersion(ctx context.Context, apiID int64, version string) (models.APIVersion, error)
+	CreateAPIVersion(ctx context.Context, version models.APIVersion) (int64, error)
+	UpdateAPIVersion(ctx context.Context, version models.APIVersion) error
+	DeleteAPIVersion(ctx context.Context, apiID int64, version string) error
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	RenameTag(ctx context.Context, name, newName string, actorID int64) ([]int64, error)
+	MergeTags(ctx context.Context, name, into string, actorID int64) ([]int64, error)
+	AddAPIDependency(ctx context.Context, apiID, dependsOnID int64) error
+	RemoveAPIDependency(ctx context.Context, apiID, dependsOnID int64) error
+	ListAPIDependencies(ctx context.Context, apiID int64, maxDepth int) ([]models.DependencyNode, error)
+	ListAPIDependents(ctx context.Context, apiID int64, maxDepth int) ([]models.DependencyNode, error)
+	GetDependencyGraph(ctx context.Context) (models.DependencyGraph, error)
+	ImportAPIs(ctx context.Context, apis []models.API, actorID int64) ([]int64, error)
+	PatchAPI(ctx context.Context, id int64, patch func(current models.API) (models.API, error), actorID int64) (models.API, error)
+}
+
+type CategoryRepository interface {
+	CreateCategory(ctx context.Context, category models.APICategory) (int64, error)
+	GetCategoryByID(ctx context.Context, id int64) (models.APICategory, error)
+	UpdateCategory(ctx context.Context, category models.APICategory) error
+	DeleteCategory(ctx context.Context, id int64) error
+	ListCategories(ctx context.Context) ([]models.APICategory, error)
+}
+
+type TeamRepository interface {
+	CreateTeam(ctx context.Context, team models.Team) (int64, error)
+	GetTeamByID(ctx context.Context, id int64) (models.Team, error)
+	UpdateTeam(ctx context.Context, team models.Team) error
+	DeleteTeam(ctx context.Context, id int64) error
+	ListTeams(ctx context.Context) ([]models.Team, error)
+	ListTeamMembers(ctx context.Context, id int64) ([]models.User, error)
+	AddTeamMember(ctx context.Context, id, userID int64) error
+	RemoveTeamMember(ctx context.Context, id, userID int64) error
+}
+
+type UserRepository interface {
+	CreateUser(ctx context.Context, user models.User) (int64, error)
+	GetUserByID(ctx context.Context, id int64) (models.User, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, error)
+	UpdateUser(ctx context.Context, user models.User) error
+	UpdateUserRole(ctx context.Context, id int64, role int64) error
+	UpdateUserTeam(ctx context.Context, id int64, teamID int64) error
+	ListUsers(ctx context.Context) ([]models.User, error)
+	SetRefreshToken(ctx context.Context, id int64, tokenHash string) error
+	RotateRefreshToken(ctx context.Context, id int64, oldHash, newHash string) (bool, error)
 }
@@ -0,0 +1,119 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"microd-api/internal/models"
+)
+
+type SQLiteTeamRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteTeamRepository(db *sql.DB) TeamRepository {
+	return &SQLiteTeamRepository{db: db}
+}
+
+const teamColumns = `id, name, COALESCE(description, ''), created_at, updated_at`
+
+func scanTeam(row rowScanner) (models.Team, error) {
+	var team models.Team
+	err := row.Scan(&team.ID, &team.Name, &team.Description, &team.CreatedAt, &team.UpdatedAt)
+	return team, translateError(err)
+}
+
+func (r *SQLiteTeamRepository) CreateTeam(ctx context.Context, team models.Team) (int64, error) {
+	query := `INSERT INTO teams (name, description) VALUES (?, NULLIF(?, ''))`
+	result, err := r.db.ExecContext(ctx, query, team.Name, team.Description)
+	if err != nil {
+		return 0, translateError(err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *SQLiteTeamRepository) GetTeamByID(ctx context.Context, id int64) (models.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams WHERE id = ?`
+	return scanTeam(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *SQLiteTeamRepository) UpdateTeam(ctx context.Context, team models.Team) error {
+	query := `UPDATE teams SET name = ?, description = NULLIF(?, ''), updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	return checkAffected(r.db.ExecContext(ctx, query, team.Name, team.Description, team.ID))
+}
+
+// DeleteTeam removes a team and its memberships. A team that still owns live
+// APIs cannot be deleted; APIs in the trash lose their owner.
+func (r *SQLiteTeamRepository) DeleteTeam(ctx context.Context, id int64) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var owned int64
+	query := `SELECT COUNT(*) FROM apis WHERE team_id = ? AND deleted_at IS NULL`
+	if err := tx.QueryRowContext(ctx, query, id).Scan(&owned); err != nil {
+		return err
+	}
+	if owned > 0 {
+		return ErrTeamHasAPIs
+	}
+	if err := checkAffected(tx.ExecContext(ctx, `DELETE FROM teams WHERE id = ?`, id)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteTeamRepository) ListTeams(ctx context.Context) ([]models.Team, error) {
+	query := `SELECT ` + teamColumns + ` FROM teams ORDER BY name`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	teams := []models.Team{}
+	for rows.Next() {
+		team, err := scanTeam(rows)
+		if err != nil {
+			return nil, err
+		}
+		teams = append(teams, team)
+	}
+	return teams, rows.Err()
+}
+
+func (r *SQLiteTeamRepository) ListTeamMembers(ctx context.Context, id int64) ([]models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE team_id = ? ORDER BY name, id`
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// AddTeamMember moves a user into the team, taking them out of any team they
+// were a member of before.
+func (r *SQLiteTeamRepository) AddTeamMember(ctx context.Context, id, userID int64) error {
+	query := `UPDATE users SET team_id = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	result, err := r.db.ExecContext(ctx, query, id, userID)
+	if isForeignKeyViolation(err) {
+		return ErrUnknownTeam
+	}
+	return checkAffected(result, err)
+}
+
+func (r *SQLiteTeamRepository) RemoveTeamMember(ctx context.Context, id, userID int64) error {
+	query := `UPDATE users SET team_id = NULL, updated_at = CURRENT_TIMESTAMP WHERE id = ? AND team_id = ?`
+	return checkAffected(r.db.ExecContext(ctx, query, userID, id))
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"microd-api/internal/models"
+	"testing"
+)
+
+// seedTeams creates the teams the API fixtures refer to, so that Payments is
+// team 1, Identity team 2 and so on.
+func seedTeams(t *testing.T, db *sql.DB) {
+	t.Helper()
+	for _, name := range []string{"Payments", "Identity", "Commerce", "Finance", "Checkout", "Fulfilment"} {
+		if _, err := db.Exec(`INSERT INTO teams (name) VALUES (?)`, name); err != nil {
+			t.Fatalf("Error creating team %s: %v", name, err)
+		}
+	}
+}
+
+func TestTeamRepository(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteTeamRepository(db)
+	apis := NewSQLiteAPIRepository(db)
+	users := NewSQLiteUserRepository(db)
+	ctx := context.Background()
+
+	var id int64
+	t.Run("CreateTeam", func(t *testing.T) {
+		var err error
+		id, err = repo.CreateTeam(ctx, models.Team{Name: "Payments", Description: "Money movement"})
+		if err != nil {
+			t.Fatalf("Error creating team: %v", err)
+		}
+		if _, err := repo.CreateTeam(ctx, models.Team{Name: "payments"}); !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict for a name differing only in case, got %v", err)
+		}
+	})
+
+	t.Run("GetTeamByID", func(t *testing.T) {
+		team, err := repo.GetTeamByID(ctx, id)
+		if err != nil {
+			t.Fatalf("Error getting team: %v", err)
+		}
+		if team.Name != "Payments" || team.Description != "Money movement" || team.CreatedAt.IsZero() {
+			t.Errorf("Unexpected team: %+v", team)
+		}
+		if _, err := repo.GetTeamByID(ctx, 42); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		userID, _ := users.CreateUser(ctx, models.User{Name: "Ada", Email: "ada@example.com"})
+		if err := repo.AddTeamMember(ctx, id, userID); err != nil {
+			t.Fatalf("Error adding member: %v", err)
+		}
+		if err := repo.AddTeamMember(ctx, id, 42); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound for an unknown user, got %v", err)
+		}
+
+		members, err := repo.ListTeamMembers(ctx, id)
+		if err != nil {
+			t.Fatalf("Error listing members: %v", err)
+		}
+		if len(members) != 1 || members[0].ID != userID || members[0].TeamID != id {
+			t.Errorf("Unexpected members: %+v", members)
+		}
+
+		if err := repo.RemoveTeamMember(ctx, id, userID); err != nil {
+			t.Fatalf("Error removing member: %v", err)
+		}
+		if err := repo.RemoveTeamMember(ctx, id, userID); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound removing a user who is not a member, got %v", err)
+		}
+	})
+
+	t.Run("OwnsAPIs", func(t *testing.T) {
+		if _, err := apis.CreateAPI(ctx, models.API{Name: "Ghost", TeamID: 42}, 0); err != ErrUnknownTeam {
+			t.Errorf("Expected ErrUnknownTeam, got %v", err)
+		}
+
+		apiID, err := apis.CreateAPI(ctx, models.API{Name: "Payouts", TeamID: id}, 0)
+		if err != nil {
+			t.Fatalf("Error creating API: %v", err)
+		}
+		if err := repo.UpdateTeam(ctx, models.Team{ID: id, Name: "Treasury"}); err != nil {
+			t.Fatalf("Error renaming team: %v", err)
+		}
+		results, _ := apis.SearchAPIs(ctx, "treasury", 0)
+		if results.Total != 1 || results.Items[0].API.ID != apiID {
+			t.Errorf("Expected the renamed team to be searchable, got %+v", results)
+		}
+
+		if err := repo.DeleteTeam(ctx, id); !errors.Is(err, ErrTeamHasAPIs) {
+			t.Errorf("Expected ErrTeamHasAPIs, got %v", err)
+		}
+		apis.DeleteAPI(ctx, apiID, 0, 0)
+		if err := repo.DeleteTeam(ctx, id); err != nil {
+			t.Fatalf("Error deleting team: %v", err)
+		}
+		deleted, _ := apis.GetDeletedAPI(ctx, apiID)
+		if deleted.API.TeamID != 0 {
+			t.Errorf("Expected the trashed API to lose its owner, got team %d", deleted.API.TeamID)
+		}
+		if err := repo.DeleteTeam(ctx, id); err != ErrNotFound {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ListTeams", func(t *testing.T) {
+		repo.CreateTeam(ctx, models.Team{Name: "Identity"})
+		repo.CreateTeam(ctx, models.Team{Name: "Commerce"})
+		teams, err := repo.ListTeams(ctx)
+		if err != nil {
+			t.Fatalf("Error listing teams: %v", err)
+		}
+		if len(teams) != 2 || teams[0].Name != "Commerce" || teams[1].Name != "Identity" {
+			t.Errorf("Unexpected teams: %+v", teams)
+		}
+	})
+}
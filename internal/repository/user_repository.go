@@ -0,0 +1,110 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"microd-api/internal/models"
+)
+
+type SQLiteUserRepository struct {
+	db *sql.DB
+}
+
+func NewSQLiteUserRepository(db *sql.DB) UserRepository {
+	return &SQLiteUserRepository{db: db}
+}
+
+const userColumns = `id, name, email, COALESCE(avatar, ''), COALESCE(refresh_token, ''), COALESCE(password_hash, ''), role, COALESCE(team_id, 0), created_at, updated_at`
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanUser(row rowScanner) (models.User, error) {
+	var user models.User
+	err := row.Scan(
+		&user.ID, &user.Name, &user.Email, &user.Avatar, &user.RefreshToken,
+		&user.PasswordHash, &user.Role, &user.TeamID, &user.CreatedAt, &user.UpdatedAt)
+	return user, translateError(err)
+}
+
+func (r *SQLiteUserRepository) CreateUser(ctx context.Context, user models.User) (int64, error) {
+	query := `INSERT INTO users (name, email, avatar, password_hash, role) VALUES (?, ?, ?, NULLIF(?, ''), ?)`
+	result, err := r.db.ExecContext(ctx, query, user.Name, user.Email, user.Avatar, user.PasswordHash, user.Role)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return 0, ErrDuplicateEmail
+		}
+		return 0, translateError(err)
+	}
+	return result.LastInsertId()
+}
+
+func (r *SQLiteUserRepository) GetUserByID(ctx context.Context, id int64) (models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+	return scanUser(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *SQLiteUserRepository) GetUserByEmail(ctx context.Context, email string) (models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = ?`
+	return scanUser(r.db.QueryRowContext(ctx, query, email))
+}
+
+func (r *SQLiteUserRepository) UpdateUser(ctx context.Context, user models.User) error {
+	query := `UPDATE users SET name = ?, avatar = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	return checkAffected(r.db.ExecContext(ctx, query, user.Name, user.Avatar, user.ID))
+}
+
+func (r *SQLiteUserRepository) UpdateUserRole(ctx context.Context, id int64, role int64) error {
+	query := `UPDATE users SET role = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	return checkAffected(r.db.ExecContext(ctx, query, role, id))
+}
+
+// UpdateUserTeam moves a user to teamID, or out of any team when teamID is 0.
+func (r *SQLiteUserRepository) UpdateUserTeam(ctx context.Context, id int64, teamID int64) error {
+	query := `UPDATE users SET team_id = NULLIF(?, 0), updated_at = CURRENT_TIMESTAMP WHERE id = ?`
+	result, err := r.db.ExecContext(ctx, query, teamID, id)
+	if isForeignKeyViolation(err) {
+		return ErrUnknownTeam
+	}
+	return checkAffected(result, err)
+}
+
+func (r *SQLiteUserRepository) ListUsers(ctx context.Context) ([]models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY id`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+func (r *SQLiteUserRepository) SetRefreshToken(ctx context.Context, id int64, tokenHash string) error {
+	query := `UPDATE users SET refresh_token = NULLIF(?, '') WHERE id = ?`
+	return checkAffected(r.db.ExecContext(ctx, query, tokenHash, id))
+}
+
+// RotateRefreshToken replaces the stored refresh token hash only if it still
+// matches oldHash, so two concurrent refreshes cannot both succeed.
+func (r *SQLiteUserRepository) RotateRefreshToken(ctx context.Context, id int64, oldHash, newHash string) (bool, error) {
+	query := `UPDATE users SET refresh_token = ? WHERE id = ? AND refresh_token = ?`
+	result, err := r.db.ExecContext(ctx, query, newHash, id, oldHash)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return affected == 1, nil
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"testing"
+)
+
+func TestUserRepository(t *testing.T) {
+	db := setupMigratedDB(t)
+	defer db.Close()
+	seedTeams(t, db)
+
+	repo := NewSQLiteUserRepository(db)
+	ctx := context.Background()
+
+	t.Run("CreateUser", func(t *testing.T) {
+		id, err := repo.CreateUser(ctx, models.User{Name: "Ada", Email: "ada@example.com"})
+		if err != nil {
+			t.Fatalf("Error creating user: %v", err)
+		}
+		if id <= 0 {
+			t.Errorf("Expected positive ID, got %d", id)
+		}
+	})
+
+	t.Run("CreateUser_DuplicateEmail", func(t *testing.T) {
+		_, err := repo.CreateUser(ctx, models.User{Name: "Other", Email: "ada@example.com"})
+		if err != ErrDuplicateEmail {
+			t.Errorf("Expected ErrDuplicateEmail, got %v", err)
+		}
+	})
+
+	t.Run("GetUserByID", func(t *testing.T) {
+		user, err := repo.GetUserByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("Error getting user: %v", err)
+		}
+		if user.Email != "ada@example.com" {
+			t.Errorf("Expected email 'ada@example.com', got '%s'", user.Email)
+		}
+		if user.Role != models.RoleUser {
+			t.Errorf("Expected default role %d, got %d", models.RoleUser, user.Role)
+		}
+	})
+
+	t.Run("GetUserByID_NullColumns", func(t *testing.T) {
+		_, err := db.Exec(`INSERT INTO users (name, email) VALUES ('Null', 'null@example.com')`)
+		if err != nil {
+			t.Fatalf("Error inserting user: %v", err)
+		}
+
+		user, err := repo.GetUserByID(ctx, 2)
+		if err != nil {
+			t.Fatalf("Error getting user with NULL avatar: %v", err)
+		}
+		if user.Avatar != "" {
+			t.Errorf("Expected empty avatar, got '%s'", user.Avatar)
+		}
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		err := repo.UpdateUser(ctx, models.User{ID: 1, Name: "Ada Lovelace", Avatar: "http://avatar.example.com/ada.png"})
+		if err != nil {
+			t.Fatalf("Error updating user: %v", err)
+		}
+
+		user, err := repo.GetUserByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("Error getting updated user: %v", err)
+		}
+		if user.Name != "Ada Lovelace" || user.Avatar != "http://avatar.example.com/ada.png" {
+			t.Errorf("Unexpected user after update: %+v", user)
+		}
+	})
+
+	t.Run("UpdateUserRole", func(t *testing.T) {
+		if err := repo.UpdateUserRole(ctx, 1, models.RoleAdmin); err != nil {
+			t.Fatalf("Error updating user role: %v", err)
+		}
+
+		user, err := repo.GetUserByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("Error getting updated user: %v", err)
+		}
+		if !user.IsAdmin() {
+			t.Errorf("Expected user to be admin, got role %d", user.Role)
+		}
+
+		if err := repo.UpdateUserRole(ctx, 1, 5); err == nil {
+			t.Error("Expected check constraint to reject role 5")
+		}
+	})
+
+	t.Run("UpdateUserTeam", func(t *testing.T) {
+		if err := repo.UpdateUserTeam(ctx, 1, 1); err != nil {
+			t.Fatalf("Error updating user team: %v", err)
+		}
+
+		user, err := repo.GetUserByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("Error getting updated user: %v", err)
+		}
+		if user.TeamID != 1 {
+			t.Errorf("Expected team 1, got %d", user.TeamID)
+		}
+
+		if err := repo.UpdateUserTeam(ctx, 1, 99); err != ErrUnknownTeam {
+			t.Errorf("Expected ErrUnknownTeam, got %v", err)
+		}
+	})
+
+	t.Run("GetUserByEmail", func(t *testing.T) {
+		if _, err := repo.CreateUser(ctx, models.User{Name: "Grace", Email: "grace@example.com", PasswordHash: "hash"}); err != nil {
+			t.Fatalf("Error creating user: %v", err)
+		}
+
+		user, err := repo.GetUserByEmail(ctx, "grace@example.com")
+		if err != nil {
+			t.Fatalf("Error getting user by email: %v", err)
+		}
+		if user.PasswordHash != "hash" {
+			t.Errorf("Expected password hash 'hash', got '%s'", user.PasswordHash)
+		}
+	})
+
+	t.Run("RefreshTokens", func(t *testing.T) {
+		if err := repo.SetRefreshToken(ctx, 1, "first"); err != nil {
+			t.Fatalf("Error setting refresh token: %v", err)
+		}
+
+		rotated, err := repo.RotateRefreshToken(ctx, 1, "stale", "second")
+		if err != nil {
+			t.Fatalf("Error rotating refresh token: %v", err)
+		}
+		if rotated {
+			t.Error("Expected rotation with a stale hash to fail")
+		}
+
+		rotated, err = repo.RotateRefreshToken(ctx, 1, "first", "second")
+		if err != nil {
+			t.Fatalf("Error rotating refresh token: %v", err)
+		}
+		if !rotated {
+			t.Error("Expected rotation with the current hash to succeed")
+		}
+
+		if err := repo.SetRefreshToken(ctx, 1, ""); err != nil {
+			t.Fatalf("Error clearing refresh token: %v", err)
+		}
+		user, _ := repo.GetUserByID(ctx, 1)
+		if user.RefreshToken != "" {
+			t.Errorf("Expected refresh token to be cleared, got '%s'", user.RefreshToken)
+		}
+	})
+
+	t.Run("ListUsers", func(t *testing.T) {
+		users, err := repo.ListUsers(ctx)
+		if err != nil {
+			t.Fatalf("Error listing users: %v", err)
+		}
+		if len(users) != 3 {
+			t.Errorf("Expected 3 users, got %d", len(users))
+		}
+	})
+}
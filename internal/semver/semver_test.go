@@ -0,0 +1,59 @@
+package semver
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected Version
+		valid    bool
+	}{
+		{"1.2.3", Version{Major: 1, Minor: 2, Patch: 3}, true},
+		{"0.0.0", Version{}, true},
+		{"10.20.30-rc.1+build.5", Version{Major: 10, Minor: 20, Patch: 30, Prerelease: "rc.1", Build: "build.5"}, true},
+		{"1.0.0-alpha-beta", Version{Major: 1, Prerelease: "alpha-beta"}, true},
+		{"1.0", Version{}, false},
+		{"v1.0.0", Version{}, false},
+		{"01.0.0", Version{}, false},
+		{"1.0.0-01", Version{}, false},
+		{"1.0.0+", Version{}, false},
+		{"", Version{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			v, err := Parse(tt.input)
+			if (err == nil) != tt.valid {
+				t.Fatalf("Parse(%q) error = %v, want valid=%v", tt.input, err, tt.valid)
+			}
+			if v != tt.expected {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.input, v, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	// Each version has lower precedence than the next, as in the example from
+	// the specification.
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.2.0", "2.0.0", "10.0.0",
+	}
+	for i := 0; i < len(ordered)-1; i++ {
+		a, _ := Parse(ordered[i])
+		b, _ := Parse(ordered[i+1])
+		if got := Compare(a, b); got != -1 {
+			t.Errorf("Compare(%s, %s) = %d, want -1", ordered[i], ordered[i+1], got)
+		}
+		if got := Compare(b, a); got != 1 {
+			t.Errorf("Compare(%s, %s) = %d, want 1", ordered[i+1], ordered[i], got)
+		}
+	}
+
+	a, _ := Parse("1.0.0+build.1")
+	b, _ := Parse("1.0.0+build.2")
+	if got := Compare(a, b); got != 0 {
+		t.Errorf("Expected build metadata to be ignored, got %d", got)
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"encoding/json"
 	"log"
+	"microd-api/internal/auth"
 	"net/http"
 
 	"github.com/go-chi/chi/v5"
@@ -13,16 +14,93 @@ func (s *Server) RegisterRoutes() http.Handler {
 	r := chi.NewRouter()
 	r.Use(middleware.Logger)
 
+	authenticate := auth.Middleware(s.tokenManager, s.userRepository)
+
 	r.Get("/", s.HelloWorldHandler)
 
 	r.Route("/api", func(r chi.Router) {
 		r.Route("/v1", func(r chi.Router) {
+			r.Route("/auth", func(r chi.Router) {
+				r.Post("/login", s.authController.Login)
+				r.Post("/refresh", s.authController.Refresh)
+				r.Post("/logout", s.authController.Logout)
+			})
 			r.Route("/apis", func(r chi.Router) {
+				r.Use(authenticate)
 				r.Post("/", s.apiController.CreateAPI)
 				r.Get("/", s.apiController.ListAPIs)
+				r.Get("/search", s.apiController.SearchAPIs)
+				r.Get("/export", s.apiController.ExportAPIs)
+				r.Post("/import", s.apiController.ImportAPIs)
 				r.Get("/{id}", s.apiController.GetAPIByID)
 				r.Put("/{id}", s.apiController.UpdateAPI)
+				r.Patch("/{id}", s.apiController.PatchAPI)
 				r.Delete("/{id}", s.apiController.DeleteAPI)
+				r.Get("/{id}/spec/operations", s.apiController.GetAPISpecOperations)
+				r.Post("/{id}/spec/diff", s.apiController.DiffAPISpec)
+				r.Get("/{id}/categories", s.apiController.ListAPICategories)
+				r.Put("/{id}/categories/{categoryID}", s.apiController.AttachCategory)
+				r.Delete("/{id}/categories/{categoryID}", s.apiController.DetachCategory)
+				r.Get("/{id}/revisions", s.apiController.ListAPIRevisions)
+				r.Get("/{id}/revisions/{revision}", s.apiController.GetAPIRevision)
+				r.Post("/{id}/revisions/{revision}/restore", s.apiController.RestoreAPIRevision)
+				r.Get("/{id}/versions", s.apiController.ListAPIVersions)
+				r.Post("/{id}/versions", s.apiController.CreateAPIVersion)
+				r.Get("/{id}/versions/{version}", s.apiController.GetAPIVersion)
+				r.Put("/{id}/versions/{version}", s.apiController.UpdateAPIVersion)
+				r.Delete("/{id}/versions/{version}", s.apiController.DeleteAPIVersion)
+				r.Get("/{id}/dependencies", s.apiController.ListAPIDependencies)
+				r.Get("/{id}/dependencies/graph", s.apiController.GetAPIDependencyGraph)
+				r.Put("/{id}/dependencies/{dependencyID}", s.apiController.AddAPIDependency)
+				r.Delete("/{id}/dependencies/{dependencyID}", s.apiController.RemoveAPIDependency)
+				r.Get("/{id}/dependents", s.apiController.ListAPIDependents)
+			})
+			r.Route("/dependencies", func(r chi.Router) {
+				r.Use(authenticate)
+				r.Get("/", s.apiController.GetDependencyGraph)
+			})
+			r.Route("/trash", func(r chi.Router) {
+				r.Use(authenticate)
+				r.Get("/", s.apiController.ListTrash)
+				r.Post("/{id}/restore", s.apiController.RestoreFromTrash)
+			})
+			r.Route("/tags", func(r chi.Router) {
+				r.Use(authenticate)
+				r.Get("/", s.apiController.ListTags)
+				r.Put("/{name}", s.apiController.RenameTag)
+				r.Post("/{name}/merge", s.apiController.MergeTags)
+			})
+			r.Route("/teams", func(r chi.Router) {
+				r.Use(authenticate)
+				r.Post("/", s.teamController.CreateTeam)
+				r.Get("/", s.teamController.ListTeams)
+				r.Get("/{id}", s.teamController.GetTeamByID)
+				r.Put("/{id}", s.teamController.UpdateTeam)
+				r.Delete("/{id}", s.teamController.DeleteTeam)
+				r.Get("/{id}/apis", s.teamController.ListTeamAPIs)
+				r.Get("/{id}/members", s.teamController.ListTeamMembers)
+				r.Put("/{id}/members/{userID}", s.teamController.AddTeamMember)
+				r.Delete("/{id}/members/{userID}", s.teamController.RemoveTeamMember)
+			})
+			r.Route("/users", func(r chi.Router) {
+				r.Post("/", s.userController.RegisterUser)
+				r.Group(func(r chi.Router) {
+					r.Use(authenticate)
+					r.Get("/", s.userController.ListUsers)
+					r.Get("/me", s.userController.GetCurrentUser)
+					r.Get("/{id}", s.userController.GetUserByID)
+					r.Put("/{id}", s.userController.UpdateUser)
+					r.Put("/{id}/role", s.userController.UpdateUserRole)
+					r.Put("/{id}/team", s.userController.UpdateUserTeam)
+				})
+			})
+			r.Route("/categories", func(r chi.Router) {
+				r.Use(authenticate)
+				r.Post("/", s.categoryController.CreateCategory)
+				r.Get("/", s.categoryController.ListCategories)
+				r.Get("/{id}", s.categoryController.GetCategoryByID)
+				r.Put("/{id}", s.categoryController.UpdateCategory)
+				r.Delete("/{id}", s.categoryController.DeleteCategory)
 			})
 		})
 	})
@@ -2,7 +2,9 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"microd-api/internal/auth"
 	"microd-api/internal/controller"
 	"microd-api/internal/mocks"
 	"microd-api/internal/models"
@@ -10,26 +12,47 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestAPIRoutes(t *testing.T) {
 	mockRepo := mocks.NewMockAPIRepository()
 	apiService := service.NewAPIService(mockRepo)
 	apiController := controller.NewAPIController(apiService)
+	categoryController := controller.NewCategoryController(service.NewCategoryService(mocks.NewMockCategoryRepository()))
+	userRepo := mocks.NewMockUserRepository()
+	userController := controller.NewUserController(service.NewUserService(userRepo))
+	teamController := controller.NewTeamController(service.NewTeamService(mocks.NewMockTeamRepository(mockRepo, userRepo), apiService))
+	tokenManager := auth.NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	authController := controller.NewAuthController(service.NewAuthService(userRepo, tokenManager))
 	server := &Server{
-		apiController: apiController,
+		apiController:      apiController,
+		categoryController: categoryController,
+		teamController:     teamController,
+		userController:     userController,
+		userRepository:     userRepo,
+		tokenManager:       tokenManager,
+		authController:     authController,
 	}
 
 	router := server.RegisterRoutes()
 
+	userID, _ := userRepo.CreateUser(context.Background(), models.User{Name: "Route Tester", Email: "routes@example.com", Role: models.RoleAdmin})
+	tokens, err := tokenManager.IssueTokens(models.User{ID: userID})
+	if err != nil {
+		t.Fatalf("error issuing tokens: %v", err)
+	}
+	bearer := "Bearer " + tokens.AccessToken
+
 	t.Run("CreateAPI", func(t *testing.T) {
 		api := models.API{
 			Name:        "Test API",
-			Version:     "1.0",
+			Version:     "1.0.0",
 			Description: "Test Description",
 		}
 		body, _ := json.Marshal(api)
 		req, _ := http.NewRequest("POST", "/api/v1/apis", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", bearer)
 		rr := httptest.NewRecorder()
 
 		router.ServeHTTP(rr, req)
@@ -41,6 +64,7 @@ func TestAPIRoutes(t *testing.T) {
 
 	t.Run("GetAPIByID", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/api/v1/apis/1", nil)
+		req.Header.Set("Authorization", bearer)
 		rr := httptest.NewRecorder()
 
 		router.ServeHTTP(rr, req)
@@ -53,11 +77,25 @@ func TestAPIRoutes(t *testing.T) {
 	t.Run("UpdateAPI", func(t *testing.T) {
 		api := models.API{
 			Name:        "Updated API",
-			Version:     "2.0",
+			Version:     "2.0.0",
 			Description: "Updated Description",
 		}
 		body, _ := json.Marshal(api)
 		req, _ := http.NewRequest("PUT", "/api/v1/apis/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("PatchAPI", func(t *testing.T) {
+		req, _ := http.NewRequest("PATCH", "/api/v1/apis/1", bytes.NewBufferString(`{"description":"Patched"}`))
+		req.Header.Set("Authorization", bearer)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
 		rr := httptest.NewRecorder()
 
 		router.ServeHTTP(rr, req)
@@ -69,6 +107,31 @@ func TestAPIRoutes(t *testing.T) {
 
 	t.Run("DeleteAPI", func(t *testing.T) {
 		req, _ := http.NewRequest("DELETE", "/api/v1/apis/1", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("ListTrash", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/trash", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("RestoreFromTrash", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/trash/1/restore", nil)
+		req.Header.Set("Authorization", bearer)
 		rr := httptest.NewRecorder()
 
 		router.ServeHTTP(rr, req)
@@ -80,6 +143,322 @@ func TestAPIRoutes(t *testing.T) {
 
 	t.Run("ListAPIs", func(t *testing.T) {
 		req, _ := http.NewRequest("GET", "/api/v1/apis", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("SearchAPIs", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/apis/search?q=test", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("GetAPISpecOperations", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/apis/1/spec/operations", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		// The API created above carries no OpenAPI document.
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("DiffAPISpec", func(t *testing.T) {
+		body := bytes.NewBufferString(`{"openapi": "3.0.0", "info": {"title": "Test API", "version": "1.0.0"}, "paths": {}}`)
+		req, _ := http.NewRequest("POST", "/api/v1/apis/1/spec/diff", body)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("ListAPIRevisions", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/apis/1/revisions", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("RestoreAPIRevision", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/apis/1/revisions/1/restore", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("CreateAPIVersion", func(t *testing.T) {
+		body := []byte(`{"version": "2.0.0", "released_at": "2024-01-01T00:00:00Z"}`)
+		req, _ := http.NewRequest("POST", "/api/v1/apis/1/versions", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+		}
+	})
+
+	t.Run("GetLatestAPIVersion", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/apis/1/versions/latest", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("ListTags", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/tags", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("RenameTag", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/api/v1/tags/missing", bytes.NewBufferString(`{"name":"other"}`))
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusNotFound {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+		}
+	})
+
+	t.Run("ImportAPIs", func(t *testing.T) {
+		req, _ := http.NewRequest("POST", "/api/v1/apis/import?dry_run=true", bytes.NewBufferString(`[{"name":"Seeded"}]`))
+		req.Header.Set("Authorization", bearer)
+		req.Header.Set("Content-Type", "application/json")
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("ExportAPIs", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/apis/export?format=csv", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("AddAPIDependency", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/api/v1/apis/1/dependencies/1", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusUnprocessableEntity {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnprocessableEntity)
+		}
+	})
+
+	t.Run("GetAPIDependencyGraph", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/apis/1/dependencies/graph", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("GetDependencyGraph", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/dependencies?format=dot", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("CreateTeam", func(t *testing.T) {
+		body, _ := json.Marshal(models.Team{Name: "Payments"})
+		req, _ := http.NewRequest("POST", "/api/v1/teams", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+		}
+	})
+
+	t.Run("ListTeamAPIs", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/teams/1/apis?limit=10", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("AddTeamMember", func(t *testing.T) {
+		req, _ := http.NewRequest("PUT", "/api/v1/teams/1/members/1", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("CreateCategory", func(t *testing.T) {
+		body, _ := json.Marshal(models.APICategory{Name: "Payments"})
+		req, _ := http.NewRequest("POST", "/api/v1/categories", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+		}
+	})
+
+	t.Run("ListCategories", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/categories", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("ListAPICategories", func(t *testing.T) {
+		body, _ := json.Marshal(models.API{Name: "Categorised API"})
+		req, _ := http.NewRequest("POST", "/api/v1/apis", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", bearer)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		req, _ = http.NewRequest("GET", "/api/v1/apis/2/categories", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("RegisterUser", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"name": "Ada", "email": "ada@example.com", "password": "correct horse"})
+		req, _ := http.NewRequest("POST", "/api/v1/users", bytes.NewBuffer(body))
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusCreated {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusCreated)
+		}
+	})
+
+	t.Run("GetUserByID", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/users/1", nil)
+		req.Header.Set("Authorization", bearer)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+	})
+
+	t.Run("APIsRequireAuthentication", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/api/v1/apis", nil)
+		rr := httptest.NewRecorder()
+
+		router.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusUnauthorized {
+			t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run("CategoriesRequireAuthentication", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"name": "Payments"})
+		for _, req := range []*http.Request{
+			httptest.NewRequest("GET", "/api/v1/categories", nil),
+			httptest.NewRequest("POST", "/api/v1/categories", bytes.NewBuffer(body)),
+			httptest.NewRequest("DELETE", "/api/v1/categories/1", nil),
+		} {
+			rr := httptest.NewRecorder()
+
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != http.StatusUnauthorized {
+				t.Errorf("%s %s returned wrong status code: got %v want %v", req.Method, req.URL.Path, status, http.StatusUnauthorized)
+			}
+		}
+	})
+
+	t.Run("Login", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]string{"email": "ada@example.com", "password": "correct horse"})
+		req, _ := http.NewRequest("POST", "/api/v1/auth/login", bytes.NewBuffer(body))
 		rr := httptest.NewRecorder()
 
 		router.ServeHTTP(rr, req)
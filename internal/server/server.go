@@ -2,11 +2,15 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
 	"fmt"
 	"log"
+	"microd-api/internal/auth"
+	"microd-api/internal/cache"
 	"microd-api/internal/config"
 	"microd-api/internal/controller"
+	"microd-api/internal/database"
 	"microd-api/internal/repository"
 	"microd-api/internal/service"
 	"net/http"
@@ -14,49 +18,156 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
-
-	_ "github.com/mattn/go-sqlite3"
 )
 
 type Server struct {
 	*http.Server
-	db            *sql.DB
-	apiRepository repository.APIRepository
-	apiService    service.APIService
-	apiController controller.APIController
+	db                 *sql.DB
+	cache              cache.Store
+	apiRepository      repository.APIRepository
+	apiService         service.APIService
+	apiController      controller.APIController
+	categoryRepository repository.CategoryRepository
+	categoryService    service.CategoryService
+	categoryController controller.CategoryController
+	teamRepository     repository.TeamRepository
+	teamService        service.TeamService
+	teamController     controller.TeamController
+	userRepository     repository.UserRepository
+	userService        service.UserService
+	userController     controller.UserController
+	tokenManager       *auth.TokenManager
+	authService        service.AuthService
+	authController     controller.AuthController
+	trashPurgeInterval time.Duration
 }
 
 func NewServer(cfg *config.Config) (*Server, error) {
-	db, err := sql.Open("sqlite3", cfg.DBPath)
+	db, err := database.Open(context.Background(), cfg.DBPath)
 	if err != nil {
-		return nil, fmt.Errorf("error opening database: %w", err)
+		return nil, err
 	}
 
 	apiRepo := repository.NewSQLiteAPIRepository(db)
 
-	apiService := service.NewAPIService(apiRepo)
+	store, err := newCacheStore(cfg)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	apiService := service.NewAPIService(apiRepo,
+		service.WithSpecCompatibilityCheck(cfg.EnforceSpecCompatibility),
+		service.WithTrashRetention(cfg.TrashRetention),
+		service.WithCache(store),
+	)
 
 	apiController := controller.NewAPIController(apiService)
 
+	categoryRepo := repository.NewSQLiteCategoryRepository(db)
+
+	categoryService := service.NewCategoryService(categoryRepo)
+
+	categoryController := controller.NewCategoryController(categoryService)
+
+	teamRepo := repository.NewSQLiteTeamRepository(db)
+
+	teamService := service.NewTeamService(teamRepo, apiService)
+
+	teamController := controller.NewTeamController(teamService)
+
+	userRepo := repository.NewSQLiteUserRepository(db)
+
+	userService := service.NewUserService(userRepo, service.WithAdminEmail(cfg.AdminEmail))
+
+	userController := controller.NewUserController(userService)
+
+	secret := []byte(cfg.JWTSecret)
+	if len(secret) == 0 {
+		log.Println("JWT_SECRET is not set, using a random secret; tokens will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("error generating JWT secret: %w", err)
+		}
+	}
+	tokenManager := auth.NewTokenManager(secret, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+
+	authService := service.NewAuthService(userRepo, tokenManager)
+
+	authController := controller.NewAuthController(authService)
+
 	s := &Server{
 		Server: &http.Server{
 			Addr:         fmt.Sprintf(":%d", cfg.Port),
 			ReadTimeout:  10 * time.Second,
 			WriteTimeout: 10 * time.Second,
 		},
-		db:            db,
-		apiRepository: apiRepo,
-		apiService:    apiService,
-		apiController: apiController,
+		db:                 db,
+		cache:              store,
+		apiRepository:      apiRepo,
+		apiService:         apiService,
+		apiController:      apiController,
+		categoryRepository: categoryRepo,
+		categoryService:    categoryService,
+		categoryController: categoryController,
+		teamRepository:     teamRepo,
+		teamService:        teamService,
+		teamController:     teamController,
+		userRepository:     userRepo,
+		userService:        userService,
+		userController:     userController,
+		tokenManager:       tokenManager,
+		authService:        authService,
+		authController:     authController,
+		trashPurgeInterval: cfg.TrashPurgeInterval,
 	}
 
 	s.Handler = s.RegisterRoutes()
 	return s, nil
 }
 
+// cacheKeyPrefix namespaces the keys this service keeps in a shared Redis.
+const cacheKeyPrefix = "microd-api:"
+
+// newCacheStore builds the cache backend selected in cfg. Settings left at
+// their zero value fall back to the defaults of config.Load.
+func newCacheStore(cfg *config.Config) (cache.Store, error) {
+	ttl := cfg.CacheTTL
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+
+	switch cfg.CacheBackend {
+	case "", config.CacheMemory:
+		return cache.NewCache(ttl), nil
+	case config.CacheLRU:
+		maxBytes := cfg.CacheMaxBytes
+		if maxBytes <= 0 {
+			maxBytes = 64 << 20
+		}
+		return cache.NewLRU(maxBytes, ttl), nil
+	case config.CacheRedis:
+		opts, err := cache.ParseRedisURL(cfg.CacheRedisURL)
+		if err != nil {
+			return nil, err
+		}
+		opts.Prefix = cacheKeyPrefix
+		opts.TTL = ttl
+		return cache.NewRedisStore(opts), nil
+	}
+	return nil, fmt.Errorf("unknown cache backend %q", cfg.CacheBackend)
+}
+
 func (s *Server) Run(ctx context.Context) error {
 	serverErrors := make(chan error, 1)
 
+	if s.trashPurgeInterval > 0 {
+		purgeCtx, stopPurge := context.WithCancel(ctx)
+		defer stopPurge()
+		go s.purgeTrash(purgeCtx)
+	}
+
 	go func() {
 		log.Printf("Server is listening on %s", s.Addr)
 		serverErrors <- s.ListenAndServe()
@@ -81,6 +192,28 @@ func (s *Server) Run(ctx context.Context) error {
 	return nil
 }
 
+// purgeTrash removes expired entries from the trash once at startup and then
+// every trashPurgeInterval until ctx is done.
+func (s *Server) purgeTrash(ctx context.Context) {
+	ticker := time.NewTicker(s.trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.apiService.PurgeTrash(ctx)
+		if err != nil {
+			log.Printf("Error purging trash: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d APIs from the trash", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 func (s *Server) GracefulShutdown(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
@@ -94,6 +227,12 @@ func (s *Server) GracefulShutdown(ctx context.Context) error {
 	return nil
 }
 
+// Close stops the cache and closes the database.
 func (s *Server) Close() error {
+	stats := s.cache.Stats()
+	log.Printf("Cache: %d hits, %d misses, %d evictions", stats.Hits, stats.Misses, stats.Evictions)
+	if err := s.cache.Close(); err != nil {
+		log.Printf("Error closing cache: %v", err)
+	}
 	return s.db.Close()
 }
@@ -2,9 +2,15 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"microd-api/internal/auth"
+	"microd-api/internal/cache"
 	"microd-api/internal/config"
 	"net/http"
+	"net/http/httptest"
 	"os"
+	"reflect"
+	"strings"
 	"syscall"
 	"testing"
 	"time"
@@ -28,6 +34,25 @@ func TestNewServer(t *testing.T) {
 	}
 }
 
+func TestNewServer_AppliesMigrations(t *testing.T) {
+	cfg := &config.Config{
+		DBPath: ":memory:",
+		Port:   8080,
+	}
+
+	server, err := NewServer(cfg)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	defer server.Close()
+
+	var count int
+	err = server.db.QueryRow(`SELECT COUNT(*) FROM apis`).Scan(&count)
+	if err != nil {
+		t.Fatalf("expected apis table to exist: %v", err)
+	}
+}
+
 func TestServer_Run(t *testing.T) {
 	cfg := &config.Config{
 		DBPath: ":memory:",
@@ -35,7 +60,10 @@ func TestServer_Run(t *testing.T) {
 	}
 
 	t.Run("NormalShutdown", func(t *testing.T) {
-		server, _ := NewServer(cfg)
+		server, err := NewServer(cfg)
+		if err != nil {
+			t.Fatalf("NewServer() error = %v", err)
+		}
 		ctx, cancel := context.WithCancel(context.Background())
 		errCh := make(chan error)
 
@@ -58,7 +86,10 @@ func TestServer_Run(t *testing.T) {
 	})
 
 	t.Run("ShutdownOnSIGTERM", func(t *testing.T) {
-		server, _ := NewServer(cfg)
+		server, err := NewServer(cfg)
+		if err != nil {
+			t.Fatalf("NewServer() error = %v", err)
+		}
 		ctx := context.Background()
 		errCh := make(chan error)
 
@@ -87,7 +118,10 @@ func TestServer_GracefulShutdown(t *testing.T) {
 		Port:   8080,
 	}
 
-	server, _ := NewServer(cfg)
+	server, err := NewServer(cfg)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
 
 	go func() {
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -97,7 +131,7 @@ func TestServer_GracefulShutdown(t *testing.T) {
 
 	time.Sleep(100 * time.Millisecond)
 
-	err := server.GracefulShutdown(context.Background())
+	err = server.GracefulShutdown(context.Background())
 	if err != nil {
 		t.Errorf("Server.GracefulShutdown() error = %v", err)
 	}
@@ -109,10 +143,113 @@ func TestServer_Close(t *testing.T) {
 		Port:   8080,
 	}
 
-	server, _ := NewServer(cfg)
+	server, err := NewServer(cfg)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
 
-	err := server.Close()
+	err = server.Close()
 	if err != nil {
 		t.Errorf("Server.Close() error = %v", err)
 	}
 }
+
+func TestServer_PurgeTrash(t *testing.T) {
+	cfg := &config.Config{
+		DBPath:             ":memory:",
+		Port:               8080,
+		TrashRetention:     time.Hour,
+		TrashPurgeInterval: 10 * time.Millisecond,
+	}
+
+	server, err := NewServer(cfg)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	defer server.Close()
+
+	_, err = server.db.Exec(`INSERT INTO apis (name, deleted_at) VALUES ('Expired', '2024-01-01 00:00:00'), ('Fresh', CURRENT_TIMESTAMP)`)
+	if err != nil {
+		t.Fatalf("error inserting APIs: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		server.purgeTrash(ctx)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	var count int
+	for time.Now().Before(deadline) {
+		server.db.QueryRow(`SELECT COUNT(*) FROM apis`).Scan(&count)
+		if count == 1 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if count != 1 {
+		t.Errorf("expected only the fresh API to remain, got %d APIs", count)
+	}
+}
+
+func TestNewCacheStore(t *testing.T) {
+	tests := []struct {
+		cfg  config.Config
+		want interface{}
+	}{
+		{config.Config{}, &cache.Cache{}},
+		{config.Config{CacheBackend: config.CacheMemory}, &cache.Cache{}},
+		{config.Config{CacheBackend: config.CacheLRU, CacheMaxBytes: 1024}, &cache.LRU{}},
+		{config.Config{CacheBackend: config.CacheRedis, CacheRedisURL: "redis://localhost:6379/0"}, &cache.RedisStore{}},
+	}
+	for _, tt := range tests {
+		store, err := newCacheStore(&tt.cfg)
+		if err != nil {
+			t.Errorf("newCacheStore(%q) error = %v", tt.cfg.CacheBackend, err)
+			continue
+		}
+		if reflect.TypeOf(store) != reflect.TypeOf(tt.want) {
+			t.Errorf("newCacheStore(%q) = %T, want %T", tt.cfg.CacheBackend, store, tt.want)
+		}
+	}
+
+	if _, err := newCacheStore(&config.Config{CacheBackend: config.CacheRedis, CacheRedisURL: "localhost"}); err == nil {
+		t.Error("expected an error for an invalid Redis URL")
+	}
+	if _, err := NewServer(&config.Config{DBPath: ":memory:", CacheBackend: "memcached"}); err == nil {
+		t.Error("expected NewServer to reject an unknown cache backend")
+	}
+}
+
+func TestServer_AdminBootstrap(t *testing.T) {
+	server, err := NewServer(&config.Config{DBPath: ":memory:", AdminEmail: "ops@example.com", AccessTokenTTL: time.Minute})
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+	defer server.Close()
+
+	send := func(method, path, bearer, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if bearer != "" {
+			req.Header.Set("Authorization", "Bearer "+bearer)
+		}
+		rr := httptest.NewRecorder()
+		server.Handler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	if rr := send("POST", "/api/v1/users/", "", `{"name": "Ops", "email": "ops@example.com", "password": "correct horse"}`); rr.Code != http.StatusCreated {
+		t.Fatalf("register returned %d: %s", rr.Code, rr.Body.String())
+	}
+	var tokens auth.TokenPair
+	json.Unmarshal(send("POST", "/api/v1/auth/login", "", `{"email": "ops@example.com", "password": "correct horse"}`).Body.Bytes(), &tokens)
+
+	if rr := send("POST", "/api/v1/apis/", tokens.AccessToken, `{"name": "Ledger", "version": "1.0.0"}`); rr.Code != http.StatusCreated {
+		t.Errorf("expected the bootstrap admin to create an API, got %d: %s", rr.Code, rr.Body.String())
+	}
+}
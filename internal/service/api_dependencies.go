@@ -0,0 +1,127 @@
+package service
+
+import (
+	"context"
+	"microd-api/internal/graph"
+	"microd-api/internal/models"
+	"microd-api/internal/utils"
+)
+
+// AddAPIDependency records that the API id consumes dependsOnID. Only the
+// owners of the consuming API may declare what it depends on.
+func (s *DefaultAPIService) AddAPIDependency(ctx context.Context, id, dependsOnID int64) error {
+	if _, err := s.authorizeExisting(ctx, id); err != nil {
+		return err
+	}
+	if id == dependsOnID {
+		var v utils.ValidationError
+		v.Add("depends_on_id", "must not be the API itself")
+		return v.Err()
+	}
+	if _, err := s.repo.GetAPIByID(ctx, dependsOnID); err != nil {
+		return fromRepository(err, "dependency")
+	}
+
+	return fromRepository(s.repo.AddAPIDependency(ctx, id, dependsOnID), "dependency")
+}
+
+func (s *DefaultAPIService) RemoveAPIDependency(ctx context.Context, id, dependsOnID int64) error {
+	if _, err := s.authorizeExisting(ctx, id); err != nil {
+		return err
+	}
+
+	return fromRepository(s.repo.RemoveAPIDependency(ctx, id, dependsOnID), "dependency")
+}
+
+// ListAPIDependencies returns the APIs that the API consumes, up to maxDepth
+// edges away, or all of them when maxDepth is 0.
+func (s *DefaultAPIService) ListAPIDependencies(ctx context.Context, id int64, maxDepth int) ([]models.DependencyNode, error) {
+	if err := s.checkDependencyWalk(ctx, id, maxDepth); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAPIDependencies(ctx, id, maxDepth)
+}
+
+// ListAPIDependents returns the APIs that consume the API, up to maxDepth
+// edges away, or all of them when maxDepth is 0: everything that may break
+// when it changes.
+func (s *DefaultAPIService) ListAPIDependents(ctx context.Context, id int64, maxDepth int) ([]models.DependencyNode, error) {
+	if err := s.checkDependencyWalk(ctx, id, maxDepth); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAPIDependents(ctx, id, maxDepth)
+}
+
+func (s *DefaultAPIService) checkDependencyWalk(ctx context.Context, id int64, maxDepth int) error {
+	if maxDepth < 0 {
+		var v utils.ValidationError
+		v.Add("depth", "must not be negative")
+		return v.Err()
+	}
+	if _, err := s.repo.GetAPIByID(ctx, id); err != nil {
+		return fromRepository(err, "API")
+	}
+	return nil
+}
+
+// GetDependencyGraph returns the dependency graph of the catalog. When id is
+// not 0 the graph is cut down to that API and the APIs it depends on or that
+// depend on it, directly or not.
+func (s *DefaultAPIService) GetDependencyGraph(ctx context.Context, id int64) (models.DependencyGraph, error) {
+	full, err := s.repo.GetDependencyGraph(ctx)
+	if err != nil {
+		return models.DependencyGraph{}, err
+	}
+	if id == 0 {
+		full.Cycles = dependencyCycles(full.Edges)
+		return full, nil
+	}
+
+	api, err := s.repo.GetAPIByID(ctx, id)
+	if err != nil {
+		return models.DependencyGraph{}, fromRepository(err, "API")
+	}
+	upstream, err := s.repo.ListAPIDependencies(ctx, id, 0)
+	if err != nil {
+		return models.DependencyGraph{}, err
+	}
+	downstream, err := s.repo.ListAPIDependents(ctx, id, 0)
+	if err != nil {
+		return models.DependencyGraph{}, err
+	}
+
+	keep := map[int64]bool{api.ID: true}
+	for _, node := range append(upstream, downstream...) {
+		keep[node.ID] = true
+	}
+	g := models.DependencyGraph{Nodes: []models.DependencyNode{}, Edges: []models.APIDependency{}}
+	for _, node := range full.Nodes {
+		if keep[node.ID] {
+			g.Nodes = append(g.Nodes, node)
+		}
+	}
+	if len(g.Nodes) == 0 {
+		g.Nodes = append(g.Nodes, models.DependencyNode{
+			ID: api.ID, Name: api.Name, TeamID: api.TeamID, Lifecycle: api.Lifecycle,
+		})
+	}
+	for _, edge := range full.Edges {
+		if keep[edge.APIID] && keep[edge.DependsOnID] {
+			g.Edges = append(g.Edges, edge)
+		}
+	}
+	g.Cycles = dependencyCycles(g.Edges)
+	return g, nil
+}
+
+func dependencyCycles(dependencies []models.APIDependency) [][]int64 {
+	edges := make([]graph.Edge, 0, len(dependencies))
+	for _, d := range dependencies {
+		edges = append(edges, graph.Edge{From: d.APIID, To: d.DependsOnID})
+	}
+	cycles := graph.Cycles(edges)
+	if cycles == nil {
+		return [][]int64{}
+	}
+	return cycles
+}
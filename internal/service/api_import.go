@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microd-api/internal/auth"
+	"microd-api/internal/catalog"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"microd-api/internal/utils"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// ExportAPIs returns every live API that matches filter, ordered by name.
+// Paging does not apply.
+func (s *DefaultAPIService) ExportAPIs(ctx context.Context, filter models.APIFilter) ([]catalog.Record, error) {
+	filter.Limit, filter.Cursor, filter.Sort = 0, "", "name"
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+	filter.Limit = 0 // normalizeFilter applies the default page size
+
+	page, err := s.listAPIs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]catalog.Record, 0, len(page.Items))
+	for _, api := range page.Items {
+		records = append(records, catalog.FromAPI(api))
+	}
+	return records, nil
+}
+
+// ImportAPIs creates an API for every record or, with options.Upsert,
+// replaces the live API of the same name the way UpdateAPI does. Names match
+// case-insensitively. The whole document is checked before anything is
+// written and every problem is reported at once, as a validation error whose
+// fields are prefixed with the index of the record, so either every record is
+// imported or none is.
+func (s *DefaultAPIService) ImportAPIs(ctx context.Context, records []catalog.Record, options models.APIImportOptions) (models.APIImportReport, error) {
+	if _, err := auth.RequireUser(ctx); err != nil {
+		return models.APIImportReport{}, err
+	}
+
+	existing, err := s.repo.ListAPIs(ctx, models.APIFilter{})
+	if err != nil {
+		return models.APIImportReport{}, err
+	}
+	byName := make(map[string][]models.API)
+	for _, api := range existing.Items {
+		key := strings.ToLower(api.Name)
+		byName[key] = append(byName[key], api)
+	}
+
+	report := models.APIImportReport{DryRun: options.DryRun, Items: []models.APIImportItem{}}
+	var writes []models.API
+	var written []int
+	var v utils.ValidationError
+	seen := make(map[string]int)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	for i, record := range records {
+		api := normalizeAPI(record.API())
+		item := models.APIImportItem{Index: i, Name: api.Name, Action: models.ImportCreate}
+		field := func(name string) string { return fmt.Sprintf("[%d].%s", i, name) }
+
+		key := strings.ToLower(api.Name)
+		if first, ok := seen[key]; ok && api.Name != "" {
+			v.Add(field("name"), fmt.Sprintf("repeats the name of record %d", first))
+			continue
+		}
+		seen[key] = i
+
+		var stored *models.API
+		switch matches := byName[key]; {
+		case len(matches) == 0:
+		case !options.Upsert:
+			v.Add(field("name"), "already exists; use upsert to replace it")
+			continue
+		case len(matches) > 1:
+			v.Add(field("name"), "matches more than one API")
+			continue
+		default:
+			stored = &matches[0]
+			api.ID = stored.ID
+			item.ID = stored.ID
+			item.Action = models.ImportUpdate
+		}
+
+		api = applyLifecycle(api, stored, now)
+		if err := s.checkImport(ctx, api, stored, &v, field); err != nil {
+			return models.APIImportReport{}, fmt.Errorf("record %d: %w", i, err)
+		}
+		if stored != nil {
+			item.Changes = changedFields(*stored, api)
+			if len(item.Changes) == 0 {
+				item.Action = models.ImportUnchanged
+			}
+		}
+
+		switch item.Action {
+		case models.ImportCreate:
+			report.Created++
+		case models.ImportUpdate:
+			report.Updated++
+		case models.ImportUnchanged:
+			report.Unchanged++
+		}
+		if item.Action != models.ImportUnchanged {
+			writes = append(writes, api)
+			written = append(written, len(report.Items))
+		}
+		report.Items = append(report.Items, item)
+	}
+	if err := v.Err(); err != nil {
+		return models.APIImportReport{}, err
+	}
+	if options.DryRun || len(writes) == 0 {
+		return report, nil
+	}
+
+	ids, err := s.repo.ImportAPIs(ctx, writes, actorID(ctx))
+	var batchErr *repository.BatchError
+	if errors.As(err, &batchErr) && errors.Is(err, repository.ErrUnknownTeam) {
+		v.Add(fmt.Sprintf("[%d].team_id", report.Items[written[batchErr.Index]].Index), "does not exist")
+		return models.APIImportReport{}, v.Err()
+	}
+	if err != nil {
+		return models.APIImportReport{}, fromRepository(err, "API")
+	}
+	for i, id := range ids {
+		report.Items[written[i]].ID = id
+	}
+
+	s.invalidateAPIs(ids...)
+
+	return report, nil
+}
+
+// checkImport checks one record the way CreateAPI or, when it replaces
+// stored, UpdateAPI would, adding invalid fields to v. Any other failure,
+// such as a caller who may not write the API, is returned.
+func (s *DefaultAPIService) checkImport(ctx context.Context, api models.API, stored *models.API,
+	v *utils.ValidationError, field func(string) string) error {
+	incoming := []models.API{api}
+	if stored != nil {
+		incoming = append(incoming, *stored)
+	}
+	if err := auth.AuthorizeAPIWrite(ctx, incoming...); err != nil {
+		return err
+	}
+
+	checks := []error{validateAPI(api)}
+	if stored != nil {
+		checks = append(checks, checkLifecycleTransition("lifecycle", stored.Lifecycle, api.Lifecycle))
+		if s.enforceSpecCompatibility {
+			checks = append(checks, checkSpecCompatibility(*stored, api))
+		}
+	}
+	for _, err := range checks {
+		var invalid *utils.ValidationError
+		switch {
+		case err == nil:
+		case errors.As(err, &invalid):
+			for _, f := range invalid.Fields {
+				v.Add(field(f.Field), f.Message)
+			}
+		default:
+			return err
+		}
+	}
+	return nil
+}
+
+// changedFields names the fields of models.API that an update from stored to
+// api replaces, as they are named in the JSON representation of an API.
+func changedFields(stored, api models.API) []string {
+	var changes []string
+	before, after := reflect.ValueOf(stored), reflect.ValueOf(api)
+	document := reflect.TypeOf(apiDocument{})
+	for i := 0; i < before.NumField(); i++ {
+		a, b := before.Field(i), after.Field(i)
+		switch name := before.Type().Field(i).Name; {
+		case name == "ID" || name == "CreatedAt" || name == "UpdatedAt" || name == "RowVersion":
+		case a.Kind() == reflect.Slice && a.Len() == 0 && b.Len() == 0:
+		case name == "Tags" && sameTags(stored.Tags, api.Tags):
+		case !reflect.DeepEqual(a.Interface(), b.Interface()):
+			if field, ok := document.FieldByName(name); ok {
+				name = field.Tag.Get("json")
+			}
+			changes = append(changes, name)
+		}
+	}
+	return changes
+}
+
+// sameTags reports whether two tag lists name the same tags. Tag names are
+// unique regardless of case, so a change of case alone replaces nothing.
+func sameTags(a, b []string) bool {
+	a, b = normalizeTags(a), normalizeTags(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !strings.EqualFold(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"fmt"
+	"microd-api/internal/models"
+	"microd-api/internal/utils"
+	"strings"
+	"time"
+)
+
+// lifecycleTransitions lists the states each lifecycle state may move to.
+// Retired is final, and a stable API has to be deprecated before it can be
+// retired so that consumers get a warning first.
+var lifecycleTransitions = map[string][]string{
+	models.LifecycleExperimental: {models.LifecycleStable, models.LifecycleRetired},
+	models.LifecycleStable:       {models.LifecycleDeprecated},
+	models.LifecycleDeprecated:   {models.LifecycleStable, models.LifecycleRetired},
+	models.LifecycleRetired:      {},
+}
+
+func validLifecycle(lifecycle string) bool {
+	_, ok := lifecycleTransitions[lifecycle]
+	return ok
+}
+
+// checkLifecycleTransition reports a move between lifecycle states that is
+// not allowed as a validation error on field.
+func checkLifecycleTransition(field, from, to string) error {
+	if from == to || !validLifecycle(from) {
+		return nil
+	}
+	allowed := lifecycleTransitions[from]
+	for _, next := range allowed {
+		if next == to {
+			return nil
+		}
+	}
+
+	var v utils.ValidationError
+	if len(allowed) == 0 {
+		v.Add(field, fmt.Sprintf("cannot change from %s because it is final", from))
+	} else {
+		v.Add(field, fmt.Sprintf("cannot change from %s to %s; allowed: %s", from, to, strings.Join(allowed, ", ")))
+	}
+	return v.Err()
+}
+
+// applyLifecycle fills in the lifecycle of an API being written. A new API is
+// stable unless it says otherwise, and an update that leaves Lifecycle out
+// keeps the stored lifecycle and dates. A deprecated or retired API without a
+// deprecation date keeps the stored one or is dated now, and a retired API
+// without a sunset date keeps a stored one that has passed or is dated now.
+// Returning to experimental or stable clears both dates.
+func applyLifecycle(api models.API, existing *models.API, now time.Time) models.API {
+	if api.Lifecycle == "" && existing != nil {
+		api.Lifecycle = existing.Lifecycle
+		api.DeprecatedAt = existing.DeprecatedAt
+		api.SunsetAt = existing.SunsetAt
+	}
+	if api.Lifecycle == "" {
+		api.Lifecycle = models.LifecycleStable
+	}
+
+	switch api.Lifecycle {
+	case models.LifecycleExperimental, models.LifecycleStable:
+		api.DeprecatedAt = time.Time{}
+		api.SunsetAt = time.Time{}
+	case models.LifecycleDeprecated, models.LifecycleRetired:
+		if api.DeprecatedAt.IsZero() {
+			api.DeprecatedAt = now
+			if existing != nil && !existing.DeprecatedAt.IsZero() {
+				api.DeprecatedAt = existing.DeprecatedAt
+			}
+		}
+		if api.Lifecycle == models.LifecycleRetired && api.SunsetAt.IsZero() {
+			api.SunsetAt = now
+			if existing != nil && !existing.SunsetAt.IsZero() && existing.SunsetAt.Before(now) {
+				api.SunsetAt = existing.SunsetAt
+			}
+		}
+	}
+	return api
+}
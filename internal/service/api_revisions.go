@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+)
+
+func (s *DefaultAPIService) ListAPIRevisions(ctx context.Context, id int64) ([]models.APIRevision, error) {
+	revisions, err := s.repo.ListAPIRevisions(ctx, id)
+	if err != nil {
+		return nil, fromRepository(err, "API")
+	}
+	return revisions, nil
+}
+
+func (s *DefaultAPIService) GetAPIRevision(ctx context.Context, id, revision int64) (models.APIRevision, error) {
+	rev, err := s.repo.GetAPIRevision(ctx, id, revision)
+	if err != nil {
+		return models.APIRevision{}, fromRepository(err, "revision")
+	}
+	return rev, nil
+}
+
+// RestoreAPIRevision rolls an API back to an earlier revision, recreating it
+// if it has been deleted. The caller must be allowed to manage both the API as
+// it is now and as it was in that revision, and the snapshot must pass the
+// same checks as an update of the current row, which may be in the trash.
+func (s *DefaultAPIService) RestoreAPIRevision(ctx context.Context, id, revision int64) (models.API, error) {
+	if _, err := auth.RequireUser(ctx); err != nil {
+		return models.API{}, err
+	}
+
+	rev, err := s.repo.GetAPIRevision(ctx, id, revision)
+	if err != nil {
+		return models.API{}, fromRepository(err, "revision")
+	}
+
+	existing, found, err := s.currentAPI(ctx, id)
+	if err != nil {
+		return models.API{}, err
+	}
+	affected := []models.API{rev.Snapshot}
+	if found {
+		affected = append(affected, existing)
+	}
+	if err := auth.AuthorizeAPIWrite(ctx, affected...); err != nil {
+		return models.API{}, err
+	}
+
+	// The repository restores a snapshot taken before lifecycles existed as
+	// stable, so it is checked as one.
+	snapshot := rev.Snapshot
+	if snapshot.Lifecycle == "" {
+		snapshot.Lifecycle = models.LifecycleStable
+	}
+	if err := validateAPI(snapshot); err != nil {
+		return models.API{}, err
+	}
+	if found {
+		if err := checkLifecycleTransition("lifecycle", existing.Lifecycle, snapshot.Lifecycle); err != nil {
+			return models.API{}, err
+		}
+		if s.enforceSpecCompatibility {
+			if err := checkSpecCompatibility(existing, snapshot); err != nil {
+				return models.API{}, err
+			}
+		}
+	}
+
+	api, err := s.repo.RestoreAPIRevision(ctx, id, revision, actorID(ctx))
+	if err != nil {
+		return models.API{}, fromRepository(err, "revision")
+	}
+
+	s.invalidateAPIs(id)
+
+	return api, nil
+}
+
+// currentAPI returns the stored row of an API, looking in the trash when it
+// has been deleted. found is false once the API has been purged.
+func (s *DefaultAPIService) currentAPI(ctx context.Context, id int64) (api models.API, found bool, err error) {
+	api, err = s.repo.GetAPIByID(ctx, id)
+	if err == nil {
+		return api, true, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return models.API{}, false, fromRepository(err, "API")
+	}
+	deleted, err := s.repo.GetDeletedAPI(ctx, id)
+	if err == nil {
+		return deleted.API, true, nil
+	}
+	if !errors.Is(err, repository.ErrNotFound) {
+		return models.API{}, false, fromRepository(err, "API")
+	}
+	return models.API{}, false, nil
+}
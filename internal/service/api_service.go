@@ -3,38 +3,118 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"microd-api/internal/auth"
 	"microd-api/internal/cache"
 	"microd-api/internal/models"
 	"microd-api/internal/repository"
+	"microd-api/internal/utils"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
+const (
+	DefaultPageSize   = 50
+	MaxPageSize       = 200
+	DefaultSearchSize = 20
+	MaxSearchSize     = 100
+)
+
+var ErrInvalidFilter error = utils.NewError(utils.ErrBadRequest, "invalid list query")
+
 type DefaultAPIService struct {
 	repo  repository.APIRepository
-	cache *cache.Cache
+	cache cache.Store
+
+	enforceSpecCompatibility bool
+	trashRetention           time.Duration
+}
+
+type APIServiceOption func(*DefaultAPIService)
+
+// WithSpecCompatibilityCheck makes UpdateAPI reject OpenAPI documents with
+// breaking changes unless the major version is increased as well.
+func WithSpecCompatibilityCheck(enabled bool) APIServiceOption {
+	return func(s *DefaultAPIService) {
+		s.enforceSpecCompatibility = enabled
+	}
 }
 
-func NewAPIService(repo repository.APIRepository) APIService {
-	return &DefaultAPIService{
-		repo:  repo,
-		cache: cache.NewCache(5 * time.Minute),
+// WithCache makes the service cache reads in store instead of the default
+// in-memory cache.
+func WithCache(store cache.Store) APIServiceOption {
+	return func(s *DefaultAPIService) {
+		s.cache = store
 	}
 }
 
+func NewAPIService(repo repository.APIRepository, opts ...APIServiceOption) APIService {
+	s := &DefaultAPIService{
+		repo:           repo,
+		trashRetention: DefaultTrashRetention,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.cache == nil {
+		s.cache = cache.NewCache(5 * time.Minute)
+	}
+	return s
+}
+
+// Cache keys. An API is cached under apiCacheKey, and listings and searches
+// under the prefixes, which a write to any API invalidates as a whole.
+const (
+	listCachePrefix   = "api:list:"
+	searchCachePrefix = "api:search:"
+)
+
+func apiCacheKey(id int64) string {
+	return "api:" + strconv.FormatInt(id, 10)
+}
+
+// invalidateAPIs drops the cached copies of the APIs ids and every cached
+// listing and search, which may include them.
+func (s *DefaultAPIService) invalidateAPIs(ids ...int64) {
+	for _, id := range ids {
+		s.cache.Delete(apiCacheKey(id))
+	}
+	s.cache.DeletePrefix(listCachePrefix)
+	s.InvalidateSearches()
+}
+
+// InvalidateSearches drops every cached search. Other services call it when
+// they change data the search index covers, such as the name of a team.
+func (s *DefaultAPIService) InvalidateSearches() {
+	s.cache.DeletePrefix(searchCachePrefix)
+}
+
 func (s *DefaultAPIService) CreateAPI(ctx context.Context, api models.API) (int64, error) {
-	id, err := s.repo.CreateAPI(ctx, api)
-	if err != nil {
+	api = normalizeAPI(api)
+	if err := auth.AuthorizeAPIWrite(ctx, api); err != nil {
 		return 0, err
 	}
+	api = applyLifecycle(api, nil, time.Now().UTC().Truncate(time.Second))
+	if err := validateAPI(api); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.CreateAPI(ctx, api, actorID(ctx))
+	if err != nil {
+		return 0, fromAPIWrite(err)
+	}
 
-	s.cache.Clear()
+	s.invalidateAPIs()
 
 	return id, nil
 }
 
 func (s *DefaultAPIService) GetAPIByID(ctx context.Context, id int64) (models.API, error) {
-	cacheKey := fmt.Sprintf("api:%d", id)
+	cacheKey := apiCacheKey(id)
 
 	if cachedData, ok := s.cache.Get(cacheKey); ok {
 		var api models.API
@@ -46,7 +126,7 @@ func (s *DefaultAPIService) GetAPIByID(ctx context.Context, id int64) (models.AP
 
 	api, err := s.repo.GetAPIByID(ctx, id)
 	if err != nil {
-		return models.API{}, err
+		return models.API{}, fromRepository(err, "API")
 	}
 
 	cachedData, _ := json.Marshal(api)
@@ -55,50 +135,263 @@ func (s *DefaultAPIService) GetAPIByID(ctx context.Context, id int64) (models.AP
 	return api, nil
 }
 
-func (s *DefaultAPIService) UpdateAPI(ctx context.Context, api models.API) error {
-	err := s.repo.UpdateAPI(ctx, api)
+// UpdateAPI replaces an API. When ifMatch is given the API must match it
+// and must not change before it is written.
+func (s *DefaultAPIService) UpdateAPI(ctx context.Context, api models.API, ifMatch *models.IfMatch) error {
+	api = normalizeAPI(api)
+	existing, err := s.authorizeExisting(ctx, api.ID, api)
+	if err != nil {
+		return err
+	}
+	api.RowVersion, err = checkIfMatch(existing, ifMatch)
 	if err != nil {
 		return err
 	}
+	api = applyLifecycle(api, &existing, time.Now().UTC().Truncate(time.Second))
+	if err := validateAPI(api); err != nil {
+		return err
+	}
+	if err := checkLifecycleTransition("lifecycle", existing.Lifecycle, api.Lifecycle); err != nil {
+		return err
+	}
+	if s.enforceSpecCompatibility {
+		if err := checkSpecCompatibility(existing, api); err != nil {
+			return err
+		}
+	}
 
-	cacheKey := fmt.Sprintf("api:%d", api.ID)
-	s.cache.Clear()
+	err = s.repo.UpdateAPI(ctx, api, actorID(ctx))
+	if err != nil {
+		return fromAPIWrite(err)
+	}
 
-	cachedData, _ := json.Marshal(api)
-	s.cache.Set(cacheKey, cachedData)
+	s.invalidateAPIs(api.ID)
 
 	return nil
 }
 
-func (s *DefaultAPIService) DeleteAPI(ctx context.Context, id int64) error {
-	err := s.repo.DeleteAPI(ctx, id)
+// DeleteAPI moves an API to the trash. When ifMatch is given the API must
+// match it and must not change before it is deleted.
+func (s *DefaultAPIService) DeleteAPI(ctx context.Context, id int64, ifMatch *models.IfMatch) error {
+	existing, err := s.authorizeExisting(ctx, id)
+	if err != nil {
+		return err
+	}
+	version, err := checkIfMatch(existing, ifMatch)
 	if err != nil {
 		return err
 	}
 
-	s.cache.Clear()
+	err = s.repo.DeleteAPI(ctx, id, version, actorID(ctx))
+	if err != nil {
+		return fromRepository(err, "API")
+	}
+
+	s.invalidateAPIs(id)
 
 	return nil
 }
 
-func (s *DefaultAPIService) ListAPIs(ctx context.Context) ([]models.API, error) {
-	cacheKey := "api:list"
+// checkIfMatch checks the precondition of a write to existing. It returns
+// the row version the repository must find the API at when it writes, or
+// zero when the write is unconditional.
+func checkIfMatch(existing models.API, ifMatch *models.IfMatch) (int64, error) {
+	if ifMatch == nil {
+		return 0, nil
+	}
+	if !ifMatch.Matches(existing.RowVersion) {
+		return 0, errModified("API")
+	}
+	return existing.RowVersion, nil
+}
+
+func (s *DefaultAPIService) ListAPIs(ctx context.Context, filter models.APIFilter) (models.APIPage, error) {
+	filter, err := normalizeFilter(filter)
+	if err != nil {
+		return models.APIPage{}, err
+	}
+
+	// Category mappings can change through the category service, which does
+	// not share this cache, so filtered listings always hit the repository.
+	if filter.CategoryID != 0 {
+		return s.listAPIs(ctx, filter)
+	}
+
+	cacheKey := listCacheKey(filter)
 
 	if cachedData, ok := s.cache.Get(cacheKey); ok {
-		var apis []models.API
-		err := json.Unmarshal(cachedData, &apis)
+		var page models.APIPage
+		err := json.Unmarshal(cachedData, &page)
 		if err == nil {
-			return apis, nil
+			return page, nil
 		}
 	}
 
-	apis, err := s.repo.ListAPIs(ctx)
+	page, err := s.listAPIs(ctx, filter)
 	if err != nil {
-		return nil, err
+		return models.APIPage{}, err
 	}
 
-	cachedData, _ := json.Marshal(apis)
+	cachedData, _ := json.Marshal(page)
 	s.cache.Set(cacheKey, cachedData)
 
-	return apis, nil
+	return page, nil
+}
+
+func (s *DefaultAPIService) listAPIs(ctx context.Context, filter models.APIFilter) (models.APIPage, error) {
+	page, err := s.repo.ListAPIs(ctx, filter)
+	if errors.Is(err, repository.ErrInvalidCursor) || errors.Is(err, repository.ErrInvalidSort) {
+		return models.APIPage{}, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	return page, err
+}
+
+func normalizeFilter(filter models.APIFilter) (models.APIFilter, error) {
+	switch {
+	case filter.Limit == 0:
+		filter.Limit = DefaultPageSize
+	case filter.Limit < 0 || filter.Limit > MaxPageSize:
+		return filter, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, MaxPageSize)
+	}
+
+	switch strings.TrimPrefix(filter.Sort, "-") {
+	case "", "name", "created_at", "updated_at":
+	default:
+		return filter, fmt.Errorf("%w: sort must be one of name, created_at, updated_at", ErrInvalidFilter)
+	}
+
+	filter.Version = strings.TrimSpace(filter.Version)
+
+	filter.Lifecycle = strings.ToLower(strings.TrimSpace(filter.Lifecycle))
+	if filter.Lifecycle != "" && !validLifecycle(filter.Lifecycle) {
+		return filter, fmt.Errorf("%w: lifecycle must be one of experimental, stable, deprecated, retired", ErrInvalidFilter)
+	}
+
+	seen := make(map[string]bool)
+	var tags []string
+	for _, tag := range filter.Tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	sort.Strings(tags)
+	filter.Tags = tags
+
+	return filter, nil
+}
+
+func listCacheKey(filter models.APIFilter) string {
+	values := url.Values{}
+	values.Set("limit", strconv.Itoa(filter.Limit))
+	if filter.Sort != "" {
+		values.Set("sort", filter.Sort)
+	}
+	if filter.Cursor != "" {
+		values.Set("cursor", filter.Cursor)
+	}
+	if filter.TeamID != 0 {
+		values.Set("team_id", strconv.FormatInt(filter.TeamID, 10))
+	}
+	if filter.Version != "" {
+		values.Set("version", filter.Version)
+	}
+	if filter.Lifecycle != "" {
+		values.Set("lifecycle", filter.Lifecycle)
+	}
+	for _, tag := range filter.Tags {
+		values.Add("tags", tag)
+	}
+	return listCachePrefix + values.Encode()
+}
+
+func (s *DefaultAPIService) SearchAPIs(ctx context.Context, q string, limit int) (models.APISearchPage, error) {
+	q = strings.TrimSpace(q)
+	if q == "" {
+		return models.APISearchPage{}, fmt.Errorf("%w: q is required", ErrInvalidFilter)
+	}
+	switch {
+	case limit == 0:
+		limit = DefaultSearchSize
+	case limit < 0 || limit > MaxSearchSize:
+		return models.APISearchPage{}, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidFilter, MaxSearchSize)
+	}
+
+	values := url.Values{}
+	values.Set("q", q)
+	values.Set("limit", strconv.Itoa(limit))
+	cacheKey := searchCachePrefix + values.Encode()
+
+	if cachedData, ok := s.cache.Get(cacheKey); ok {
+		var page models.APISearchPage
+		err := json.Unmarshal(cachedData, &page)
+		if err == nil {
+			return page, nil
+		}
+	}
+
+	page, err := s.repo.SearchAPIs(ctx, q, limit)
+	if errors.Is(err, repository.ErrInvalidSearchQuery) {
+		return models.APISearchPage{}, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+	if err != nil {
+		return models.APISearchPage{}, err
+	}
+
+	cachedData, _ := json.Marshal(page)
+	s.cache.Set(cacheKey, cachedData)
+
+	return page, nil
+}
+
+func (s *DefaultAPIService) AttachCategory(ctx context.Context, apiID, categoryID int64) error {
+	if _, err := s.authorizeExisting(ctx, apiID); err != nil {
+		return err
+	}
+
+	return fromRepository(s.repo.AttachCategory(ctx, apiID, categoryID), "category")
+}
+
+func (s *DefaultAPIService) DetachCategory(ctx context.Context, apiID, categoryID int64) error {
+	if _, err := s.authorizeExisting(ctx, apiID); err != nil {
+		return err
+	}
+
+	return fromRepository(s.repo.DetachCategory(ctx, apiID, categoryID), "category")
+}
+
+func (s *DefaultAPIService) ListAPICategories(ctx context.Context, apiID int64) ([]models.APICategory, error) {
+	if _, err := s.repo.GetAPIByID(ctx, apiID); err != nil {
+		return nil, fromRepository(err, "API")
+	}
+
+	return s.repo.ListAPICategories(ctx, apiID)
+}
+
+// actorID returns the ID of the user making a request, which the repository
+// records as the author of the revisions it writes, or zero without a user.
+func actorID(ctx context.Context) int64 {
+	user, _ := auth.UserFromContext(ctx)
+	return user.ID
+}
+
+// authorizeExisting checks the caller against the stored API before any other
+// lookup, so unauthenticated callers learn nothing about which IDs exist. The
+// stored API is returned once the caller may write to it.
+func (s *DefaultAPIService) authorizeExisting(ctx context.Context, id int64, incoming ...models.API) (models.API, error) {
+	if _, err := auth.RequireUser(ctx); err != nil {
+		return models.API{}, err
+	}
+
+	existing, err := s.repo.GetAPIByID(ctx, id)
+	if err != nil {
+		return models.API{}, fromRepository(err, "API")
+	}
+
+	if err := auth.AuthorizeAPIWrite(ctx, append([]models.API{existing}, incoming...)...); err != nil {
+		return models.API{}, err
+	}
+	return existing, nil
 }
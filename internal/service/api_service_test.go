@@ -2,9 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"microd-api/internal/auth"
 	"microd-api/internal/cache"
+	"microd-api/internal/catalog"
+	"microd-api/internal/jsonpatch"
 	"microd-api/internal/mocks"
 	"microd-api/internal/models"
+	"microd-api/internal/utils"
 	"reflect"
 	"testing"
 	"time"
@@ -13,19 +19,19 @@ import (
 func TestAPIService(t *testing.T) {
 	mockRepo := mocks.NewMockAPIRepository()
 	service := NewAPIService(mockRepo)
-	ctx := context.Background()
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
 
 	t.Run("CreateAPI", func(t *testing.T) {
 		api := models.API{
 			Name:              "Test API",
-			Version:           "1.0",
+			Version:           "1.0.0",
 			Description:       "Test Description",
 			DocumentationLink: "http://docs.example.com",
 			ForumReference:    "http://forum.example.com",
 			ApmLink:           "http://apm.example.com",
-			Team:              "Test Team",
-			Tags:              "test,api",
-			Swagger:           "http://swagger.example.com",
+			TeamID:            1,
+			Tags:              []string{"test", "api"},
+			Swagger:           `{"openapi": "3.0.0", "info": {"title": "Test API", "version": "1.0.0"}, "paths": {}}`,
 		}
 
 		id, err := service.CreateAPI(ctx, api)
@@ -56,7 +62,7 @@ func TestAPIService(t *testing.T) {
 
 		modifiedAPI := api
 		modifiedAPI.Name = "Modified API"
-		mockRepo.UpdateAPI(ctx, modifiedAPI)
+		mockRepo.UpdateAPI(ctx, modifiedAPI, 0)
 
 		cachedAPI, err = service.GetAPIByID(ctx, 1)
 		if err != nil {
@@ -71,17 +77,17 @@ func TestAPIService(t *testing.T) {
 		api := models.API{
 			ID:                1,
 			Name:              "Updated API",
-			Version:           "2.0",
+			Version:           "2.0.0",
 			Description:       "Updated Description",
 			DocumentationLink: "http://updated-docs.example.com",
 			ForumReference:    "http://updated-forum.example.com",
 			ApmLink:           "http://updated-apm.example.com",
-			Team:              "Updated Team",
-			Tags:              "updated,api",
-			Swagger:           "http://updated-swagger.example.com",
+			TeamID:            2,
+			Tags:              []string{"updated", "api"},
+			Swagger:           "swagger: '2.0'\ninfo: {title: Updated API, version: 2.0.0}\npaths: {}\n",
 		}
 
-		err := service.UpdateAPI(ctx, api)
+		err := service.UpdateAPI(ctx, api, nil)
 		if err != nil {
 			t.Fatalf("error updating API: %v", err)
 		}
@@ -96,15 +102,15 @@ func TestAPIService(t *testing.T) {
 	})
 
 	t.Run("ListAPIs", func(t *testing.T) {
-		apis, err := service.ListAPIs(ctx)
+		apis, err := service.ListAPIs(ctx, models.APIFilter{})
 		if err != nil {
 			t.Fatalf("error listing APIs: %v", err)
 		}
-		if len(apis) != 1 {
-			t.Errorf("expected 1 API, got %d", len(apis))
+		if len(apis.Items) != 1 {
+			t.Errorf("expected 1 API, got %d", len(apis.Items))
 		}
 
-		cachedAPIs, err := service.ListAPIs(ctx)
+		cachedAPIs, err := service.ListAPIs(ctx, models.APIFilter{})
 		if err != nil {
 			t.Fatalf("error listing APIs from cache: %v", err)
 		}
@@ -118,32 +124,79 @@ func TestAPIService(t *testing.T) {
 			t.Fatalf("error creating new API: %v", err)
 		}
 
-		updatedAPIs, err := service.ListAPIs(ctx)
+		updatedAPIs, err := service.ListAPIs(ctx, models.APIFilter{})
 		if err != nil {
 			t.Fatalf("error listing updated APIs: %v", err)
 		}
-		if len(updatedAPIs) != 2 {
-			t.Errorf("expected 2 APIs after adding new one, got %d", len(updatedAPIs))
+		if len(updatedAPIs.Items) != 2 {
+			t.Errorf("expected 2 APIs after adding new one, got %d", len(updatedAPIs.Items))
+		}
+	})
+
+	t.Run("CategoryMappings", func(t *testing.T) {
+		err := service.AttachCategory(ctx, 1, 7)
+		if err != nil {
+			t.Fatalf("error attaching category: %v", err)
+		}
+
+		categories, err := service.ListAPICategories(ctx, 1)
+		if err != nil {
+			t.Fatalf("error listing API categories: %v", err)
+		}
+		if len(categories) != 1 || categories[0].ID != 7 {
+			t.Errorf("expected API to have category 7, got %v", categories)
+		}
+
+		apis, err := service.ListAPIs(ctx, models.APIFilter{CategoryID: 7})
+		if err != nil {
+			t.Fatalf("error listing APIs by category: %v", err)
+		}
+		if len(apis.Items) != 1 || apis.Items[0].ID != 1 {
+			t.Errorf("expected only API 1 in category 7, got %v", apis.Items)
+		}
+
+		err = service.DetachCategory(ctx, 1, 7)
+		if err != nil {
+			t.Fatalf("error detaching category: %v", err)
+		}
+
+		apis, err = service.ListAPIs(ctx, models.APIFilter{CategoryID: 7})
+		if err != nil {
+			t.Fatalf("error listing APIs by category: %v", err)
+		}
+		if len(apis.Items) != 0 {
+			t.Errorf("expected no APIs in category 7 after detaching, got %d", len(apis.Items))
+		}
+
+		_, err = service.ListAPICategories(ctx, 999)
+		if err == nil {
+			t.Error("expected error listing categories of unknown API, got nil")
 		}
 	})
 
 	t.Run("DeleteAPI", func(t *testing.T) {
-		err := service.DeleteAPI(ctx, 1)
+		err := service.DeleteAPI(ctx, 1, nil)
 		if err != nil {
 			t.Fatalf("error deleting API: %v", err)
 		}
 
 		_, err = service.GetAPIByID(ctx, 1)
-		if err == nil {
-			t.Error("expected error when fetching deleted API, got nil")
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound when fetching deleted API, got %v", err)
+		}
+		if err := service.DeleteAPI(ctx, 1, nil); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound deleting a missing API, got %v", err)
+		}
+		if err := service.UpdateAPI(ctx, models.API{ID: 1, Name: "Ghost"}, nil); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound updating a missing API, got %v", err)
 		}
 
-		apis, err := service.ListAPIs(ctx)
+		apis, err := service.ListAPIs(ctx, models.APIFilter{})
 		if err != nil {
 			t.Fatalf("error listing APIs after deletion: %v", err)
 		}
-		if len(apis) != 1 {
-			t.Errorf("expected 1 API after deletion, got %d", len(apis))
+		if len(apis.Items) != 1 {
+			t.Errorf("expected 1 API after deletion, got %d", len(apis.Items))
 		}
 	})
 
@@ -155,7 +208,7 @@ func TestAPIService(t *testing.T) {
 			cache: shortCache,
 		}
 
-		ctx := context.Background()
+		ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
 
 		api := models.API{Name: "Expiring API"}
 		id, err := shortCacheService.CreateAPI(ctx, api)
@@ -173,7 +226,7 @@ func TestAPIService(t *testing.T) {
 
 		modifiedAPI := fetchedAPI
 		modifiedAPI.Name = "Modified Expiring API"
-		err = mockRepo.UpdateAPI(ctx, modifiedAPI)
+		err = mockRepo.UpdateAPI(ctx, modifiedAPI, 0)
 		if err != nil {
 			t.Fatalf("error updating API in repository: %v", err)
 		}
@@ -192,4 +245,1170 @@ func TestAPIService(t *testing.T) {
 			t.Errorf("refetched API should be different from originally fetched API after cache expiration")
 		}
 	})
+
+	t.Run("CustomStore", func(t *testing.T) {
+		mockRepo := mocks.NewMockAPIRepository()
+		store := cache.NewLRU(1<<20, time.Minute)
+		lruService := NewAPIService(mockRepo, WithCache(store))
+		ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+		id, _ := lruService.CreateAPI(ctx, models.API{Name: "Cached API"})
+		if _, err := lruService.GetAPIByID(ctx, id); err != nil {
+			t.Fatalf("error fetching API: %v", err)
+		}
+		if _, ok := store.Get(fmt.Sprintf("api:%d", id)); !ok {
+			t.Errorf("expected the API to be cached in the configured store")
+		}
+	})
+
+	t.Run("TargetedInvalidation", func(t *testing.T) {
+		mockRepo := mocks.NewMockAPIRepository()
+		store := cache.NewLRU(1<<20, time.Minute)
+		lruService := NewAPIService(mockRepo, WithCache(store))
+		ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+		ledger, _ := lruService.CreateAPI(ctx, models.API{Name: "Ledger"})
+		login, _ := lruService.CreateAPI(ctx, models.API{Name: "Login"})
+		lruService.GetAPIByID(ctx, ledger)
+		lruService.GetAPIByID(ctx, login)
+		lruService.ListAPIs(ctx, models.APIFilter{})
+		lruService.SearchAPIs(ctx, "ledger", 0)
+		if store.Len() != 4 {
+			t.Fatalf("expected 4 cached entries, got %d", store.Len())
+		}
+
+		api, _ := lruService.GetAPIByID(ctx, ledger)
+		api.Description = "Double-entry books"
+		if err := lruService.UpdateAPI(ctx, api, nil); err != nil {
+			t.Fatalf("error updating API: %v", err)
+		}
+
+		if _, ok := store.Get(fmt.Sprintf("api:%d", ledger)); ok {
+			t.Errorf("expected the updated API to be invalidated")
+		}
+		if _, ok := store.Get(fmt.Sprintf("api:%d", login)); !ok {
+			t.Errorf("expected other APIs to stay cached")
+		}
+		if store.Len() != 1 {
+			t.Errorf("expected listings and searches to be invalidated, %d entries remain", store.Len())
+		}
+		if stats := store.Stats(); stats.Hits == 0 {
+			t.Errorf("expected cache hits, got %+v", stats)
+		}
+	})
+}
+
+func TestAPIServiceAuthorization(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	ctx := context.Background()
+
+	admin := auth.WithUser(ctx, models.User{ID: 1, Role: models.RoleAdmin})
+	payments := auth.WithUser(ctx, models.User{ID: 2, TeamID: 1})
+	identity := auth.WithUser(ctx, models.User{ID: 3, TeamID: 2})
+	teamless := auth.WithUser(ctx, models.User{ID: 4})
+
+	id, err := service.CreateAPI(admin, models.API{Name: "Ledger", TeamID: 1})
+	if err != nil {
+		t.Fatalf("error creating API as admin: %v", err)
+	}
+
+	t.Run("CreateRequiresAuthentication", func(t *testing.T) {
+		_, err := service.CreateAPI(ctx, models.API{Name: "Anonymous", TeamID: 1})
+		if !errors.Is(err, auth.ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("CreateForOwnTeam", func(t *testing.T) {
+		if _, err := service.CreateAPI(payments, models.API{Name: "Payouts", TeamID: 1}); err != nil {
+			t.Errorf("expected team member to create API for own team, got %v", err)
+		}
+		if _, err := service.CreateAPI(payments, models.API{Name: "Logins", TeamID: 2}); !errors.Is(err, auth.ErrForbidden) {
+			t.Errorf("expected ErrForbidden creating API for another team, got %v", err)
+		}
+		if _, err := service.CreateAPI(teamless, models.API{Name: "Orphan"}); !errors.Is(err, auth.ErrForbidden) {
+			t.Errorf("expected ErrForbidden for user without team, got %v", err)
+		}
+	})
+
+	t.Run("UpdateRequiresOwnership", func(t *testing.T) {
+		err := service.UpdateAPI(identity, models.API{ID: id, Name: "Ledger", TeamID: 2}, nil)
+		if !errors.Is(err, auth.ErrForbidden) {
+			t.Errorf("expected ErrForbidden updating another team's API, got %v", err)
+		}
+
+		err = service.UpdateAPI(payments, models.API{ID: id, Name: "Ledger", TeamID: 2}, nil)
+		if !errors.Is(err, auth.ErrForbidden) {
+			t.Errorf("expected ErrForbidden moving API to a team the user is not in, got %v", err)
+		}
+
+		err = service.UpdateAPI(payments, models.API{ID: id, Name: "Ledger v2", TeamID: 1}, nil)
+		if err != nil {
+			t.Errorf("expected team member to update API, got %v", err)
+		}
+	})
+
+	t.Run("DeleteRequiresOwnership", func(t *testing.T) {
+		if err := service.DeleteAPI(ctx, id, nil); !errors.Is(err, auth.ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+		if err := service.DeleteAPI(identity, id, nil); !errors.Is(err, auth.ErrForbidden) {
+			t.Errorf("expected ErrForbidden deleting another team's API, got %v", err)
+		}
+		if err := service.DeleteAPI(payments, id, nil); err != nil {
+			t.Errorf("expected team member to delete API, got %v", err)
+		}
+	})
+
+	t.Run("ReadsStayOpen", func(t *testing.T) {
+		if _, err := service.ListAPIs(identity, models.APIFilter{}); err != nil {
+			t.Errorf("expected any user to list APIs, got %v", err)
+		}
+	})
+}
+
+func TestAPIServiceListQueries(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	for _, api := range []models.API{
+		{Name: "Ledger", TeamID: 1, Tags: []string{"billing", "public"}},
+		{Name: "Login", TeamID: 2, Tags: []string{"auth"}},
+		{Name: "Payouts", TeamID: 1, Tags: []string{"billing"}},
+	} {
+		if _, err := service.CreateAPI(ctx, api); err != nil {
+			t.Fatalf("error creating API: %v", err)
+		}
+	}
+
+	t.Run("DefaultLimit", func(t *testing.T) {
+		page, err := service.ListAPIs(ctx, models.APIFilter{})
+		if err != nil {
+			t.Fatalf("error listing APIs: %v", err)
+		}
+		if len(page.Items) != 3 || page.NextCursor != "" {
+			t.Errorf("expected a single page of 3 APIs, got %d (next %q)", len(page.Items), page.NextCursor)
+		}
+	})
+
+	t.Run("InvalidQueries", func(t *testing.T) {
+		for _, filter := range []models.APIFilter{
+			{Limit: MaxPageSize + 1},
+			{Limit: -1},
+			{Sort: "description"},
+			{Cursor: "garbage"},
+		} {
+			if _, err := service.ListAPIs(ctx, filter); !errors.Is(err, ErrInvalidFilter) {
+				t.Errorf("expected ErrInvalidFilter for %+v, got %v", filter, err)
+			}
+		}
+	})
+
+	t.Run("CacheKeyedPerQuery", func(t *testing.T) {
+		payments, err := service.ListAPIs(ctx, models.APIFilter{TeamID: 1})
+		if err != nil {
+			t.Fatalf("error listing APIs: %v", err)
+		}
+		identity, err := service.ListAPIs(ctx, models.APIFilter{TeamID: 2})
+		if err != nil {
+			t.Fatalf("error listing APIs: %v", err)
+		}
+		if payments.Total != 2 || identity.Total != 1 {
+			t.Errorf("expected distinct cached results per team, got %d and %d", payments.Total, identity.Total)
+		}
+
+		first, _ := service.ListAPIs(ctx, models.APIFilter{Limit: 1})
+		second, _ := service.ListAPIs(ctx, models.APIFilter{Limit: 1, Cursor: first.NextCursor})
+		if len(second.Items) != 1 || second.Items[0].ID == first.Items[0].ID {
+			t.Errorf("expected second page to differ from first")
+		}
+	})
+
+	t.Run("EquivalentTagQueriesShareCacheKey", func(t *testing.T) {
+		a, _ := normalizeFilter(models.APIFilter{Tags: []string{"public", " billing", "public"}})
+		b, _ := normalizeFilter(models.APIFilter{Tags: []string{"billing", "public"}})
+		if listCacheKey(a) != listCacheKey(b) {
+			t.Errorf("expected equivalent tag filters to share a cache key: %q vs %q", listCacheKey(a), listCacheKey(b))
+		}
+	})
+}
+
+func TestAPIServiceSearch(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	if _, err := service.CreateAPI(ctx, models.API{Name: "Ledger", Description: "Payments bookkeeping"}); err != nil {
+		t.Fatalf("error creating API: %v", err)
+	}
+
+	page, err := service.SearchAPIs(ctx, "  ledger ", 0)
+	if err != nil {
+		t.Fatalf("error searching APIs: %v", err)
+	}
+	if page.Total != 1 {
+		t.Errorf("expected 1 result, got %d", page.Total)
+	}
+
+	if _, err := service.CreateAPI(ctx, models.API{Name: "Ledger Export"}); err != nil {
+		t.Fatalf("error creating API: %v", err)
+	}
+	page, _ = service.SearchAPIs(ctx, "ledger", 0)
+	if page.Total != 2 {
+		t.Errorf("expected writes to invalidate cached searches, got %d results", page.Total)
+	}
+
+	for _, tc := range []struct {
+		q     string
+		limit int
+	}{
+		{"", 0},
+		{"   ", 0},
+		{"ledger", MaxSearchSize + 1},
+		{"ledger", -1},
+	} {
+		if _, err := service.SearchAPIs(ctx, tc.q, tc.limit); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter for q=%q limit=%d, got %v", tc.q, tc.limit, err)
+		}
+	}
+}
+
+func TestAPIServiceSpec(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	ctx := context.Background()
+
+	spec := `
+openapi: 3.0.0
+info: {title: Orders, version: 1.0.0}
+servers: [{url: "https://orders.example.com"}]
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      responses: {'200': {description: ok}}
+`
+	withSpec, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Orders", Swagger: spec}, 0)
+	withoutSpec, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Legacy"}, 0)
+	broken, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Broken", Swagger: "http://swagger.example.com"}, 0)
+
+	doc, err := service.GetAPISpec(ctx, withSpec)
+	if err != nil {
+		t.Fatalf("error getting spec: %v", err)
+	}
+	if len(doc.Operations) != 1 || doc.Operations[0].OperationID != "listOrders" || doc.Servers[0].URL != "https://orders.example.com" {
+		t.Errorf("unexpected spec: %+v", doc)
+	}
+
+	if _, err := service.GetAPISpec(ctx, withoutSpec); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for API without spec, got %v", err)
+	}
+	if _, err := service.GetAPISpec(ctx, 999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown API, got %v", err)
+	}
+	if _, err := service.GetAPISpec(ctx, broken); !errors.Is(err, ErrConflict) {
+		t.Errorf("expected ErrConflict for unparseable stored spec, got %v", err)
+	}
+}
+
+func TestAPIServiceSpecCompatibility(t *testing.T) {
+	const v1 = `{"openapi": "3.0.0", "info": {"title": "Orders", "version": "1"}, "paths": {
+		"/orders": {"get": {"responses": {"200": {"description": "ok"}}}},
+		"/orders/{id}": {"get": {"parameters": [{"name": "id", "in": "path", "required": true}], "responses": {"200": {"description": "ok"}}}}}}`
+	const additive = `{"openapi": "3.0.0", "info": {"title": "Orders", "version": "1"}, "paths": {
+		"/orders": {"get": {"parameters": [{"name": "status", "in": "query"}], "responses": {"200": {"description": "ok"}}}},
+		"/orders/{id}": {"get": {"parameters": [{"name": "id", "in": "path", "required": true}], "responses": {"200": {"description": "ok"}}}}}}`
+	const breaking = `{"openapi": "3.0.0", "info": {"title": "Orders", "version": "1"}, "paths": {
+		"/orders": {"get": {"responses": {"200": {"description": "ok"}}}}}}`
+
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	t.Run("Diff", func(t *testing.T) {
+		mockRepo := mocks.NewMockAPIRepository()
+		service := NewAPIService(mockRepo)
+		id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Orders", Version: "1.0.0", Swagger: v1}, 0)
+
+		report, err := service.DiffAPISpec(ctx, id, breaking)
+		if err != nil {
+			t.Fatalf("error diffing spec: %v", err)
+		}
+		if !report.Breaking || len(report.Changes) != 1 || report.Changes[0].Path != "/orders/{id}" {
+			t.Errorf("unexpected report: %+v", report)
+		}
+
+		if _, err := service.DiffAPISpec(ctx, id, "not: [a spec"); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected ErrValidation for invalid candidate, got %v", err)
+		}
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		mockRepo := mocks.NewMockAPIRepository()
+		service := NewAPIService(mockRepo)
+		id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Orders", Version: "1.0.0", Swagger: v1}, 0)
+
+		if err := service.UpdateAPI(ctx, models.API{ID: id, Name: "Orders", Version: "1.1.0", Swagger: breaking}, nil); err != nil {
+			t.Errorf("expected breaking update to pass when the check is disabled, got %v", err)
+		}
+	})
+
+	t.Run("Enforced", func(t *testing.T) {
+		tests := []struct {
+			name        string
+			fromVersion string
+			toVersion   string
+			swagger     string
+			rejected    bool
+		}{
+			{"NonBreakingMinor", "1.0.0", "1.1.0", additive, false},
+			{"BreakingMinor", "1.0.0", "1.1.0", breaking, true},
+			{"BreakingSameVersion", "1.0.0", "1.0.0", breaking, true},
+			{"BreakingMajor", "1.0.0", "2.0.0", breaking, false},
+			{"BreakingInitialDevelopment", "0.3.0", "0.4.0", breaking, false},
+			{"BreakingWithoutPreviousVersion", "", "1.0.0", breaking, false},
+			{"UnchangedSpec", "1.0.0", "1.0.1", v1, false},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				mockRepo := mocks.NewMockAPIRepository()
+				service := NewAPIService(mockRepo, WithSpecCompatibilityCheck(true))
+				id, _ := mockRepo.CreateAPI(ctx, models.API{Name: "Orders", Version: tt.fromVersion, Swagger: v1}, 0)
+
+				err := service.UpdateAPI(ctx, models.API{ID: id, Name: "Orders", Version: tt.toVersion, Swagger: tt.swagger}, nil)
+				if !tt.rejected {
+					if err != nil {
+						t.Fatalf("expected update to pass, got %v", err)
+					}
+					return
+				}
+
+				var verr *utils.ValidationError
+				if !errors.As(err, &verr) || len(verr.Fields) != 1 || verr.Fields[0].Field != "version" {
+					t.Fatalf("expected version validation error, got %v", err)
+				}
+				stored, _ := mockRepo.GetAPIByID(ctx, id)
+				if stored.Swagger != v1 {
+					t.Errorf("expected rejected update to leave the stored spec unchanged")
+				}
+			})
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		mockRepo := mocks.NewMockAPIRepository()
+		service := NewAPIService(mockRepo, WithSpecCompatibilityCheck(true))
+		id, _ := service.CreateAPI(ctx, models.API{Name: "Orders", Version: "1.0.0", Swagger: breaking})
+		if err := service.UpdateAPI(ctx, models.API{ID: id, Name: "Orders", Version: "1.1.0", Swagger: v1}, nil); err != nil {
+			t.Fatalf("error updating API: %v", err)
+		}
+
+		_, err := service.RestoreAPIRevision(ctx, id, 1)
+		var verr *utils.ValidationError
+		if !errors.As(err, &verr) || verr.Fields[0].Field != "version" {
+			t.Fatalf("expected restoring a breaking spec without a major bump to be rejected, got %v", err)
+		}
+		if stored, _ := mockRepo.GetAPIByID(ctx, id); stored.Swagger != v1 {
+			t.Errorf("expected rejected restore to leave the stored spec unchanged")
+		}
+	})
+}
+
+func TestAPIServiceRevisions(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	base := context.Background()
+	payments := auth.WithUser(base, models.User{ID: 2, TeamID: 1})
+	identity := auth.WithUser(base, models.User{ID: 3, TeamID: 2})
+
+	id, err := service.CreateAPI(payments, models.API{Name: "Payouts", Version: "1.0.0", TeamID: 1})
+	if err != nil {
+		t.Fatalf("error creating API: %v", err)
+	}
+	if err := service.UpdateAPI(payments, models.API{ID: id, Name: "Payouts", Version: "1.1.0", TeamID: 1}, nil); err != nil {
+		t.Fatalf("error updating API: %v", err)
+	}
+
+	revisions, err := service.ListAPIRevisions(base, id)
+	if err != nil || len(revisions) != 2 || revisions[0].ActorID != 2 {
+		t.Fatalf("unexpected revisions: %+v, %v", revisions, err)
+	}
+	if _, err := service.ListAPIRevisions(base, 999); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown API, got %v", err)
+	}
+	if _, err := service.GetAPIRevision(base, id, 9); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for unknown revision, got %v", err)
+	}
+
+	t.Run("Authorization", func(t *testing.T) {
+		if _, err := service.RestoreAPIRevision(base, id, 1); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+		if _, err := service.RestoreAPIRevision(identity, id, 1); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for another team, got %v", err)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		// Prime the cache so that a stale entry would show up.
+		service.GetAPIByID(base, id)
+
+		api, err := service.RestoreAPIRevision(payments, id, 1)
+		if err != nil {
+			t.Fatalf("error restoring revision: %v", err)
+		}
+		if api.Version != "1.0.0" {
+			t.Errorf("expected version 1.0.0, got %s", api.Version)
+		}
+		if cached, _ := service.GetAPIByID(base, id); cached.Version != "1.0.0" {
+			t.Errorf("expected restore to invalidate the cache, got version %s", cached.Version)
+		}
+	})
+
+	t.Run("RestoreDeleted", func(t *testing.T) {
+		if err := service.DeleteAPI(payments, id, nil); err != nil {
+			t.Fatalf("error deleting API: %v", err)
+		}
+		if _, err := service.RestoreAPIRevision(identity, id, 2); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for another team, got %v", err)
+		}
+		api, err := service.RestoreAPIRevision(payments, id, 2)
+		if err != nil {
+			t.Fatalf("error restoring deleted API: %v", err)
+		}
+		if api.ID != id || api.Version != "1.1.0" {
+			t.Errorf("unexpected restored API: %+v", api)
+		}
+	})
+}
+
+func TestAPIServiceTrash(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo, WithTrashRetention(48*time.Hour))
+	base := context.Background()
+	payments := auth.WithUser(base, models.User{ID: 2, TeamID: 1})
+	identity := auth.WithUser(base, models.User{ID: 3, TeamID: 2})
+
+	id, _ := service.CreateAPI(payments, models.API{Name: "Payouts", TeamID: 1})
+	if err := service.DeleteAPI(payments, id, nil); err != nil {
+		t.Fatalf("error deleting API: %v", err)
+	}
+	if _, err := service.GetAPIByID(base, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected deleted API to be hidden, got %v", err)
+	}
+
+	t.Run("List", func(t *testing.T) {
+		trash, err := service.ListTrash(base)
+		if err != nil {
+			t.Fatalf("error listing trash: %v", err)
+		}
+		if len(trash) != 1 || !trash[0].PurgeAt.Equal(trash[0].DeletedAt.Add(48*time.Hour)) {
+			t.Errorf("unexpected trash: %+v", trash)
+		}
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		if _, err := service.RestoreFromTrash(identity, id); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for another team, got %v", err)
+		}
+		if _, err := service.RestoreFromTrash(payments, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+
+		api, err := service.RestoreFromTrash(payments, id)
+		if err != nil {
+			t.Fatalf("error restoring API: %v", err)
+		}
+		if api.ID != id {
+			t.Errorf("unexpected restored API: %+v", api)
+		}
+		live, err := service.GetAPIByID(base, id)
+		if err != nil {
+			t.Errorf("expected restored API to be visible, got %v", err)
+		}
+		if api.RowVersion != live.RowVersion {
+			t.Errorf("expected the restored row, got row version %d want %d", api.RowVersion, live.RowVersion)
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		expired, _ := service.CreateAPI(payments, models.API{Name: "Expired", TeamID: 1})
+		fresh, _ := service.CreateAPI(payments, models.API{Name: "Fresh", TeamID: 1})
+		service.DeleteAPI(payments, expired, nil)
+		service.DeleteAPI(payments, fresh, nil)
+		mockRepo.SetDeletedAt(expired, time.Now().Add(-72*time.Hour))
+
+		purged, err := service.PurgeTrash(base)
+		if err != nil {
+			t.Fatalf("error purging trash: %v", err)
+		}
+		if purged != 1 {
+			t.Errorf("expected 1 purged API, got %d", purged)
+		}
+		trash, _ := service.ListTrash(base)
+		if len(trash) != 1 || trash[0].API.ID != fresh {
+			t.Errorf("expected only the fresh API in the trash, got %+v", trash)
+		}
+	})
+}
+
+func TestAPIServiceVersions(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	base := context.Background()
+	payments := auth.WithUser(base, models.User{ID: 2, TeamID: 1})
+	identity := auth.WithUser(base, models.User{ID: 3, TeamID: 2})
+
+	id, _ := service.CreateAPI(payments, models.API{Name: "Payouts", TeamID: 1})
+	released := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Create", func(t *testing.T) {
+		for _, v := range []string{"1.2.0", "2.0.0-beta.1", "1.10.0", "0.9.0"} {
+			if _, err := service.CreateAPIVersion(payments, models.APIVersion{APIID: id, Version: v, ReleasedAt: released}); err != nil {
+				t.Fatalf("error creating version %s: %v", v, err)
+			}
+		}
+
+		created, err := service.CreateAPIVersion(payments, models.APIVersion{APIID: id, Version: " 1.11.0 ", Status: "Retired"})
+		if err != nil {
+			t.Fatalf("error creating version: %v", err)
+		}
+		if created.Version != "1.11.0" || created.Status != models.LifecycleRetired || created.ID == 0 {
+			t.Errorf("unexpected created version: %+v", created)
+		}
+
+		if _, err := service.CreateAPIVersion(payments, models.APIVersion{APIID: id, Version: "1.2.0"}); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict for a duplicate version, got %v", err)
+		}
+		if _, err := service.CreateAPIVersion(identity, models.APIVersion{APIID: id, Version: "3.0.0"}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for another team, got %v", err)
+		}
+		if _, err := service.CreateAPIVersion(base, models.APIVersion{APIID: id, Version: "3.0.0"}); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		_, err := service.CreateAPIVersion(payments, models.APIVersion{
+			APIID: id, Version: "v3", Status: "sunset", DocumentationLink: "docs", Swagger: "{}",
+		})
+		var verr *utils.ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected validation error, got %v", err)
+		}
+		fields := map[string]bool{}
+		for _, fe := range verr.Fields {
+			fields[fe.Field] = true
+		}
+		for _, field := range []string{"version", "status", "documentation_link", "swagger"} {
+			if !fields[field] {
+				t.Errorf("expected an error for %s, got %+v", field, verr.Fields)
+			}
+		}
+	})
+
+	t.Run("List", func(t *testing.T) {
+		list, err := service.ListAPIVersions(base, id)
+		if err != nil {
+			t.Fatalf("error listing versions: %v", err)
+		}
+		var got []string
+		for _, v := range list.Items {
+			got = append(got, v.Version)
+		}
+		want := []string{"2.0.0-beta.1", "1.11.0", "1.10.0", "1.2.0", "0.9.0"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("expected versions %v, got %v", want, got)
+		}
+		if list.Latest != "1.10.0" {
+			t.Errorf("expected latest to skip pre-releases and retired versions, got %q", list.Latest)
+		}
+
+		if _, err := service.ListAPIVersions(base, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("GetLatest", func(t *testing.T) {
+		v, err := service.GetAPIVersion(base, id, LatestVersion)
+		if err != nil {
+			t.Fatalf("error getting latest version: %v", err)
+		}
+		if v.Version != "1.10.0" {
+			t.Errorf("expected 1.10.0, got %+v", v)
+		}
+
+		empty, _ := service.CreateAPI(payments, models.API{Name: "Empty", TeamID: 1})
+		if _, err := service.GetAPIVersion(base, empty, LatestVersion); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an API without versions, got %v", err)
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		released := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+		v, err := service.UpdateAPIVersion(payments, models.APIVersion{APIID: id, Version: "1.10.0", Status: models.LifecycleDeprecated, ReleasedAt: released})
+		if err != nil {
+			t.Fatalf("error updating version: %v", err)
+		}
+		if v.Status != models.LifecycleDeprecated || !v.ReleasedAt.Equal(released) {
+			t.Errorf("unexpected updated version: %+v", v)
+		}
+		if _, err := service.UpdateAPIVersion(payments, models.APIVersion{APIID: id, Version: "5.0.0"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		if err := service.DeleteAPIVersion(identity, id, "1.10.0"); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for another team, got %v", err)
+		}
+		if err := service.DeleteAPIVersion(payments, id, "1.10.0"); err != nil {
+			t.Fatalf("error deleting version: %v", err)
+		}
+		list, _ := service.ListAPIVersions(base, id)
+		if list.Latest != "1.2.0" || len(list.Items) != 4 {
+			t.Errorf("expected latest to move back to 1.2.0, got %+v", list)
+		}
+	})
+
+	t.Run("Unreleased", func(t *testing.T) {
+		if _, err := service.CreateAPIVersion(payments, models.APIVersion{APIID: id, Version: "3.0.0"}); err != nil {
+			t.Fatalf("error creating version: %v", err)
+		}
+		scheduled := models.APIVersion{APIID: id, Version: "2.5.0", ReleasedAt: time.Now().Add(24 * time.Hour)}
+		if _, err := service.CreateAPIVersion(payments, scheduled); err != nil {
+			t.Fatalf("error creating version: %v", err)
+		}
+
+		list, _ := service.ListAPIVersions(base, id)
+		if list.Latest != "1.2.0" {
+			t.Errorf("expected latest to skip unreleased and scheduled versions, got %q", list.Latest)
+		}
+	})
+}
+
+func TestAPIServiceLifecycle(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	id, err := service.CreateAPI(ctx, models.API{Name: "Billing"})
+	if err != nil {
+		t.Fatalf("error creating API: %v", err)
+	}
+	api, _ := service.GetAPIByID(ctx, id)
+	if api.Lifecycle != models.LifecycleStable || !api.DeprecatedAt.IsZero() {
+		t.Errorf("expected a new API to be stable, got %+v", api)
+	}
+
+	update := func(lifecycle string, sunset time.Time) (models.API, error) {
+		err := service.UpdateAPI(ctx, models.API{ID: id, Name: "Billing", Lifecycle: lifecycle, SunsetAt: sunset}, nil)
+		api, _ := service.GetAPIByID(ctx, id)
+		return api, err
+	}
+
+	if _, err := update(models.LifecycleRetired, time.Time{}); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected stable -> retired to be rejected, got %v", err)
+	}
+
+	sunset := time.Now().Add(30 * 24 * time.Hour).UTC().Truncate(time.Second)
+	api, err = update(models.LifecycleDeprecated, sunset)
+	if err != nil {
+		t.Fatalf("error deprecating API: %v", err)
+	}
+	if api.Lifecycle != models.LifecycleDeprecated || api.DeprecatedAt.IsZero() || !api.SunsetAt.Equal(sunset) {
+		t.Errorf("expected a dated deprecation, got %+v", api)
+	}
+	deprecatedAt := api.DeprecatedAt
+
+	api, err = update("", time.Time{})
+	if err != nil {
+		t.Fatalf("error updating API: %v", err)
+	}
+	if api.Lifecycle != models.LifecycleDeprecated || !api.DeprecatedAt.Equal(deprecatedAt) || !api.SunsetAt.Equal(sunset) {
+		t.Errorf("expected an update without a lifecycle to keep it, got %+v", api)
+	}
+
+	page, _ := service.ListAPIs(ctx, models.APIFilter{Lifecycle: "Deprecated"})
+	if len(page.Items) != 1 || page.Items[0].ID != id {
+		t.Errorf("expected the deprecated API in the filtered list, got %+v", page.Items)
+	}
+	if _, err := service.ListAPIs(ctx, models.APIFilter{Lifecycle: "sunset"}); !errors.Is(err, ErrInvalidFilter) {
+		t.Errorf("expected ErrInvalidFilter for an unknown lifecycle, got %v", err)
+	}
+
+	api, err = update(models.LifecycleRetired, time.Time{})
+	if err != nil {
+		t.Fatalf("error retiring API: %v", err)
+	}
+	if !api.DeprecatedAt.Equal(deprecatedAt) || api.SunsetAt.IsZero() || api.SunsetAt.After(time.Now()) {
+		t.Errorf("expected retirement to keep the deprecation date and sunset now, got %+v", api)
+	}
+
+	_, err = update(models.LifecycleStable, time.Time{})
+	var verr *utils.ValidationError
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "lifecycle" {
+		t.Errorf("expected retired to be final, got %v", err)
+	}
+
+	_, err = service.RestoreAPIRevision(ctx, id, 1)
+	if !errors.As(err, &verr) || verr.Fields[0].Field != "lifecycle" {
+		t.Errorf("expected restoring a stable revision of a retired API to be rejected, got %v", err)
+	}
+	if api, _ := service.GetAPIByID(ctx, id); api.Lifecycle != models.LifecycleRetired {
+		t.Errorf("expected the rejected restore to leave the API retired, got %s", api.Lifecycle)
+	}
+}
+
+func TestCheckLifecycleTransition(t *testing.T) {
+	allowed := map[[2]string]bool{
+		{models.LifecycleExperimental, models.LifecycleStable}:  true,
+		{models.LifecycleExperimental, models.LifecycleRetired}: true,
+		{models.LifecycleStable, models.LifecycleDeprecated}:    true,
+		{models.LifecycleDeprecated, models.LifecycleStable}:    true,
+		{models.LifecycleDeprecated, models.LifecycleRetired}:   true,
+	}
+	states := []string{models.LifecycleExperimental, models.LifecycleStable, models.LifecycleDeprecated, models.LifecycleRetired}
+	for _, from := range states {
+		for _, to := range states {
+			err := checkLifecycleTransition("lifecycle", from, to)
+			if want := from == to || allowed[[2]string{from, to}]; (err == nil) != want {
+				t.Errorf("%s -> %s: got %v, want allowed=%t", from, to, err, want)
+			}
+		}
+	}
+}
+
+func TestAPIServiceTags(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	base := context.Background()
+	member := auth.WithUser(base, models.User{ID: 2, TeamID: 1})
+	admin := auth.WithUser(base, models.User{ID: 1, Role: models.RoleAdmin})
+
+	id, _ := service.CreateAPI(member, models.API{Name: "Payouts", TeamID: 1, Tags: []string{"Public", "payouts"}})
+	service.CreateAPI(member, models.API{Name: "Refunds", TeamID: 1, Tags: []string{"public"}})
+
+	t.Run("List", func(t *testing.T) {
+		tags, err := service.ListTags(base)
+		if err != nil {
+			t.Fatalf("error listing tags: %v", err)
+		}
+		if len(tags) != 2 || tags[0].APICount != 2 || tags[1] != (models.Tag{Name: "payouts", APICount: 1}) {
+			t.Errorf("unexpected tags: %+v", tags)
+		}
+	})
+
+	t.Run("RequiresAdmin", func(t *testing.T) {
+		if err := service.RenameTag(member, "public", "external"); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden renaming as a member, got %v", err)
+		}
+		if err := service.MergeTags(base, "payouts", "public"); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated merging anonymously, got %v", err)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		var verr *utils.ValidationError
+		if err := service.RenameTag(admin, "public", "  "); !errors.As(err, &verr) || verr.Fields[0].Field != "name" {
+			t.Errorf("expected validation error on name, got %v", err)
+		}
+		if err := service.MergeTags(admin, "public", "#hash"); !errors.As(err, &verr) || verr.Fields[0].Field != "into" {
+			t.Errorf("expected validation error on into, got %v", err)
+		}
+		if err := service.RenameTag(admin, "missing", "other"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if err := service.RenameTag(admin, "public", "payouts"); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("RenameAndMerge", func(t *testing.T) {
+		if _, err := service.GetAPIByID(base, id); err != nil {
+			t.Fatalf("error getting API: %v", err)
+		}
+		if err := service.RenameTag(admin, "public", " external "); err != nil {
+			t.Fatalf("error renaming tag: %v", err)
+		}
+		api, _ := service.GetAPIByID(base, id)
+		if !reflect.DeepEqual(api.Tags, []string{"external", "payouts"}) {
+			t.Errorf("expected the cached API to see the renamed tag, got %v", api.Tags)
+		}
+
+		if err := service.MergeTags(admin, "payouts", "external"); err != nil {
+			t.Fatalf("error merging tags: %v", err)
+		}
+		tags, _ := service.ListTags(base)
+		if !reflect.DeepEqual(tags, []models.Tag{{Name: "external", APICount: 2}}) {
+			t.Errorf("unexpected tags after merge: %+v", tags)
+		}
+	})
+
+	t.Run("TargetedInvalidation", func(t *testing.T) {
+		store := cache.NewLRU(1<<20, time.Minute)
+		lruService := NewAPIService(mockRepo, WithCache(store))
+		untagged, _ := lruService.CreateAPI(member, models.API{Name: "Ledger", TeamID: 1})
+		lruService.GetAPIByID(base, id)
+		lruService.GetAPIByID(base, untagged)
+		lruService.ListAPIs(base, models.APIFilter{})
+
+		if err := lruService.RenameTag(admin, "external", "shared"); err != nil {
+			t.Fatalf("error renaming tag: %v", err)
+		}
+		if _, ok := store.Get(fmt.Sprintf("api:%d", id)); ok {
+			t.Errorf("expected the retagged API to be invalidated")
+		}
+		if _, ok := store.Get(fmt.Sprintf("api:%d", untagged)); !ok {
+			t.Errorf("expected APIs without the tag to stay cached")
+		}
+		if store.Len() != 1 {
+			t.Errorf("expected listings to be invalidated, %d entries remain", store.Len())
+		}
+	})
+}
+
+func TestAPIServiceDependencies(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	base := context.Background()
+	payments := auth.WithUser(base, models.User{ID: 2, TeamID: 1})
+	checkout := auth.WithUser(base, models.User{ID: 3, TeamID: 2})
+
+	ledger, _ := service.CreateAPI(payments, models.API{Name: "Ledger", TeamID: 1})
+	payouts, _ := service.CreateAPI(payments, models.API{Name: "Payouts", TeamID: 1})
+	cart, _ := service.CreateAPI(checkout, models.API{Name: "Cart", TeamID: 2})
+	standalone, _ := service.CreateAPI(checkout, models.API{Name: "Standalone", TeamID: 2})
+
+	t.Run("Add", func(t *testing.T) {
+		if err := service.AddAPIDependency(payments, payouts, ledger); err != nil {
+			t.Fatalf("error adding dependency: %v", err)
+		}
+		if err := service.AddAPIDependency(checkout, cart, payouts); err != nil {
+			t.Fatalf("error adding a dependency on another team's API: %v", err)
+		}
+		if err := service.AddAPIDependency(checkout, payouts, cart); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden declaring another team's dependency, got %v", err)
+		}
+		if err := service.AddAPIDependency(base, cart, ledger); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+
+		var verr *utils.ValidationError
+		if err := service.AddAPIDependency(checkout, cart, cart); !errors.As(err, &verr) || verr.Fields[0].Field != "depends_on_id" {
+			t.Errorf("expected validation error on depends_on_id, got %v", err)
+		}
+		if err := service.AddAPIDependency(checkout, cart, 999); !errors.Is(err, ErrNotFound) || err.Error() != "dependency not found" {
+			t.Errorf("expected dependency not found, got %v", err)
+		}
+	})
+
+	t.Run("Walk", func(t *testing.T) {
+		dependents, err := service.ListAPIDependents(base, ledger, 0)
+		if err != nil {
+			t.Fatalf("error listing dependents: %v", err)
+		}
+		if len(dependents) != 2 || dependents[0].Name != "Payouts" || dependents[1].Name != "Cart" || dependents[1].Depth != 2 {
+			t.Errorf("unexpected dependents: %+v", dependents)
+		}
+
+		direct, _ := service.ListAPIDependencies(base, cart, 1)
+		if len(direct) != 1 || direct[0].ID != payouts {
+			t.Errorf("expected only the direct dependency, got %+v", direct)
+		}
+
+		if _, err := service.ListAPIDependencies(base, 999, 0); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Graph", func(t *testing.T) {
+		if err := service.AddAPIDependency(payments, ledger, cart); err != nil {
+			t.Fatalf("error adding dependency: %v", err)
+		}
+		defer service.RemoveAPIDependency(payments, ledger, cart)
+
+		graph, err := service.GetDependencyGraph(base, 0)
+		if err != nil {
+			t.Fatalf("error getting graph: %v", err)
+		}
+		if len(graph.Nodes) != 3 || len(graph.Edges) != 3 {
+			t.Errorf("unexpected graph: %+v", graph)
+		}
+		if !reflect.DeepEqual(graph.Cycles, [][]int64{{ledger, payouts, cart}}) {
+			t.Errorf("expected one cycle through all three APIs, got %v", graph.Cycles)
+		}
+
+		alone, err := service.GetDependencyGraph(base, standalone)
+		if err != nil {
+			t.Fatalf("error getting graph: %v", err)
+		}
+		if len(alone.Nodes) != 1 || alone.Nodes[0].Name != "Standalone" || len(alone.Edges) != 0 || alone.Cycles == nil {
+			t.Errorf("expected the API on its own, got %+v", alone)
+		}
+	})
+
+	t.Run("Subgraph", func(t *testing.T) {
+		graph, err := service.GetDependencyGraph(base, payouts)
+		if err != nil {
+			t.Fatalf("error getting graph: %v", err)
+		}
+		if len(graph.Nodes) != 3 || len(graph.Edges) != 2 || len(graph.Cycles) != 0 {
+			t.Errorf("unexpected graph: %+v", graph)
+		}
+	})
+
+	t.Run("Remove", func(t *testing.T) {
+		if err := service.RemoveAPIDependency(payments, cart, payouts); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden, got %v", err)
+		}
+		if err := service.RemoveAPIDependency(checkout, cart, payouts); err != nil {
+			t.Fatalf("error removing dependency: %v", err)
+		}
+		if err := service.RemoveAPIDependency(checkout, cart, payouts); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestAPIServiceImport(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	base := context.Background()
+	member := auth.WithUser(base, models.User{ID: 2, TeamID: 1})
+	admin := auth.WithUser(base, models.User{ID: 1, Role: models.RoleAdmin})
+
+	ledger, _ := service.CreateAPI(member, models.API{Name: "Ledger", Version: "1.0.0", TeamID: 1, Tags: []string{"billing"}})
+	service.CreateAPI(admin, models.API{Name: "Login", TeamID: 2})
+
+	t.Run("Export", func(t *testing.T) {
+		records, err := service.ExportAPIs(base, models.APIFilter{TeamID: 1})
+		if err != nil {
+			t.Fatalf("error exporting: %v", err)
+		}
+		if len(records) != 1 || records[0].Name != "Ledger" || records[0].Lifecycle != models.LifecycleStable {
+			t.Errorf("unexpected records: %+v", records)
+		}
+
+		all, _ := service.ExportAPIs(base, models.APIFilter{Limit: 1})
+		if len(all) != 2 {
+			t.Errorf("expected paging not to apply, got %d records", len(all))
+		}
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		records := []catalog.Record{
+			{Name: " ledger ", Version: "1.1.0", TeamID: 1, Tags: []string{" Billing", "billing"}},
+			{Name: "Payouts", TeamID: 1},
+		}
+		report, err := service.ImportAPIs(member, records, models.APIImportOptions{Upsert: true, DryRun: true})
+		if err != nil {
+			t.Fatalf("error importing: %v", err)
+		}
+		if !report.DryRun || report.Created != 1 || report.Updated != 1 || len(report.Items) != 2 {
+			t.Fatalf("unexpected report: %+v", report)
+		}
+		update := report.Items[0]
+		if update.Action != models.ImportUpdate || update.ID != ledger || !reflect.DeepEqual(update.Changes, []string{"name", "version"}) {
+			t.Errorf("unexpected update: %+v", update)
+		}
+
+		page, _ := service.ListAPIs(base, models.APIFilter{})
+		api, _ := service.GetAPIByID(base, ledger)
+		if page.Total != 2 || api.Version != "1.0.0" {
+			t.Errorf("expected a dry run not to write, got %d APIs and version %s", page.Total, api.Version)
+		}
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		records := []catalog.Record{
+			{Name: "Ledger", TeamID: 1},
+			{Name: "Refunds", Version: "one", TeamID: 1},
+			{Name: "refunds", TeamID: 1},
+			{Name: "", TeamID: 1},
+		}
+		_, err := service.ImportAPIs(member, records, models.APIImportOptions{})
+		var verr *utils.ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected a validation error, got %v", err)
+		}
+		var fields []string
+		for _, f := range verr.Fields {
+			fields = append(fields, f.Field)
+		}
+		if expected := []string{"[0].name", "[1].version", "[2].name", "[3].name"}; !reflect.DeepEqual(fields, expected) {
+			t.Errorf("expected errors on %v, got %v", expected, verr.Fields)
+		}
+	})
+
+	t.Run("Authorization", func(t *testing.T) {
+		if _, err := service.ImportAPIs(base, nil, models.APIImportOptions{}); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+		records := []catalog.Record{{Name: "Payouts", TeamID: 1}, {Name: "Login", TeamID: 2}}
+		if _, err := service.ImportAPIs(member, records, models.APIImportOptions{Upsert: true}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden replacing another team's API, got %v", err)
+		}
+		page, _ := service.ListAPIs(base, models.APIFilter{})
+		if page.Total != 2 {
+			t.Errorf("expected nothing to be imported, got %d APIs", page.Total)
+		}
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		exported, _ := service.ExportAPIs(base, models.APIFilter{})
+		records := append(exported, catalog.Record{Name: "Payouts", TeamID: 1})
+		records[0].Description = "Double-entry bookkeeping"
+
+		report, err := service.ImportAPIs(admin, records, models.APIImportOptions{Upsert: true})
+		if err != nil {
+			t.Fatalf("error importing: %v", err)
+		}
+		if report.Created != 1 || report.Updated != 1 || report.Unchanged != 1 || report.Items[2].ID == 0 {
+			t.Errorf("unexpected report: %+v", report)
+		}
+
+		api, _ := service.GetAPIByID(base, ledger)
+		if api.Description != "Double-entry bookkeeping" || !reflect.DeepEqual(api.Tags, []string{"billing"}) {
+			t.Errorf("expected the cached API to be replaced, got %+v", api)
+		}
+		if _, err := service.GetAPIByID(base, report.Items[2].ID); err != nil {
+			t.Errorf("expected the new API to exist: %v", err)
+		}
+	})
+}
+
+func TestAPIServicePatch(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	base := context.Background()
+	member := auth.WithUser(base, models.User{ID: 2, TeamID: 1})
+
+	id, err := service.CreateAPI(member, models.API{
+		Name:              "Ledger",
+		Version:           "1.0.0",
+		DocumentationLink: "https://docs.example.com/ledger",
+		Swagger:           "swagger: '2.0'\ninfo: {title: Ledger, version: 1.0.0}\npaths: {}\n",
+		TeamID:            1,
+		Tags:              []string{"billing"},
+	})
+	if err != nil {
+		t.Fatalf("error creating API: %v", err)
+	}
+
+	t.Run("MergePatch", func(t *testing.T) {
+		api, err := service.PatchAPI(member, id, nil, jsonpatch.MergePatchType,
+			[]byte(`{"description": "Double-entry books", "lifecycle": "deprecated", "documentation_link": null}`))
+		if err != nil {
+			t.Fatalf("error patching: %v", err)
+		}
+		if api.Description != "Double-entry books" || api.DocumentationLink != "" {
+			t.Errorf("expected the patched fields to change, got %+v", api)
+		}
+		if api.Swagger == "" || api.TeamID != 1 || !reflect.DeepEqual(api.Tags, []string{"billing"}) {
+			t.Errorf("expected the other fields to be kept, got %+v", api)
+		}
+		if api.DeprecatedAt.IsZero() {
+			t.Errorf("expected the lifecycle rules to apply, got %+v", api)
+		}
+	})
+
+	t.Run("JSONPatch", func(t *testing.T) {
+		api, err := service.PatchAPI(member, id, nil, jsonpatch.JSONPatchType, []byte(`[
+			{"op": "test", "path": "/version", "value": "1.0.0"},
+			{"op": "add", "path": "/tags/-", "value": "finance"},
+			{"op": "replace", "path": "/version", "value": "1.1.0"}
+		]`))
+		if err != nil {
+			t.Fatalf("error patching: %v", err)
+		}
+		if api.Version != "1.1.0" || !reflect.DeepEqual(api.Tags, []string{"billing", "finance"}) {
+			t.Errorf("unexpected patched API: %+v", api)
+		}
+	})
+
+	failures := []struct {
+		name      string
+		mediaType string
+		patch     string
+		want      error
+	}{
+		{"TestFails", jsonpatch.JSONPatchType, `[{"op": "test", "path": "/version", "value": "9.9.9"}]`, ErrConflict},
+		{"MissingPath", jsonpatch.JSONPatchType, `[{"op": "remove", "path": "/owner"}]`, ErrConflict},
+		{"Malformed", jsonpatch.MergePatchType, `{"name": `, utils.ErrBadRequest},
+		{"UnsupportedType", "application/json", `{}`, utils.ErrBadRequest},
+		{"UnknownField", jsonpatch.MergePatchType, `{"owner": "Payments"}`, ErrValidation},
+		{"ReadOnly", jsonpatch.MergePatchType, `{"id": 7}`, ErrValidation},
+		{"Invalid", jsonpatch.MergePatchType, `{"name": null, "version": "one"}`, ErrValidation},
+		{"Transition", jsonpatch.MergePatchType, `{"lifecycle": "experimental"}`, ErrValidation},
+		{"OtherTeam", jsonpatch.MergePatchType, `{"team_id": 2}`, ErrForbidden},
+	}
+	for _, tt := range failures {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.PatchAPI(member, id, nil, tt.mediaType, []byte(tt.patch)); !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	api, _ := service.GetAPIByID(base, id)
+	if api.Name != "Ledger" || api.Version != "1.1.0" || api.TeamID != 1 {
+		t.Errorf("expected failed patches not to write, got %+v", api)
+	}
+
+	t.Run("Unauthenticated", func(t *testing.T) {
+		if _, err := service.PatchAPI(base, id, nil, jsonpatch.MergePatchType, []byte(`{}`)); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		if _, err := service.PatchAPI(member, 999, nil, jsonpatch.MergePatchType, []byte(`{}`)); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestAPIServiceIfMatch(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	id, _ := service.CreateAPI(ctx, models.API{Name: "Ledger"})
+	api, _ := service.GetAPIByID(ctx, id)
+	if api.RowVersion != 1 {
+		t.Fatalf("expected version 1, got %d", api.RowVersion)
+	}
+
+	stale := &models.IfMatch{Versions: []int64{7}}
+	if err := service.UpdateAPI(ctx, models.API{ID: id, Name: "Ledger"}, stale); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if _, err := service.PatchAPI(ctx, id, stale, jsonpatch.MergePatchType, []byte(`{}`)); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+	if err := service.DeleteAPI(ctx, id, stale); !errors.Is(err, ErrPreconditionFailed) {
+		t.Errorf("expected ErrPreconditionFailed, got %v", err)
+	}
+
+	if err := service.UpdateAPI(ctx, models.API{ID: id, Name: "Ledger", Version: "1.0.0"}, &models.IfMatch{Versions: []int64{7, 1}}); err != nil {
+		t.Fatalf("error updating: %v", err)
+	}
+	api, _ = service.GetAPIByID(ctx, id)
+	if api.RowVersion != 2 || api.Version != "1.0.0" {
+		t.Errorf("expected the update to be read back at version 2, got %+v", api)
+	}
+
+	patched, err := service.PatchAPI(ctx, id, &models.IfMatch{Any: true}, jsonpatch.MergePatchType, []byte(`{"description": "Books"}`))
+	if err != nil || patched.RowVersion != 3 {
+		t.Fatalf("expected the patch to reach version 3, got %+v, %v", patched, err)
+	}
+
+	if err := service.DeleteAPI(ctx, id, &models.IfMatch{Versions: []int64{3}}); err != nil {
+		t.Errorf("error deleting: %v", err)
+	}
+	if err := service.DeleteAPI(ctx, id, &models.IfMatch{Any: true}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a deleted API, got %v", err)
+	}
 }
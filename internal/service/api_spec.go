@@ -0,0 +1,110 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"microd-api/internal/models"
+	"microd-api/internal/openapi"
+	"microd-api/internal/semver"
+	"microd-api/internal/utils"
+)
+
+// GetAPISpec parses the OpenAPI document stored with an API.
+func (s *DefaultAPIService) GetAPISpec(ctx context.Context, id int64) (*openapi.Document, error) {
+	api, err := s.GetAPIByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return storedSpec(api)
+}
+
+// DiffAPISpec compares the stored OpenAPI document of an API with a candidate
+// replacement without changing anything.
+func (s *DefaultAPIService) DiffAPISpec(ctx context.Context, id int64, candidate string) (openapi.Report, error) {
+	api, err := s.GetAPIByID(ctx, id)
+	if err != nil {
+		return openapi.Report{}, err
+	}
+	current, err := storedSpec(api)
+	if err != nil {
+		return openapi.Report{}, err
+	}
+
+	next, err := openapi.Parse([]byte(candidate))
+	if err != nil {
+		return openapi.Report{}, specValidationError(err)
+	}
+	return openapi.Diff(current, next), nil
+}
+
+func storedSpec(api models.API) (*openapi.Document, error) {
+	if api.Swagger == "" {
+		return nil, utils.NewError(ErrNotFound, "API has no OpenAPI document")
+	}
+
+	doc, err := openapi.Parse([]byte(api.Swagger))
+	if err != nil {
+		// Documents stored before specs were validated may not parse.
+		return nil, utils.NewError(ErrConflict, err.Error())
+	}
+	return doc, nil
+}
+
+// checkSpecCompatibility rejects an update whose OpenAPI document breaks
+// clients of the stored one unless the major version goes up with it.
+func checkSpecCompatibility(existing, api models.API) error {
+	if existing.Swagger == "" || api.Swagger == "" || existing.Swagger == api.Swagger {
+		return nil
+	}
+	current, err := openapi.Parse([]byte(existing.Swagger))
+	if err != nil {
+		return nil
+	}
+	next, err := openapi.Parse([]byte(api.Swagger))
+	if err != nil {
+		return specValidationError(err)
+	}
+
+	report := openapi.Diff(current, next)
+	if !report.Breaking || majorBumped(existing.Version, api.Version) {
+		return nil
+	}
+
+	breaking := 0
+	for _, c := range report.Changes {
+		if c.Breaking {
+			breaking++
+		}
+	}
+	var v utils.ValidationError
+	v.Add("version", fmt.Sprintf("must increase the major version because the OpenAPI document has %d breaking change(s)", breaking))
+	return v.Err()
+}
+
+// majorBumped reports whether moving from old to new allows breaking changes.
+// Semantic versioning allows anything while the major version is 0, and an
+// API without a valid version has no promise to keep.
+func majorBumped(old, new string) bool {
+	from, err := semver.Parse(old)
+	if err != nil || from.Major == 0 {
+		return true
+	}
+	to, err := semver.Parse(new)
+	if err != nil {
+		return false
+	}
+	return to.Major > from.Major
+}
+
+func specValidationError(err error) error {
+	var specErr *openapi.Error
+	if !errors.As(err, &specErr) {
+		return err
+	}
+	var v utils.ValidationError
+	for _, problem := range specErr.Problems {
+		v.Add("swagger", problem)
+	}
+	return v.Err()
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"microd-api/internal/auth"
+	"microd-api/internal/models"
+	"microd-api/internal/utils"
+	"strings"
+)
+
+func (s *DefaultAPIService) ListTags(ctx context.Context) ([]models.Tag, error) {
+	return s.repo.ListTags(ctx)
+}
+
+// RenameTag renames a tag across the catalog. Tags are shared by every team,
+// so only admins may rename or merge them.
+func (s *DefaultAPIService) RenameTag(ctx context.Context, name, newName string) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+	newName = strings.TrimSpace(newName)
+	if err := validateTagName("name", newName); err != nil {
+		return err
+	}
+
+	ids, err := s.repo.RenameTag(ctx, name, newName, actorID(ctx))
+	if err != nil {
+		return fromRepository(err, "tag")
+	}
+
+	s.invalidateAPIs(ids...)
+
+	return nil
+}
+
+// MergeTags retags every API tagged name with into and removes name.
+func (s *DefaultAPIService) MergeTags(ctx context.Context, name, into string) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+	into = strings.TrimSpace(into)
+	if err := validateTagName("into", into); err != nil {
+		return err
+	}
+
+	ids, err := s.repo.MergeTags(ctx, name, into, actorID(ctx))
+	if err != nil {
+		return fromRepository(err, "tag")
+	}
+
+	s.invalidateAPIs(ids...)
+
+	return nil
+}
+
+func validateTagName(field, name string) error {
+	var v utils.ValidationError
+	switch {
+	case name == "":
+		v.Add(field, "is required")
+	case !validTag(name):
+		v.Add(field, tagMessage)
+	}
+	return v.Err()
+}
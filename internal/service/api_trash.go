@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"microd-api/internal/auth"
+	"microd-api/internal/models"
+	"time"
+)
+
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+// WithTrashRetention sets how long deleted APIs stay in the trash before
+// PurgeTrash removes them. A non-positive retention keeps the default.
+func WithTrashRetention(retention time.Duration) APIServiceOption {
+	return func(s *DefaultAPIService) {
+		if retention > 0 {
+			s.trashRetention = retention
+		}
+	}
+}
+
+func (s *DefaultAPIService) ListTrash(ctx context.Context) ([]models.DeletedAPI, error) {
+	deleted, err := s.repo.ListDeletedAPIs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range deleted {
+		deleted[i].PurgeAt = deleted[i].DeletedAt.Add(s.trashRetention)
+	}
+	return deleted, nil
+}
+
+// RestoreFromTrash takes a deleted API out of the trash. Like a delete, it is
+// limited to callers who may manage the API.
+func (s *DefaultAPIService) RestoreFromTrash(ctx context.Context, id int64) (models.API, error) {
+	if _, err := auth.RequireUser(ctx); err != nil {
+		return models.API{}, err
+	}
+
+	deleted, err := s.repo.GetDeletedAPI(ctx, id)
+	if err != nil {
+		return models.API{}, fromRepository(err, "deleted API")
+	}
+	if err := auth.AuthorizeAPIWrite(ctx, deleted.API); err != nil {
+		return models.API{}, err
+	}
+
+	if err := s.repo.UndeleteAPI(ctx, id, actorID(ctx)); err != nil {
+		return models.API{}, fromRepository(err, "deleted API")
+	}
+
+	s.invalidateAPIs(id)
+
+	api, err := s.repo.GetAPIByID(ctx, id)
+	if err != nil {
+		return models.API{}, fromRepository(err, "API")
+	}
+	return api, nil
+}
+
+// PurgeTrash permanently removes the APIs that have been in the trash for
+// longer than the retention period.
+func (s *DefaultAPIService) PurgeTrash(ctx context.Context) (int64, error) {
+	return s.repo.PurgeDeletedAPIs(ctx, time.Now().Add(-s.trashRetention))
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"microd-api/internal/models"
+	"microd-api/internal/openapi"
+	"microd-api/internal/semver"
+	"microd-api/internal/utils"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	maxNameLength        = 200
+	maxDescriptionLength = 10000
+	maxTagLength         = 50
+)
+
+var tagPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// normalizeAPI trims surrounding whitespace from the free-form fields and
+// rewrites Tags as a sorted list without blanks or duplicates. Tags that
+// differ only in case are the same tag.
+func normalizeAPI(api models.API) models.API {
+	api.Name = strings.TrimSpace(api.Name)
+	api.Version = strings.TrimSpace(api.Version)
+	api.DocumentationLink = strings.TrimSpace(api.DocumentationLink)
+	api.ForumReference = strings.TrimSpace(api.ForumReference)
+	api.ApmLink = strings.TrimSpace(api.ApmLink)
+	api.Lifecycle = strings.ToLower(strings.TrimSpace(api.Lifecycle))
+
+	api.Tags = normalizeTags(api.Tags)
+	return api
+}
+
+func normalizeTags(tags []string) []string {
+	seen := make(map[string]bool)
+	normalized := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[strings.ToLower(tag)] {
+			continue
+		}
+		seen[strings.ToLower(tag)] = true
+		normalized = append(normalized, tag)
+	}
+	sort.Slice(normalized, func(i, j int) bool {
+		return strings.ToLower(normalized[i]) < strings.ToLower(normalized[j])
+	})
+	return normalized
+}
+
+// validateAPI checks a normalized API and reports every invalid field at once.
+// Fields are named as in the JSON representation of an API.
+func validateAPI(api models.API) error {
+	var v utils.ValidationError
+
+	switch {
+	case api.Name == "":
+		v.Add("name", "is required")
+	case len(api.Name) > maxNameLength:
+		v.Add("name", "must be at most 200 characters")
+	}
+
+	if api.Version != "" && !semver.Valid(api.Version) {
+		v.Add("version", "must be a semantic version such as 1.2.3")
+	}
+
+	if len(api.Description) > maxDescriptionLength {
+		v.Add("description", "must be at most 10000 characters")
+	}
+
+	for _, link := range []struct {
+		field string
+		value string
+	}{
+		{"documentation_link", api.DocumentationLink},
+		{"forum_reference", api.ForumReference},
+		{"apm_link", api.ApmLink},
+	} {
+		if link.value != "" && !validURL(link.value) {
+			v.Add(link.field, "must be an absolute http or https URL")
+		}
+	}
+
+	if !validLifecycle(api.Lifecycle) {
+		v.Add("lifecycle", "must be one of experimental, stable, deprecated, retired")
+	}
+	if !api.SunsetAt.IsZero() && api.SunsetAt.Before(api.DeprecatedAt) {
+		v.Add("sunset_at", "must not be before deprecated_at")
+	}
+
+	for _, tag := range api.Tags {
+		if !validTag(tag) {
+			v.Add("tags", tagMessage)
+			break
+		}
+	}
+
+	if api.Swagger != "" {
+		if err := validateSpec(&v, api.Swagger); err != nil {
+			return err
+		}
+	}
+
+	return v.Err()
+}
+
+func normalizeAPIVersion(version models.APIVersion) models.APIVersion {
+	version.Version = strings.TrimSpace(version.Version)
+	version.DocumentationLink = strings.TrimSpace(version.DocumentationLink)
+	version.Status = strings.ToLower(strings.TrimSpace(version.Status))
+	if version.Status == "" {
+		version.Status = models.LifecycleStable
+	}
+	return version
+}
+
+// validateAPIVersion checks a normalized version the way validateAPI checks
+// an API.
+func validateAPIVersion(version models.APIVersion) error {
+	var v utils.ValidationError
+
+	switch {
+	case version.Version == "":
+		v.Add("version", "is required")
+	case !semver.Valid(version.Version):
+		v.Add("version", "must be a semantic version such as 1.2.3")
+	}
+
+	if version.DocumentationLink != "" && !validURL(version.DocumentationLink) {
+		v.Add("documentation_link", "must be an absolute http or https URL")
+	}
+
+	if !validLifecycle(version.Status) {
+		v.Add("status", "must be one of experimental, stable, deprecated, retired")
+	}
+
+	if version.Swagger != "" {
+		if err := validateSpec(&v, version.Swagger); err != nil {
+			return err
+		}
+	}
+
+	return v.Err()
+}
+
+// validateSpec adds every problem with an OpenAPI document to v.
+func validateSpec(v *utils.ValidationError, swagger string) error {
+	if _, err := openapi.Parse([]byte(swagger)); err != nil {
+		var specErr *openapi.Error
+		if !errors.As(err, &specErr) {
+			return err
+		}
+		for _, problem := range specErr.Problems {
+			v.Add("swagger", problem)
+		}
+	}
+	return nil
+}
+
+const tagMessage = "must be tags made of letters, digits, '.', '_' or '-', each at most 50 characters"
+
+func validTag(tag string) bool {
+	return len(tag) <= maxTagLength && tagPattern.MatchString(tag)
+}
+
+func validURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
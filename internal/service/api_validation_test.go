@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"microd-api/internal/utils"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAPI(t *testing.T) {
+	valid := models.API{
+		Name:              "Orders",
+		Version:           "1.4.0-rc.1",
+		DocumentationLink: "https://docs.example.com/orders",
+		ForumReference:    "http://forum.example.com/t/42",
+		ApmLink:           "https://apm.example.com/services/orders",
+		TeamID:            3,
+		Tags:              []string{"orders", "public", "v2.internal_beta"},
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(api *models.API)
+		fields []string
+	}{
+		{"Valid", func(api *models.API) {}, nil},
+		{"OptionalFieldsEmpty", func(api *models.API) { *api = models.API{Name: "Orders"} }, nil},
+		{"MissingName", func(api *models.API) { api.Name = "" }, []string{"name"}},
+		{"LongName", func(api *models.API) { api.Name = strings.Repeat("a", maxNameLength+1) }, []string{"name"}},
+		{"NonSemverVersion", func(api *models.API) { api.Version = "1.0" }, []string{"version"}},
+		{"PrefixedVersion", func(api *models.API) { api.Version = "v1.0.0" }, []string{"version"}},
+		{"RelativeLink", func(api *models.API) { api.DocumentationLink = "/docs/orders" }, []string{"documentation_link"}},
+		{"UnsupportedScheme", func(api *models.API) { api.ApmLink = "ftp://apm.example.com" }, []string{"apm_link"}},
+		{"MalformedLink", func(api *models.API) { api.ForumReference = "http://%zz" }, []string{"forum_reference"}},
+		{"BlankTags", func(api *models.API) { api.Tags = []string{"orders", " ", "public"} }, nil},
+		{"TagWithSpaces", func(api *models.API) { api.Tags = []string{"two words"} }, []string{"tags"}},
+		{"LongTag", func(api *models.API) { api.Tags = []string{strings.Repeat("t", maxTagLength+1)} }, []string{"tags"}},
+		{"ValidSpec", func(api *models.API) {
+			api.Swagger = `{"swagger": "2.0", "info": {"title": "Orders", "version": "1.0.0"}, "paths": {}}`
+		}, nil},
+		{"SpecURL", func(api *models.API) { api.Swagger = "http://swagger.example.com" }, []string{"swagger"}},
+		{"InvalidSpec", func(api *models.API) {
+			api.Swagger = "openapi: 3.0.0\ninfo: {}\npaths: {}\n"
+		}, []string{"swagger", "swagger"}},
+		{"Deprecated", func(api *models.API) {
+			api.Lifecycle = "Deprecated"
+			api.SunsetAt = time.Now().Add(90 * 24 * time.Hour)
+		}, nil},
+		{"UnknownLifecycle", func(api *models.API) { api.Lifecycle = "sunset" }, []string{"lifecycle"}},
+		{"SunsetBeforeDeprecation", func(api *models.API) {
+			api.Lifecycle = models.LifecycleRetired
+			api.DeprecatedAt = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+			api.SunsetAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		}, []string{"sunset_at"}},
+		{"AllAtOnce", func(api *models.API) {
+			api.Name = ""
+			api.Version = "latest"
+			api.DocumentationLink = "docs"
+			api.Tags = []string{"#hash"}
+		}, []string{"name", "version", "documentation_link", "tags"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := valid
+			tt.mutate(&api)
+
+			err := validateAPI(applyLifecycle(normalizeAPI(api), nil, time.Now()))
+			if tt.fields == nil {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var verr *utils.ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected ValidationError, got %v", err)
+			}
+			if !errors.Is(err, ErrValidation) {
+				t.Errorf("expected error of kind ErrValidation")
+			}
+			var fields []string
+			for _, f := range verr.Fields {
+				fields = append(fields, f.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("expected invalid fields %v, got %v", tt.fields, fields)
+			}
+		})
+	}
+}
+
+func TestAPIServiceValidation(t *testing.T) {
+	mockRepo := mocks.NewMockAPIRepository()
+	service := NewAPIService(mockRepo)
+	ctx := auth.WithUser(context.Background(), models.User{ID: 1, Role: models.RoleAdmin})
+
+	if _, err := service.CreateAPI(ctx, models.API{Version: "1"}); !errors.Is(err, ErrValidation) {
+		t.Fatalf("expected ErrValidation creating invalid API, got %v", err)
+	}
+
+	id, err := service.CreateAPI(ctx, models.API{Name: "  Orders ", Tags: []string{" public ", "orders", "", "Orders"}})
+	if err != nil {
+		t.Fatalf("error creating API: %v", err)
+	}
+	api, _ := mockRepo.GetAPIByID(ctx, id)
+	if api.Name != "Orders" || !reflect.DeepEqual(api.Tags, []string{"orders", "public"}) {
+		t.Errorf("expected normalized fields, got name %q and tags %q", api.Name, api.Tags)
+	}
+
+	if err := service.UpdateAPI(ctx, models.API{ID: id, Name: "Orders", ApmLink: "apm"}, nil); !errors.Is(err, ErrValidation) {
+		t.Errorf("expected ErrValidation updating with invalid link, got %v", err)
+	}
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"context"
+	"microd-api/internal/models"
+	"microd-api/internal/semver"
+	"microd-api/internal/utils"
+	"sort"
+	"time"
+)
+
+// LatestVersion can be used in place of a version string to address the
+// version that APIVersionList.Latest points to.
+const LatestVersion = "latest"
+
+func (s *DefaultAPIService) ListAPIVersions(ctx context.Context, id int64) (models.APIVersionList, error) {
+	if _, err := s.repo.GetAPIByID(ctx, id); err != nil {
+		return models.APIVersionList{}, fromRepository(err, "API")
+	}
+
+	versions, err := s.repo.ListAPIVersions(ctx, id)
+	if err != nil {
+		return models.APIVersionList{}, err
+	}
+	sortVersions(versions)
+	return models.APIVersionList{Latest: latestVersion(versions), Items: versions}, nil
+}
+
+func (s *DefaultAPIService) GetAPIVersion(ctx context.Context, id int64, version string) (models.APIVersion, error) {
+	if version == LatestVersion {
+		list, err := s.ListAPIVersions(ctx, id)
+		if err != nil {
+			return models.APIVersion{}, err
+		}
+		if list.Latest == "" {
+			return models.APIVersion{}, utils.NewError(ErrNotFound, "API has no released version")
+		}
+		version = list.Latest
+	}
+
+	v, err := s.repo.GetAPIVersion(ctx, id, version)
+	if err != nil {
+		return models.APIVersion{}, fromRepository(err, "version")
+	}
+	return v, nil
+}
+
+func (s *DefaultAPIService) CreateAPIVersion(ctx context.Context, version models.APIVersion) (models.APIVersion, error) {
+	version = normalizeAPIVersion(version)
+	if _, err := s.authorizeExisting(ctx, version.APIID); err != nil {
+		return models.APIVersion{}, err
+	}
+	if err := validateAPIVersion(version); err != nil {
+		return models.APIVersion{}, err
+	}
+
+	if _, err := s.repo.CreateAPIVersion(ctx, version); err != nil {
+		return models.APIVersion{}, fromRepository(err, "version")
+	}
+	return s.GetAPIVersion(ctx, version.APIID, version.Version)
+}
+
+func (s *DefaultAPIService) UpdateAPIVersion(ctx context.Context, version models.APIVersion) (models.APIVersion, error) {
+	version = normalizeAPIVersion(version)
+	if _, err := s.authorizeExisting(ctx, version.APIID); err != nil {
+		return models.APIVersion{}, err
+	}
+	if err := validateAPIVersion(version); err != nil {
+		return models.APIVersion{}, err
+	}
+	existing, err := s.repo.GetAPIVersion(ctx, version.APIID, version.Version)
+	if err != nil {
+		return models.APIVersion{}, fromRepository(err, "version")
+	}
+	if err := checkLifecycleTransition("status", existing.Status, version.Status); err != nil {
+		return models.APIVersion{}, err
+	}
+
+	if err := s.repo.UpdateAPIVersion(ctx, version); err != nil {
+		return models.APIVersion{}, fromRepository(err, "version")
+	}
+	return s.GetAPIVersion(ctx, version.APIID, version.Version)
+}
+
+func (s *DefaultAPIService) DeleteAPIVersion(ctx context.Context, id int64, version string) error {
+	if _, err := s.authorizeExisting(ctx, id); err != nil {
+		return err
+	}
+
+	return fromRepository(s.repo.DeleteAPIVersion(ctx, id, version), "version")
+}
+
+// sortVersions orders versions by semantic version precedence, highest first.
+// Versions stored before they were validated sort last, by their text.
+func sortVersions(versions []models.APIVersion) {
+	sort.SliceStable(versions, func(i, j int) bool {
+		a, errA := semver.Parse(versions[i].Version)
+		b, errB := semver.Parse(versions[j].Version)
+		switch {
+		case errA == nil && errB == nil:
+			return semver.Compare(a, b) > 0
+		case errA == nil || errB == nil:
+			return errA == nil
+		}
+		return versions[i].Version > versions[j].Version
+	})
+}
+
+// latestVersion picks the first version of a sorted list that clients should
+// use by default: a normal version that is already released and not retired.
+func latestVersion(sorted []models.APIVersion) string {
+	now := time.Now()
+	for _, v := range sorted {
+		parsed, err := semver.Parse(v.Version)
+		if err != nil || parsed.Prerelease != "" || v.Status == models.LifecycleRetired {
+			continue
+		}
+		if v.ReleasedAt.IsZero() || v.ReleasedAt.After(now) {
+			continue
+		}
+		return v.Version
+	}
+	return ""
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"microd-api/internal/auth"
+	"microd-api/internal/repository"
+	"microd-api/internal/utils"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+var ErrInvalidCredentials error = utils.NewError(ErrUnauthenticated, "invalid email or password")
+
+// dummyHash is compared against when the email is unknown so that login takes
+// the same time whether or not the account exists.
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("microd-api-dummy-password"), bcrypt.DefaultCost)
+
+type DefaultAuthService struct {
+	repo   repository.UserRepository
+	tokens *auth.TokenManager
+}
+
+func NewAuthService(repo repository.UserRepository, tokens *auth.TokenManager) AuthService {
+	return &DefaultAuthService{repo: repo, tokens: tokens}
+}
+
+func (s *DefaultAuthService) Login(ctx context.Context, email, password string) (auth.TokenPair, error) {
+	user, err := s.repo.GetUserByEmail(ctx, strings.ToLower(strings.TrimSpace(email)))
+	if err != nil || user.PasswordHash == "" {
+		bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return auth.TokenPair{}, ErrInvalidCredentials
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return auth.TokenPair{}, ErrInvalidCredentials
+	}
+
+	tokens, err := s.tokens.IssueTokens(user)
+	if err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	if err := s.repo.SetRefreshToken(ctx, user.ID, auth.HashToken(tokens.RefreshToken)); err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	return tokens, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Only the most
+// recently issued refresh token is accepted; presenting an older one is
+// treated as token theft and revokes the session entirely.
+func (s *DefaultAuthService) Refresh(ctx context.Context, refreshToken string) (auth.TokenPair, error) {
+	claims, err := s.tokens.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return auth.TokenPair{}, auth.ErrInvalidToken
+	}
+	userID, _ := claims.UserID()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil {
+		return auth.TokenPair{}, auth.ErrInvalidToken
+	}
+
+	oldHash := auth.HashToken(refreshToken)
+	if user.RefreshToken != oldHash {
+		if user.RefreshToken != "" {
+			if err := s.repo.SetRefreshToken(ctx, user.ID, ""); err != nil {
+				return auth.TokenPair{}, err
+			}
+		}
+		return auth.TokenPair{}, auth.ErrInvalidToken
+	}
+
+	tokens, err := s.tokens.IssueTokens(user)
+	if err != nil {
+		return auth.TokenPair{}, err
+	}
+
+	rotated, err := s.repo.RotateRefreshToken(ctx, user.ID, oldHash, auth.HashToken(tokens.RefreshToken))
+	if err != nil {
+		return auth.TokenPair{}, err
+	}
+	if !rotated {
+		return auth.TokenPair{}, auth.ErrInvalidToken
+	}
+
+	return tokens, nil
+}
+
+func (s *DefaultAuthService) Logout(ctx context.Context, refreshToken string) error {
+	claims, err := s.tokens.ParseRefreshToken(refreshToken)
+	if err != nil {
+		return auth.ErrInvalidToken
+	}
+	userID, _ := claims.UserID()
+
+	user, err := s.repo.GetUserByID(ctx, userID)
+	if err != nil || user.RefreshToken != auth.HashToken(refreshToken) {
+		return auth.ErrInvalidToken
+	}
+
+	return s.repo.SetRefreshToken(ctx, user.ID, "")
+}
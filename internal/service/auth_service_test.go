@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"testing"
+	"time"
+)
+
+func TestAuthService(t *testing.T) {
+	mockRepo := mocks.NewMockUserRepository()
+	tokens := auth.NewTokenManager([]byte("test-secret"), time.Minute, time.Hour)
+	userService := NewUserService(mockRepo)
+	service := NewAuthService(mockRepo, tokens)
+	ctx := context.Background()
+
+	userID, err := userService.RegisterUser(ctx, models.User{Name: "Ada", Email: "ada@example.com"}, "correct horse")
+	if err != nil {
+		t.Fatalf("error registering user: %v", err)
+	}
+
+	var current auth.TokenPair
+
+	t.Run("Login", func(t *testing.T) {
+		current, err = service.Login(ctx, "ADA@example.com", "correct horse")
+		if err != nil {
+			t.Fatalf("error logging in: %v", err)
+		}
+
+		claims, err := tokens.ParseAccessToken(current.AccessToken)
+		if err != nil {
+			t.Fatalf("error parsing access token: %v", err)
+		}
+		if id, _ := claims.UserID(); id != userID {
+			t.Errorf("expected access token for user %d, got %d", userID, id)
+		}
+
+		user, _ := mockRepo.GetUserByID(ctx, userID)
+		if user.RefreshToken != auth.HashToken(current.RefreshToken) {
+			t.Errorf("expected refresh token to be stored hashed")
+		}
+	})
+
+	t.Run("Login_InvalidCredentials", func(t *testing.T) {
+		_, err := service.Login(ctx, "ada@example.com", "wrong password")
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials for wrong password, got %v", err)
+		}
+
+		_, err = service.Login(ctx, "nobody@example.com", "correct horse")
+		if !errors.Is(err, ErrInvalidCredentials) {
+			t.Errorf("expected ErrInvalidCredentials for unknown email, got %v", err)
+		}
+	})
+
+	t.Run("Refresh", func(t *testing.T) {
+		previous := current
+		current, err = service.Refresh(ctx, previous.RefreshToken)
+		if err != nil {
+			t.Fatalf("error refreshing token: %v", err)
+		}
+		if current.RefreshToken == previous.RefreshToken {
+			t.Errorf("expected refresh token to be rotated")
+		}
+
+		_, err = service.Refresh(ctx, "garbage")
+		if !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("expected ErrInvalidToken for garbage token, got %v", err)
+		}
+	})
+
+	t.Run("Refresh_ReuseRevokesSession", func(t *testing.T) {
+		stale := current
+		current, err = service.Refresh(ctx, stale.RefreshToken)
+		if err != nil {
+			t.Fatalf("error refreshing token: %v", err)
+		}
+
+		_, err = service.Refresh(ctx, stale.RefreshToken)
+		if !errors.Is(err, auth.ErrInvalidToken) {
+			t.Fatalf("expected reused refresh token to be rejected, got %v", err)
+		}
+
+		_, err = service.Refresh(ctx, current.RefreshToken)
+		if !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("expected session to be revoked after reuse, got %v", err)
+		}
+	})
+
+	t.Run("Logout", func(t *testing.T) {
+		current, err = service.Login(ctx, "ada@example.com", "correct horse")
+		if err != nil {
+			t.Fatalf("error logging in: %v", err)
+		}
+
+		if err := service.Logout(ctx, current.RefreshToken); err != nil {
+			t.Fatalf("error logging out: %v", err)
+		}
+
+		_, err = service.Refresh(ctx, current.RefreshToken)
+		if !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("expected refresh after logout to fail, got %v", err)
+		}
+
+		err = service.Logout(ctx, current.RefreshToken)
+		if !errors.Is(err, auth.ErrInvalidToken) {
+			t.Errorf("expected second logout to fail, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"context"
+	"microd-api/internal/auth"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+)
+
+type DefaultCategoryService struct {
+	repo repository.CategoryRepository
+}
+
+func NewCategoryService(repo repository.CategoryRepository) CategoryService {
+	return &DefaultCategoryService{repo: repo}
+}
+
+// CreateCategory adds a category. Categories are shared by every team, and
+// deleting one unmaps it from every API, so only admins may change them.
+func (s *DefaultCategoryService) CreateCategory(ctx context.Context, category models.APICategory) (int64, error) {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return 0, err
+	}
+	id, err := s.repo.CreateCategory(ctx, category)
+	return id, fromRepository(err, "category")
+}
+
+func (s *DefaultCategoryService) GetCategoryByID(ctx context.Context, id int64) (models.APICategory, error) {
+	category, err := s.repo.GetCategoryByID(ctx, id)
+	return category, fromRepository(err, "category")
+}
+
+func (s *DefaultCategoryService) UpdateCategory(ctx context.Context, category models.APICategory) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+	return fromRepository(s.repo.UpdateCategory(ctx, category), "category")
+}
+
+func (s *DefaultCategoryService) DeleteCategory(ctx context.Context, id int64) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+	return fromRepository(s.repo.DeleteCategory(ctx, id), "category")
+}
+
+func (s *DefaultCategoryService) ListCategories(ctx context.Context) ([]models.APICategory, error) {
+	return s.repo.ListCategories(ctx)
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"testing"
+)
+
+func TestCategoryService(t *testing.T) {
+	mockRepo := mocks.NewMockCategoryRepository()
+	service := NewCategoryService(mockRepo)
+	base := context.Background()
+	ctx := auth.WithUser(base, models.User{ID: 1, Role: models.RoleAdmin})
+	member := auth.WithUser(base, models.User{ID: 2})
+
+	t.Run("Authorization", func(t *testing.T) {
+		if _, err := service.CreateCategory(base, models.APICategory{Name: "Payments"}); !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated creating without a user, got %v", err)
+		}
+		if _, err := service.CreateCategory(member, models.APICategory{Name: "Payments"}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden creating as a member, got %v", err)
+		}
+		if err := service.UpdateCategory(member, models.APICategory{ID: 1, Name: "Billing"}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden updating as a member, got %v", err)
+		}
+		if err := service.DeleteCategory(member, 1); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden deleting as a member, got %v", err)
+		}
+	})
+
+	t.Run("CreateCategory", func(t *testing.T) {
+		id, err := service.CreateCategory(ctx, models.APICategory{Name: "Payments"})
+		if err != nil {
+			t.Fatalf("error creating category: %v", err)
+		}
+		if id <= 0 {
+			t.Errorf("expected positive ID, got %d", id)
+		}
+	})
+
+	t.Run("GetCategoryByID", func(t *testing.T) {
+		category, err := service.GetCategoryByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("error getting category: %v", err)
+		}
+		if category.Name != "Payments" {
+			t.Errorf("expected category name 'Payments', got '%s'", category.Name)
+		}
+	})
+
+	t.Run("UpdateCategory", func(t *testing.T) {
+		err := service.UpdateCategory(ctx, models.APICategory{ID: 1, Name: "Billing"})
+		if err != nil {
+			t.Fatalf("error updating category: %v", err)
+		}
+
+		category, err := service.GetCategoryByID(ctx, 1)
+		if err != nil {
+			t.Fatalf("error getting updated category: %v", err)
+		}
+		if category.Name != "Billing" {
+			t.Errorf("expected updated category name 'Billing', got '%s'", category.Name)
+		}
+	})
+
+	t.Run("ListCategories", func(t *testing.T) {
+		categories, err := service.ListCategories(member)
+		if err != nil {
+			t.Fatalf("error listing categories: %v", err)
+		}
+		if len(categories) != 1 {
+			t.Errorf("expected 1 category, got %d", len(categories))
+		}
+	})
+
+	t.Run("DeleteCategory", func(t *testing.T) {
+		err := service.DeleteCategory(ctx, 1)
+		if err != nil {
+			t.Fatalf("error deleting category: %v", err)
+		}
+
+		_, err = service.GetCategoryByID(ctx, 1)
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound when fetching deleted category, got %v", err)
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		if _, err := service.CreateCategory(ctx, models.APICategory{Name: "Identity"}); err != nil {
+			t.Fatalf("error creating category: %v", err)
+		}
+		if _, err := service.CreateCategory(ctx, models.APICategory{Name: "Identity"}); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict for duplicate name, got %v", err)
+		}
+		if err := service.UpdateCategory(ctx, models.APICategory{ID: 99, Name: "Ghost"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound updating missing category, got %v", err)
+		}
+		if err := service.DeleteCategory(ctx, 99); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound deleting missing category, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/repository"
+	"microd-api/internal/utils"
+)
+
+// The service error taxonomy. Every error a service returns either wraps one
+// of these kinds or is an unexpected failure; utils.RespondWithProblem turns
+// them into HTTP responses.
+var (
+	ErrNotFound           = utils.ErrNotFound
+	ErrConflict           = utils.ErrConflict
+	ErrValidation         = utils.ErrValidation
+	ErrUnauthenticated    = auth.ErrUnauthenticated
+	ErrForbidden          = auth.ErrForbidden
+	ErrPreconditionFailed = utils.ErrPreconditionFailed
+)
+
+// fromRepository translates repository failures into the taxonomy, naming
+// the resource the caller was working with.
+func fromRepository(err error, resource string) error {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return utils.NewError(ErrNotFound, resource+" not found")
+	case errors.Is(err, repository.ErrConflict):
+		return utils.NewError(ErrConflict, resource+" conflicts with an existing record")
+	case errors.Is(err, repository.ErrVersionMismatch):
+		return errModified(resource)
+	}
+	return err
+}
+
+// fromAPIWrite translates a failed create or update of an API, reporting an
+// owner that does not exist as a validation error on team_id.
+func fromAPIWrite(err error) error {
+	if errors.Is(err, repository.ErrUnknownTeam) {
+		var v utils.ValidationError
+		v.Add("team_id", "does not exist")
+		return v.Err()
+	}
+	return fromRepository(err, "API")
+}
+
+// errModified reports that a resource no longer matches the If-Match
+// precondition of a request.
+func errModified(resource string) error {
+	return utils.NewError(ErrPreconditionFailed, resource+" has been modified")
+}
@@ -2,13 +2,81 @@ package service
 
 import (
 	"context"
+	"microd-api/internal/auth"
+	"microd-api/internal/catalog"
 	"microd-api/internal/models"
+	"microd-api/internal/openapi"
 )
 
 type APIService interface {
 	CreateAPI(ctx context.Context, api models.API) (int64, error)
 	GetAPIByID(ctx context.Context, id int64) (models.API, error)
-	UpdateAPI(ctx context.Context, api models.API) error
-	DeleteAPI(ctx context.Context, id int64) error
-	ListAPIs(ctx context.Context) ([]models.API, error)
+	UpdateAPI(ctx context.Context, api models.API, ifMatch *models.IfMatch) error
+	DeleteAPI(ctx context.Context, id int64, ifMatch *models.IfMatch) error
+	ListAPIs(ctx context.Context, filter models.APIFilter) (models.APIPage, error)
+	SearchAPIs(ctx context.Context, q string, limit int) (models.APISearchPage, error)
+	GetAPISpec(ctx context.Context, id int64) (*openapi.Document, error)
+	DiffAPISpec(ctx context.Context, id int64, candidate string) (openapi.Report, error)
+	AttachCategory(ctx context.Context, apiID, categoryID int64) error
+	DetachCategory(ctx context.Context, apiID, categoryID int64) error
+	ListAPICategories(ctx context.Context, apiID int64) ([]models.APICategory, error)
+	ListAPIRevisions(ctx context.Context, id int64) ([]models.APIRevision, error)
+	GetAPIRevision(ctx context.Context, id, revision int64) (models.APIRevision, error)
+	RestoreAPIRevision(ctx context.Context, id, revision int64) (models.API, error)
+	ListTrash(ctx context.Context) ([]models.DeletedAPI, error)
+	RestoreFromTrash(ctx context.Context, id int64) (models.API, error)
+	PurgeTrash(ctx context.Context) (int64, error)
+	ListAPIVersions(ctx context.Context, id int64) (models.APIVersionList, error)
+	GetAPIVersion(ctx context.Context, id int64, version string) (models.APIVersion, error)
+	CreateAPIVersion(ctx context.Context, version models.APIVersion) (models.APIVersion, error)
+	UpdateAPIVersion(ctx context.Context, version models.APIVersion) (models.APIVersion, error)
+	DeleteAPIVersion(ctx context.Context, id int64, version string) error
+	ListTags(ctx context.Context) ([]models.Tag, error)
+	RenameTag(ctx context.Context, name, newName string) error
+	MergeTags(ctx context.Context, name, into string) error
+	AddAPIDependency(ctx context.Context, id, dependsOnID int64) error
+	RemoveAPIDependency(ctx context.Context, id, dependsOnID int64) error
+	ListAPIDependencies(ctx context.Context, id int64, maxDepth int) ([]models.DependencyNode, error)
+	ListAPIDependents(ctx context.Context, id int64, maxDepth int) ([]models.DependencyNode, error)
+	GetDependencyGraph(ctx context.Context, id int64) (models.DependencyGraph, error)
+	ExportAPIs(ctx context.Context, filter models.APIFilter) ([]catalog.Record, error)
+	ImportAPIs(ctx context.Context, records []catalog.Record, options models.APIImportOptions) (models.APIImportReport, error)
+	PatchAPI(ctx context.Context, id int64, ifMatch *models.IfMatch, mediaType string, patch []byte) (models.API, error)
+	InvalidateSearches()
+}
+
+type CategoryService interface {
+	CreateCategory(ctx context.Context, category models.APICategory) (int64, error)
+	GetCategoryByID(ctx context.Context, id int64) (models.APICategory, error)
+	UpdateCategory(ctx context.Context, category models.APICategory) error
+	DeleteCategory(ctx context.Context, id int64) error
+	ListCategories(ctx context.Context) ([]models.APICategory, error)
+}
+
+type TeamService interface {
+	CreateTeam(ctx context.Context, team models.Team) (int64, error)
+	GetTeamByID(ctx context.Context, id int64) (models.Team, error)
+	UpdateTeam(ctx context.Context, team models.Team) error
+	DeleteTeam(ctx context.Context, id int64) error
+	ListTeams(ctx context.Context) ([]models.Team, error)
+	ListTeamMembers(ctx context.Context, id int64) ([]models.User, error)
+	AddTeamMember(ctx context.Context, id, userID int64) error
+	RemoveTeamMember(ctx context.Context, id, userID int64) error
+	ListTeamAPIs(ctx context.Context, id int64, filter models.APIFilter) (models.APIPage, error)
+}
+
+type UserService interface {
+	RegisterUser(ctx context.Context, user models.User, password string) (int64, error)
+	GetUserByID(ctx context.Context, id int64) (models.User, error)
+	GetCurrentUser(ctx context.Context) (models.User, error)
+	UpdateUser(ctx context.Context, user models.User) error
+	UpdateUserRole(ctx context.Context, id int64, role int64) error
+	UpdateUserTeam(ctx context.Context, id int64, teamID int64) error
+	ListUsers(ctx context.Context) ([]models.User, error)
+}
+
+type AuthService interface {
+	Login(ctx context.Context, email, password string) (auth.TokenPair, error)
+	Refresh(ctx context.Context, refreshToken string) (auth.TokenPair, error)
+	Logout(ctx context.Context, refreshToken string) error
 }
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"microd-api/internal/utils"
+	"strings"
+)
+
+const maxTeamLength = 100
+
+var ErrTeamHasAPIs error = utils.NewError(ErrConflict, "team still owns APIs; move or delete them first")
+
+type DefaultTeamService struct {
+	repo repository.TeamRepository
+	apis APIService
+}
+
+// NewTeamService returns a TeamService that lists the APIs of a team through
+// apis, so that those listings share its cache and filter rules.
+func NewTeamService(repo repository.TeamRepository, apis APIService) TeamService {
+	return &DefaultTeamService{repo: repo, apis: apis}
+}
+
+func (s *DefaultTeamService) CreateTeam(ctx context.Context, team models.Team) (int64, error) {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return 0, err
+	}
+	team = normalizeTeam(team)
+	if err := validateTeam(team); err != nil {
+		return 0, err
+	}
+
+	id, err := s.repo.CreateTeam(ctx, team)
+	return id, fromRepository(err, "team")
+}
+
+func (s *DefaultTeamService) GetTeamByID(ctx context.Context, id int64) (models.Team, error) {
+	team, err := s.repo.GetTeamByID(ctx, id)
+	return team, fromRepository(err, "team")
+}
+
+func (s *DefaultTeamService) UpdateTeam(ctx context.Context, team models.Team) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+	team = normalizeTeam(team)
+	if err := validateTeam(team); err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateTeam(ctx, team); err != nil {
+		return fromRepository(err, "team")
+	}
+
+	// Searches match APIs on the name of their team.
+	s.apis.InvalidateSearches()
+	return nil
+}
+
+func (s *DefaultTeamService) DeleteTeam(ctx context.Context, id int64) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+
+	err := s.repo.DeleteTeam(ctx, id)
+	if errors.Is(err, repository.ErrTeamHasAPIs) {
+		return ErrTeamHasAPIs
+	}
+	return fromRepository(err, "team")
+}
+
+func (s *DefaultTeamService) ListTeams(ctx context.Context) ([]models.Team, error) {
+	return s.repo.ListTeams(ctx)
+}
+
+func (s *DefaultTeamService) ListTeamMembers(ctx context.Context, id int64) ([]models.User, error) {
+	if _, err := s.GetTeamByID(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.ListTeamMembers(ctx, id)
+}
+
+// AddTeamMember moves a user into the team. A user belongs to at most one
+// team, so this takes them out of their previous team.
+func (s *DefaultTeamService) AddTeamMember(ctx context.Context, id, userID int64) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+	if _, err := s.GetTeamByID(ctx, id); err != nil {
+		return err
+	}
+
+	return fromRepository(s.repo.AddTeamMember(ctx, id, userID), "user")
+}
+
+func (s *DefaultTeamService) RemoveTeamMember(ctx context.Context, id, userID int64) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+
+	return fromRepository(s.repo.RemoveTeamMember(ctx, id, userID), "team member")
+}
+
+// ListTeamAPIs returns a page of the APIs the team owns.
+func (s *DefaultTeamService) ListTeamAPIs(ctx context.Context, id int64, filter models.APIFilter) (models.APIPage, error) {
+	if _, err := s.GetTeamByID(ctx, id); err != nil {
+		return models.APIPage{}, err
+	}
+	filter.TeamID = id
+	return s.apis.ListAPIs(ctx, filter)
+}
+
+func normalizeTeam(team models.Team) models.Team {
+	team.Name = strings.TrimSpace(team.Name)
+	team.Description = strings.TrimSpace(team.Description)
+	return team
+}
+
+func validateTeam(team models.Team) error {
+	var v utils.ValidationError
+	switch {
+	case team.Name == "":
+		v.Add("name", "is required")
+	case len(team.Name) > maxTeamLength:
+		v.Add("name", "must be at most 100 characters")
+	}
+	if len(team.Description) > maxDescriptionLength {
+		v.Add("description", "must be at most 10000 characters")
+	}
+	return v.Err()
+}
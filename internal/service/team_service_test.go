@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/cache"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"microd-api/internal/utils"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTeamService(t *testing.T) {
+	apiRepo := mocks.NewMockAPIRepository()
+	userRepo := mocks.NewMockUserRepository()
+	teamRepo := mocks.NewMockTeamRepository(apiRepo, userRepo)
+	service := NewTeamService(teamRepo, NewAPIService(apiRepo))
+	base := context.Background()
+	admin := auth.WithUser(base, models.User{ID: 1, Role: models.RoleAdmin})
+	member := auth.WithUser(base, models.User{ID: 2})
+
+	var id int64
+	t.Run("CreateTeam", func(t *testing.T) {
+		if _, err := service.CreateTeam(member, models.Team{Name: "Payments"}); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for non-admin, got %v", err)
+		}
+
+		var verr *utils.ValidationError
+		if _, err := service.CreateTeam(admin, models.Team{Name: "  "}); !errors.As(err, &verr) || verr.Fields[0].Field != "name" {
+			t.Errorf("expected validation error on Name, got %v", err)
+		}
+		if _, err := service.CreateTeam(admin, models.Team{Name: strings.Repeat("x", 101)}); !errors.Is(err, ErrValidation) {
+			t.Errorf("expected validation error for a long name, got %v", err)
+		}
+
+		var err error
+		id, err = service.CreateTeam(admin, models.Team{Name: " Payments "})
+		if err != nil {
+			t.Fatalf("error creating team: %v", err)
+		}
+		if _, err := service.CreateTeam(admin, models.Team{Name: "payments"}); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict, got %v", err)
+		}
+		team, _ := service.GetTeamByID(base, id)
+		if team.Name != "Payments" {
+			t.Errorf("expected trimmed name, got %q", team.Name)
+		}
+	})
+
+	t.Run("Members", func(t *testing.T) {
+		userID, _ := userRepo.CreateUser(base, models.User{Name: "Ada", Email: "ada@example.com"})
+		if err := service.AddTeamMember(member, id, userID); !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for non-admin, got %v", err)
+		}
+		if err := service.AddTeamMember(admin, 42, userID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound for an unknown team, got %v", err)
+		}
+		if err := service.AddTeamMember(admin, id, userID); err != nil {
+			t.Fatalf("error adding member: %v", err)
+		}
+
+		members, err := service.ListTeamMembers(base, id)
+		if err != nil {
+			t.Fatalf("error listing members: %v", err)
+		}
+		if len(members) != 1 || members[0].ID != userID {
+			t.Errorf("unexpected members: %+v", members)
+		}
+
+		if err := service.RemoveTeamMember(admin, id, userID); err != nil {
+			t.Fatalf("error removing member: %v", err)
+		}
+		if err := service.RemoveTeamMember(admin, id, userID); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("ListTeamAPIs", func(t *testing.T) {
+		apiRepo.CreateAPI(base, models.API{Name: "Payouts", TeamID: id}, 0)
+		apiRepo.CreateAPI(base, models.API{Name: "Login", TeamID: id + 1}, 0)
+
+		page, err := service.ListTeamAPIs(base, id, models.APIFilter{})
+		if err != nil {
+			t.Fatalf("error listing team APIs: %v", err)
+		}
+		if page.Total != 1 || page.Items[0].Name != "Payouts" {
+			t.Errorf("unexpected team APIs: %+v", page)
+		}
+		if _, err := service.ListTeamAPIs(base, 42, models.APIFilter{}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound, got %v", err)
+		}
+		if _, err := service.ListTeamAPIs(base, id, models.APIFilter{Sort: "team"}); !errors.Is(err, ErrInvalidFilter) {
+			t.Errorf("expected ErrInvalidFilter, got %v", err)
+		}
+	})
+
+	t.Run("RenameInvalidatesSearches", func(t *testing.T) {
+		store := cache.NewLRU(1<<20, time.Minute)
+		apis := NewAPIService(apiRepo, WithCache(store))
+		teams := NewTeamService(teamRepo, apis)
+
+		apis.SearchAPIs(base, "payouts", 0)
+		if store.Len() != 1 {
+			t.Fatalf("expected the search to be cached, got %d entries", store.Len())
+		}
+		if err := teams.UpdateTeam(admin, models.Team{ID: id, Name: "Treasury"}); err != nil {
+			t.Fatalf("error renaming team: %v", err)
+		}
+		if store.Len() != 0 {
+			t.Errorf("expected searches to be invalidated, %d entries remain", store.Len())
+		}
+	})
+
+	t.Run("DeleteTeam", func(t *testing.T) {
+		if err := service.DeleteTeam(admin, id); !errors.Is(err, ErrConflict) {
+			t.Errorf("expected ErrConflict while the team owns APIs, got %v", err)
+		}
+		apiRepo.DeleteAPI(base, 1, 0, 0)
+		if err := service.DeleteTeam(admin, id); err != nil {
+			t.Fatalf("error deleting team: %v", err)
+		}
+		if _, err := service.GetTeamByID(base, id); !errors.Is(err, ErrNotFound) {
+			t.Errorf("expected ErrNotFound after delete, got %v", err)
+		}
+	})
+}
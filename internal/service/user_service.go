@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/models"
+	"microd-api/internal/repository"
+	"microd-api/internal/utils"
+	"net/mail"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const minPasswordLength = 8
+
+var (
+	ErrEmailTaken      error = utils.NewError(ErrConflict, "email already registered")
+	ErrInvalidUser     error = utils.NewError(ErrValidation, "name and a valid email are required")
+	ErrInvalidPassword error = utils.NewError(ErrValidation, "password must be at least 8 characters")
+	ErrInvalidRole     error = utils.NewError(ErrValidation, "invalid role")
+	ErrUnknownTeam     error = utils.NewError(ErrValidation, "team does not exist")
+)
+
+type DefaultUserService struct {
+	repo       repository.UserRepository
+	adminEmail string
+}
+
+type UserServiceOption func(*DefaultUserService)
+
+// WithAdminEmail makes RegisterUser give the admin role to the user who
+// registers with email, so that a new deployment can get its first admin.
+// Other users only become admins when an admin changes their role.
+func WithAdminEmail(email string) UserServiceOption {
+	return func(s *DefaultUserService) {
+		s.adminEmail = strings.ToLower(strings.TrimSpace(email))
+	}
+}
+
+func NewUserService(repo repository.UserRepository, opts ...UserServiceOption) UserService {
+	s := &DefaultUserService{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *DefaultUserService) RegisterUser(ctx context.Context, user models.User, password string) (int64, error) {
+	user.Name = strings.TrimSpace(user.Name)
+	user.Email = strings.ToLower(strings.TrimSpace(user.Email))
+	if user.Name == "" {
+		return 0, ErrInvalidUser
+	}
+	if _, err := mail.ParseAddress(user.Email); err != nil {
+		return 0, ErrInvalidUser
+	}
+	if len(password) < minPasswordLength {
+		return 0, ErrInvalidPassword
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return 0, err
+	}
+	user.PasswordHash = string(hash)
+	user.Role = models.RoleUser
+	if s.adminEmail != "" && user.Email == s.adminEmail {
+		user.Role = models.RoleAdmin
+	}
+	user.RefreshToken = ""
+
+	id, err := s.repo.CreateUser(ctx, user)
+	if errors.Is(err, repository.ErrDuplicateEmail) {
+		return 0, ErrEmailTaken
+	}
+	return id, fromRepository(err, "user")
+}
+
+func (s *DefaultUserService) GetUserByID(ctx context.Context, id int64) (models.User, error) {
+	user, err := s.repo.GetUserByID(ctx, id)
+	return user, fromRepository(err, "user")
+}
+
+func (s *DefaultUserService) GetCurrentUser(ctx context.Context) (models.User, error) {
+	current, err := auth.RequireUser(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+	user, err := s.repo.GetUserByID(ctx, current.ID)
+	return user, fromRepository(err, "user")
+}
+
+func (s *DefaultUserService) UpdateUser(ctx context.Context, user models.User) error {
+	current, err := auth.RequireUser(ctx)
+	if err != nil {
+		return err
+	}
+	if current.ID != user.ID && !current.IsAdmin() {
+		return ErrForbidden
+	}
+
+	existing, err := s.repo.GetUserByID(ctx, user.ID)
+	if err != nil {
+		return fromRepository(err, "user")
+	}
+
+	user.Name = strings.TrimSpace(user.Name)
+	if user.Name == "" {
+		return ErrInvalidUser
+	}
+	existing.Name = user.Name
+	existing.Avatar = user.Avatar
+
+	return fromRepository(s.repo.UpdateUser(ctx, existing), "user")
+}
+
+func (s *DefaultUserService) UpdateUserRole(ctx context.Context, id int64, role int64) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return ErrInvalidRole
+	}
+
+	return fromRepository(s.repo.UpdateUserRole(ctx, id, role), "user")
+}
+
+// UpdateUserTeam moves a user to a team, or out of their team when teamID is
+// 0.
+func (s *DefaultUserService) UpdateUserTeam(ctx context.Context, id int64, teamID int64) error {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return err
+	}
+
+	err := s.repo.UpdateUserTeam(ctx, id, teamID)
+	if errors.Is(err, repository.ErrUnknownTeam) {
+		return ErrUnknownTeam
+	}
+	return fromRepository(err, "user")
+}
+
+func (s *DefaultUserService) ListUsers(ctx context.Context) ([]models.User, error) {
+	if err := auth.RequireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	return s.repo.ListUsers(ctx)
+}
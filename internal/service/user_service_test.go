@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"microd-api/internal/auth"
+	"microd-api/internal/mocks"
+	"microd-api/internal/models"
+	"testing"
+)
+
+func TestUserService(t *testing.T) {
+	mockRepo := mocks.NewMockUserRepository()
+	service := NewUserService(mockRepo)
+	ctx := context.Background()
+
+	adminCtx := auth.WithUser(ctx, models.User{ID: 100, Role: models.RoleAdmin})
+	userCtx := auth.WithUser(ctx, models.User{ID: 1, Role: models.RoleUser})
+	otherCtx := auth.WithUser(ctx, models.User{ID: 2, Role: models.RoleUser})
+
+	t.Run("RegisterUser", func(t *testing.T) {
+		id, err := service.RegisterUser(ctx, models.User{Name: "Ada", Email: " Ada@Example.com ", Role: models.RoleAdmin}, "correct horse")
+		if err != nil {
+			t.Fatalf("error registering user: %v", err)
+		}
+
+		user, err := service.GetUserByID(ctx, id)
+		if err != nil {
+			t.Fatalf("error getting user: %v", err)
+		}
+		if user.Email != "ada@example.com" {
+			t.Errorf("expected normalised email 'ada@example.com', got '%s'", user.Email)
+		}
+		if user.Role != models.RoleUser {
+			t.Errorf("expected registration to ignore requested role, got %d", user.Role)
+		}
+		if user.PasswordHash == "" || user.PasswordHash == "correct horse" {
+			t.Errorf("expected password to be stored hashed")
+		}
+	})
+
+	t.Run("RegisterUser_AdminEmail", func(t *testing.T) {
+		bootstrap := NewUserService(mocks.NewMockUserRepository(), WithAdminEmail(" Ops@Example.com "))
+		id, err := bootstrap.RegisterUser(ctx, models.User{Name: "Ops", Email: "OPS@example.com"}, "correct horse")
+		if err != nil {
+			t.Fatalf("error registering user: %v", err)
+		}
+		if user, _ := bootstrap.GetUserByID(ctx, id); user.Role != models.RoleAdmin {
+			t.Errorf("expected the admin email to register an admin, got role %d", user.Role)
+		}
+
+		id, _ = bootstrap.RegisterUser(ctx, models.User{Name: "Grace", Email: "grace@example.com"}, "correct horse")
+		if user, _ := bootstrap.GetUserByID(ctx, id); user.Role != models.RoleUser {
+			t.Errorf("expected other emails to register users, got role %d", user.Role)
+		}
+	})
+
+	t.Run("RegisterUser_DuplicateEmail", func(t *testing.T) {
+		_, err := service.RegisterUser(ctx, models.User{Name: "Other", Email: "ada@example.com"}, "correct horse")
+		if !errors.Is(err, ErrEmailTaken) {
+			t.Errorf("expected ErrEmailTaken, got %v", err)
+		}
+	})
+
+	t.Run("RegisterUser_Invalid", func(t *testing.T) {
+		_, err := service.RegisterUser(ctx, models.User{Name: "", Email: "nobody@example.com"}, "correct horse")
+		if !errors.Is(err, ErrInvalidUser) {
+			t.Errorf("expected ErrInvalidUser for empty name, got %v", err)
+		}
+
+		_, err = service.RegisterUser(ctx, models.User{Name: "Nobody", Email: "not-an-email"}, "correct horse")
+		if !errors.Is(err, ErrInvalidUser) {
+			t.Errorf("expected ErrInvalidUser for invalid email, got %v", err)
+		}
+
+		_, err = service.RegisterUser(ctx, models.User{Name: "Nobody", Email: "nobody@example.com"}, "short")
+		if !errors.Is(err, ErrInvalidPassword) {
+			t.Errorf("expected ErrInvalidPassword for short password, got %v", err)
+		}
+	})
+
+	t.Run("GetCurrentUser", func(t *testing.T) {
+		user, err := service.GetCurrentUser(userCtx)
+		if err != nil {
+			t.Fatalf("error getting current user: %v", err)
+		}
+		if user.Name != "Ada" {
+			t.Errorf("expected current user 'Ada', got '%s'", user.Name)
+		}
+
+		_, err = service.GetCurrentUser(ctx)
+		if !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+	})
+
+	t.Run("UpdateUser", func(t *testing.T) {
+		err := service.UpdateUser(userCtx, models.User{ID: 1, Name: "Ada Lovelace", Avatar: "http://avatar.example.com/ada.png", Email: "changed@example.com"})
+		if err != nil {
+			t.Fatalf("error updating user: %v", err)
+		}
+
+		user, _ := service.GetUserByID(ctx, 1)
+		if user.Name != "Ada Lovelace" || user.Avatar != "http://avatar.example.com/ada.png" {
+			t.Errorf("unexpected user after update: %+v", user)
+		}
+		if user.Email != "ada@example.com" {
+			t.Errorf("expected email to be unchanged, got '%s'", user.Email)
+		}
+	})
+
+	t.Run("UpdateUser_Permissions", func(t *testing.T) {
+		err := service.UpdateUser(otherCtx, models.User{ID: 1, Name: "Hijacked"})
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden updating another user, got %v", err)
+		}
+
+		err = service.UpdateUser(ctx, models.User{ID: 1, Name: "Anonymous"})
+		if !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+
+		err = service.UpdateUser(adminCtx, models.User{ID: 1, Name: "Ada"})
+		if err != nil {
+			t.Errorf("expected admin to update any user, got %v", err)
+		}
+	})
+
+	t.Run("UpdateUserRole", func(t *testing.T) {
+		err := service.UpdateUserRole(userCtx, 1, models.RoleAdmin)
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for non-admin, got %v", err)
+		}
+
+		err = service.UpdateUserRole(adminCtx, 1, 7)
+		if !errors.Is(err, ErrInvalidRole) {
+			t.Errorf("expected ErrInvalidRole, got %v", err)
+		}
+
+		err = service.UpdateUserRole(adminCtx, 1, models.RoleAdmin)
+		if err != nil {
+			t.Fatalf("error updating role: %v", err)
+		}
+		user, _ := service.GetUserByID(ctx, 1)
+		if !user.IsAdmin() {
+			t.Errorf("expected user to be admin after role change")
+		}
+	})
+
+	t.Run("UpdateUserTeam", func(t *testing.T) {
+		err := service.UpdateUserTeam(userCtx, 1, 1)
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for non-admin, got %v", err)
+		}
+
+		err = service.UpdateUserTeam(adminCtx, 1, 1)
+		if err != nil {
+			t.Fatalf("error updating team: %v", err)
+		}
+		user, _ := service.GetUserByID(ctx, 1)
+		if user.TeamID != 1 {
+			t.Errorf("expected team 1, got %d", user.TeamID)
+		}
+	})
+
+	t.Run("ListUsers", func(t *testing.T) {
+		_, err := service.ListUsers(userCtx)
+		if !errors.Is(err, ErrForbidden) {
+			t.Errorf("expected ErrForbidden for non-admin, got %v", err)
+		}
+
+		_, err = service.ListUsers(ctx)
+		if !errors.Is(err, ErrUnauthenticated) {
+			t.Errorf("expected ErrUnauthenticated, got %v", err)
+		}
+
+		users, err := service.ListUsers(adminCtx)
+		if err != nil {
+			t.Fatalf("error listing users: %v", err)
+		}
+		if len(users) != 1 {
+			t.Errorf("expected 1 user, got %d", len(users))
+		}
+	})
+}
@@ -6,37 +6,6 @@ import (
 	"testing"
 )
 
-func TestRespondWithError(t *testing.T) {
-	tests := []struct {
-		name     string
-		code     int
-		msg      string
-		expected string
-	}{
-		{"Client Error", 400, "Bad Request", `{"error":"Bad Request"}`},
-		{"Server Error", 500, "Internal Server Error", `{"error":"Internal Server Error"}`},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			w := httptest.NewRecorder()
-			RespondWithError(w, tt.code, tt.msg)
-
-			if w.Code != tt.code {
-				t.Errorf("expected status code %d, got %d", tt.code, w.Code)
-			}
-
-			if w.Body.String() != tt.expected {
-				t.Errorf("expected body %q, got %q", tt.expected, w.Body.String())
-			}
-
-			if w.Header().Get("Content-Type") != "application/json" {
-				t.Errorf("expected Content-Type to be application/json")
-			}
-		})
-	}
-}
-
 func TestRespondWithJSON(t *testing.T) {
 	tests := []struct {
 		name     string
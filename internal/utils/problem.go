@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// Error kinds shared by every layer. An error that wraps one of them is mapped
+// to an HTTP status by ProblemStatus; any other error is an internal failure.
+var (
+	ErrBadRequest           = errors.New("bad request")
+	ErrNotFound             = errors.New("not found")
+	ErrConflict             = errors.New("conflict")
+	ErrValidation           = errors.New("validation failed")
+	ErrUnauthorized         = errors.New("authentication required")
+	ErrForbidden            = errors.New("insufficient permissions")
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrUnsupportedMediaType = errors.New("unsupported media type")
+)
+
+var problemStatuses = []struct {
+	kind   error
+	status int
+}{
+	{ErrBadRequest, http.StatusBadRequest},
+	{ErrNotFound, http.StatusNotFound},
+	{ErrConflict, http.StatusConflict},
+	{ErrValidation, http.StatusUnprocessableEntity},
+	{ErrUnauthorized, http.StatusUnauthorized},
+	{ErrForbidden, http.StatusForbidden},
+	{ErrPreconditionFailed, http.StatusPreconditionFailed},
+	{ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+}
+
+// Error is an error of a given kind carrying a message that is safe to show
+// to clients.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func NewError(kind error, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// Problem is an RFC 7807 problem details object.
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	// Errors lists the invalid fields of a ValidationError.
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+func ProblemStatus(err error) int {
+	for _, p := range problemStatuses {
+		if errors.Is(err, p.kind) {
+			return p.status
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// RespondWithProblem writes err as an application/problem+json response with
+// the status its kind maps to. Internal errors are logged and their details
+// withheld from the client.
+func RespondWithProblem(w http.ResponseWriter, r *http.Request, err error) {
+	status := ProblemStatus(err)
+	problem := Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Instance: r.URL.Path,
+	}
+	if status == http.StatusInternalServerError {
+		log.Printf("Responding with 5XX error: %s %s: %v", r.Method, r.URL.Path, err)
+	} else {
+		problem.Detail = err.Error()
+	}
+	var validation *ValidationError
+	if errors.As(err, &validation) {
+		problem.Detail = "One or more fields are invalid"
+		problem.Errors = validation.Fields
+	}
+	if status == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+
+	dat, err := json.Marshal(problem)
+	if err != nil {
+		log.Printf("Error marshalling JSON: %s", err)
+		w.WriteHeader(500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	w.Write(dat)
+}
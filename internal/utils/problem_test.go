@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestProblemStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected int
+	}{
+		{"NotFound", ErrNotFound, http.StatusNotFound},
+		{"Conflict", NewError(ErrConflict, "email already registered"), http.StatusConflict},
+		{"Validation", ErrValidation, http.StatusUnprocessableEntity},
+		{"BadRequest", ErrBadRequest, http.StatusBadRequest},
+		{"Unauthorized", ErrUnauthorized, http.StatusUnauthorized},
+		{"Forbidden", ErrForbidden, http.StatusForbidden},
+		{"PreconditionFailed", ErrPreconditionFailed, http.StatusPreconditionFailed},
+		{"UnsupportedMediaType", ErrUnsupportedMediaType, http.StatusUnsupportedMediaType},
+		{"Wrapped", fmt.Errorf("loading API: %w", NewError(ErrNotFound, "API not found")), http.StatusNotFound},
+		{"Unknown", errors.New("disk full"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ProblemStatus(tt.err); got != tt.expected {
+				t.Errorf("expected status %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestRespondWithProblem(t *testing.T) {
+	t.Run("ClientError", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/api/v1/apis/7", nil)
+		RespondWithProblem(w, r, NewError(ErrNotFound, "API not found"))
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("expected status code %d, got %d", http.StatusNotFound, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("expected Content-Type application/problem+json, got %q", ct)
+		}
+
+		var problem Problem
+		if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+			t.Fatalf("invalid problem body: %v", err)
+		}
+		expected := Problem{Type: "about:blank", Title: "Not Found", Status: 404, Detail: "API not found", Instance: "/api/v1/apis/7"}
+		if !reflect.DeepEqual(problem, expected) {
+			t.Errorf("expected %+v, got %+v", expected, problem)
+		}
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		RespondWithProblem(w, r, ErrUnauthorized)
+
+		if w.Header().Get("WWW-Authenticate") != "Bearer" {
+			t.Errorf("expected WWW-Authenticate challenge on 401")
+		}
+	})
+
+	t.Run("InternalErrorHidesDetail", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		RespondWithProblem(w, r, errors.New("database is locked"))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("expected status code %d, got %d", http.StatusInternalServerError, w.Code)
+		}
+		if strings.Contains(w.Body.String(), "locked") {
+			t.Errorf("expected internal error detail to be withheld, got %s", w.Body.String())
+		}
+	})
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+
+ALTER TABLE users ADD COLUMN team TEXT;
+
+CREATE INDEX idx_users_team ON users(team);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_users_team;
+ALTER TABLE users DROP COLUMN team;
@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- The catalog search index. FTS4 is used rather than FTS5 because the SQLite
+-- bundled with go-sqlite3 only includes FTS5 behind the sqlite_fts5 build tag.
+-- Rows share their docid with apis.id and are maintained by the triggers below.
+CREATE VIRTUAL TABLE apis_fts USING fts4(name, description, tags, team, tokenize=unicode61);
+
+INSERT INTO apis_fts (docid, name, description, tags, team)
+SELECT id, name, description, tags, team FROM apis;
+
+CREATE TRIGGER apis_fts_insert AFTER INSERT ON apis BEGIN
+    INSERT INTO apis_fts (docid, name, description, tags, team)
+    VALUES (new.id, new.name, new.description, new.tags, new.team);
+END;
+
+CREATE TRIGGER apis_fts_update AFTER UPDATE OF name, description, tags, team ON apis BEGIN
+    UPDATE apis_fts
+    SET name = new.name, description = new.description, tags = new.tags, team = new.team
+    WHERE docid = old.id;
+END;
+
+CREATE TRIGGER apis_fts_delete AFTER DELETE ON apis BEGIN
+    DELETE FROM apis_fts WHERE docid = old.id;
+END;
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS apis_fts_delete;
+DROP TRIGGER IF EXISTS apis_fts_update;
+DROP TRIGGER IF EXISTS apis_fts_insert;
+DROP TABLE IF EXISTS apis_fts;
@@ -0,0 +1,28 @@
+-- +goose Up
+
+-- Every write to apis records the resulting row as a JSON snapshot keyed by
+-- column name; a delete records the row as it was just before. There is no
+-- foreign key on api_id or actor_id so that history outlives both the API and
+-- the user who made the change.
+CREATE TABLE api_revisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    api_id INTEGER NOT NULL,
+    revision INTEGER NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('create', 'update', 'delete', 'restore')),
+    actor_id INTEGER,
+    snapshot TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (api_id, revision)
+);
+
+INSERT INTO api_revisions (api_id, revision, action, snapshot)
+SELECT id, 1, 'create', json_object(
+    'id', id, 'name', name, 'version', version, 'description', description,
+    'documentation_link', documentation_link, 'forum_reference', forum_reference,
+    'tags', tags, 'swagger', swagger, 'apm_link', apm_link, 'team', team,
+    'created_at', created_at, 'updated_at', updated_at)
+FROM apis;
+
+-- +goose Down
+
+DROP TABLE IF EXISTS api_revisions;
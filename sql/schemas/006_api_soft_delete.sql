@@ -0,0 +1,13 @@
+-- +goose Up
+
+-- Deleted APIs stay in the table, with their category mappings, until they
+-- are purged after the trash retention period.
+ALTER TABLE apis ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_apis_deleted_at ON apis(deleted_at);
+
+-- +goose Down
+
+DELETE FROM apis WHERE deleted_at IS NOT NULL;
+DROP INDEX IF EXISTS idx_apis_deleted_at;
+ALTER TABLE apis DROP COLUMN deleted_at;
@@ -0,0 +1,27 @@
+-- +goose Up
+
+-- Each API owns any number of versions. The version string on apis stays as
+-- the catalog entry's headline version; the versions below carry their own
+-- documents and lifecycle.
+CREATE TABLE api_versions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    api_id INTEGER NOT NULL,
+    version TEXT NOT NULL,
+    swagger TEXT,
+    documentation_link TEXT,
+    status TEXT NOT NULL DEFAULT 'stable' CHECK (status IN ('experimental', 'stable', 'deprecated', 'retired')),
+    released_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (api_id, version),
+    FOREIGN KEY (api_id) REFERENCES apis(id) ON DELETE CASCADE
+);
+
+INSERT INTO api_versions (api_id, version, swagger, documentation_link, created_at, updated_at)
+SELECT id, version, swagger, documentation_link, created_at, updated_at
+FROM apis
+WHERE version IS NOT NULL AND version != '';
+
+-- +goose Down
+
+DROP TABLE IF EXISTS api_versions;
@@ -0,0 +1,15 @@
+-- +goose Up
+
+ALTER TABLE apis ADD COLUMN lifecycle TEXT NOT NULL DEFAULT 'stable'
+    CHECK (lifecycle IN ('experimental', 'stable', 'deprecated', 'retired'));
+ALTER TABLE apis ADD COLUMN deprecated_at TIMESTAMP;
+ALTER TABLE apis ADD COLUMN sunset_at TIMESTAMP;
+
+CREATE INDEX idx_apis_lifecycle ON apis(lifecycle);
+
+-- +goose Down
+
+DROP INDEX IF EXISTS idx_apis_lifecycle;
+ALTER TABLE apis DROP COLUMN sunset_at;
+ALTER TABLE apis DROP COLUMN deprecated_at;
+ALTER TABLE apis DROP COLUMN lifecycle;
@@ -0,0 +1,111 @@
+-- +goose Up
+
+-- Tags are shared between APIs and compared case-insensitively, so "Public"
+-- and "public" are the same tag.
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE api_tags (
+    api_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    PRIMARY KEY (api_id, tag_id),
+    FOREIGN KEY (api_id) REFERENCES apis(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_tags_tag_id ON api_tags(tag_id);
+
+-- Split the comma-separated apis.tags into rows, trimming each tag and
+-- dropping blanks. Where APIs spell a tag differently, the oldest API's
+-- first spelling is kept.
+CREATE TEMP TABLE split_tags AS
+WITH RECURSIVE split(api_id, position, tag, rest) AS (
+    SELECT id, 0, '', COALESCE(tags, '') || ',' FROM apis
+    UNION ALL
+    SELECT api_id, position + 1, TRIM(substr(rest, 1, instr(rest, ',') - 1)), substr(rest, instr(rest, ',') + 1)
+    FROM split WHERE rest != ''
+)
+SELECT api_id, position, tag FROM split WHERE tag != '';
+
+INSERT OR IGNORE INTO tags (name) SELECT tag FROM split_tags ORDER BY api_id, position;
+
+INSERT OR IGNORE INTO api_tags (api_id, tag_id)
+SELECT s.api_id, t.id FROM split_tags s JOIN tags t ON t.name = s.tag;
+
+DROP TABLE split_tags;
+
+-- The search index keeps a space-separated copy of each API's tags, now
+-- maintained from api_tags and tags instead of apis.tags.
+DROP TRIGGER apis_fts_insert;
+DROP TRIGGER apis_fts_update;
+
+ALTER TABLE apis DROP COLUMN tags;
+
+CREATE TRIGGER apis_fts_insert AFTER INSERT ON apis BEGIN
+    INSERT INTO apis_fts (docid, name, description, tags, team)
+    VALUES (new.id, new.name, new.description, '', new.team);
+END;
+
+CREATE TRIGGER apis_fts_update AFTER UPDATE OF name, description, team ON apis BEGIN
+    UPDATE apis_fts
+    SET name = new.name, description = new.description, team = new.team
+    WHERE docid = old.id;
+END;
+
+CREATE TRIGGER apis_fts_tag_insert AFTER INSERT ON api_tags BEGIN
+    UPDATE apis_fts SET tags = (
+        SELECT COALESCE(group_concat(t.name, ' '), '') FROM api_tags m JOIN tags t ON t.id = m.tag_id
+        WHERE m.api_id = new.api_id
+    ) WHERE docid = new.api_id;
+END;
+
+CREATE TRIGGER apis_fts_tag_delete AFTER DELETE ON api_tags BEGIN
+    UPDATE apis_fts SET tags = (
+        SELECT COALESCE(group_concat(t.name, ' '), '') FROM api_tags m JOIN tags t ON t.id = m.tag_id
+        WHERE m.api_id = old.api_id
+    ) WHERE docid = old.api_id;
+END;
+
+CREATE TRIGGER apis_fts_tag_rename AFTER UPDATE OF name ON tags BEGIN
+    UPDATE apis_fts SET tags = (
+        SELECT COALESCE(group_concat(t.name, ' '), '') FROM api_tags m JOIN tags t ON t.id = m.tag_id
+        WHERE m.api_id = apis_fts.docid
+    ) WHERE docid IN (SELECT api_id FROM api_tags WHERE tag_id = new.id);
+END;
+
+UPDATE apis_fts SET tags = (
+    SELECT COALESCE(group_concat(t.name, ' '), '') FROM api_tags m JOIN tags t ON t.id = m.tag_id
+    WHERE m.api_id = apis_fts.docid
+);
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS apis_fts_tag_rename;
+DROP TRIGGER IF EXISTS apis_fts_tag_delete;
+DROP TRIGGER IF EXISTS apis_fts_tag_insert;
+DROP TRIGGER IF EXISTS apis_fts_update;
+DROP TRIGGER IF EXISTS apis_fts_insert;
+
+ALTER TABLE apis ADD COLUMN tags TEXT;
+
+UPDATE apis SET tags = (
+    SELECT group_concat(t.name, ',') FROM api_tags m JOIN tags t ON t.id = m.tag_id
+    WHERE m.api_id = apis.id
+);
+
+CREATE TRIGGER apis_fts_insert AFTER INSERT ON apis BEGIN
+    INSERT INTO apis_fts (docid, name, description, tags, team)
+    VALUES (new.id, new.name, new.description, new.tags, new.team);
+END;
+
+CREATE TRIGGER apis_fts_update AFTER UPDATE OF name, description, tags, team ON apis BEGIN
+    UPDATE apis_fts
+    SET name = new.name, description = new.description, tags = new.tags, team = new.team
+    WHERE docid = old.id;
+END;
+
+DROP TABLE IF EXISTS api_tags;
+DROP TABLE IF EXISTS tags;
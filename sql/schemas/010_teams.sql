@@ -0,0 +1,99 @@
+-- +goose Up
+
+-- Teams own APIs and have users as members. A user belongs to at most one
+-- team. Names compare case-insensitively so that "Payments" and "payments"
+-- cannot become two teams.
+CREATE TABLE teams (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    description TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create a team for every name in use, keeping the spelling of the oldest API
+-- or, failing that, the oldest user that has it.
+INSERT INTO teams (name)
+SELECT name FROM (
+    SELECT name, MIN(rank) AS first FROM (
+        SELECT TRIM(team) AS name, id AS rank FROM apis WHERE TRIM(team) != ''
+        UNION ALL
+        SELECT TRIM(team), (1 << 40) + id FROM users WHERE TRIM(team) != ''
+    )
+    GROUP BY name COLLATE NOCASE
+)
+ORDER BY first;
+
+ALTER TABLE apis ADD COLUMN team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN team_id INTEGER REFERENCES teams(id) ON DELETE SET NULL;
+
+UPDATE apis SET team_id = (SELECT id FROM teams WHERE name = TRIM(apis.team));
+UPDATE users SET team_id = (SELECT id FROM teams WHERE name = TRIM(users.team));
+
+-- Snapshots are keyed by column, so earlier revisions refer to the team by
+-- its new ID too.
+UPDATE api_revisions SET snapshot = json_set(json_remove(snapshot, '$.team'), '$.team_id', (
+    SELECT id FROM teams WHERE name = TRIM(json_extract(api_revisions.snapshot, '$.team'))
+));
+
+-- The search index keeps the team name, now looked up through team_id.
+DROP TRIGGER apis_fts_insert;
+DROP TRIGGER apis_fts_update;
+DROP INDEX idx_users_team;
+
+ALTER TABLE apis DROP COLUMN team;
+ALTER TABLE users DROP COLUMN team;
+
+CREATE INDEX idx_apis_team_id ON apis(team_id);
+CREATE INDEX idx_users_team_id ON users(team_id);
+
+CREATE TRIGGER apis_fts_insert AFTER INSERT ON apis BEGIN
+    INSERT INTO apis_fts (docid, name, description, tags, team)
+    VALUES (new.id, new.name, new.description, '', (SELECT name FROM teams WHERE id = new.team_id));
+END;
+
+CREATE TRIGGER apis_fts_update AFTER UPDATE OF name, description, team_id ON apis BEGIN
+    UPDATE apis_fts
+    SET name = new.name, description = new.description, team = (SELECT name FROM teams WHERE id = new.team_id)
+    WHERE docid = old.id;
+END;
+
+CREATE TRIGGER apis_fts_team_rename AFTER UPDATE OF name ON teams BEGIN
+    UPDATE apis_fts SET team = new.name WHERE docid IN (SELECT id FROM apis WHERE team_id = new.id);
+END;
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS apis_fts_team_rename;
+DROP TRIGGER IF EXISTS apis_fts_update;
+DROP TRIGGER IF EXISTS apis_fts_insert;
+DROP INDEX IF EXISTS idx_users_team_id;
+DROP INDEX IF EXISTS idx_apis_team_id;
+
+ALTER TABLE apis ADD COLUMN team TEXT;
+ALTER TABLE users ADD COLUMN team TEXT;
+
+UPDATE apis SET team = (SELECT name FROM teams WHERE id = apis.team_id);
+UPDATE users SET team = (SELECT name FROM teams WHERE id = users.team_id);
+
+UPDATE api_revisions SET snapshot = json_set(json_remove(snapshot, '$.team_id'), '$.team', (
+    SELECT name FROM teams WHERE id = json_extract(api_revisions.snapshot, '$.team_id')
+));
+
+ALTER TABLE apis DROP COLUMN team_id;
+ALTER TABLE users DROP COLUMN team_id;
+
+CREATE INDEX idx_users_team ON users(team);
+
+CREATE TRIGGER apis_fts_insert AFTER INSERT ON apis BEGIN
+    INSERT INTO apis_fts (docid, name, description, tags, team)
+    VALUES (new.id, new.name, new.description, '', new.team);
+END;
+
+CREATE TRIGGER apis_fts_update AFTER UPDATE OF name, description, team ON apis BEGIN
+    UPDATE apis_fts
+    SET name = new.name, description = new.description, team = new.team
+    WHERE docid = old.id;
+END;
+
+DROP TABLE IF EXISTS teams;
@@ -0,0 +1,20 @@
+-- +goose Up
+
+-- An edge of the dependency graph: the API api_id consumes depends_on_id.
+-- Edges leave the graph while either end is in the trash and go with it when
+-- it is purged.
+CREATE TABLE api_dependencies (
+    api_id INTEGER NOT NULL,
+    depends_on_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (api_id, depends_on_id),
+    CHECK (api_id != depends_on_id),
+    FOREIGN KEY (api_id) REFERENCES apis(id) ON DELETE CASCADE,
+    FOREIGN KEY (depends_on_id) REFERENCES apis(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_api_dependencies_depends_on_id ON api_dependencies(depends_on_id);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS api_dependencies;
@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- row_version counts the writes to an API. The repository increments it
+-- whenever the API or its tags change, and clients see it as the ETag.
+ALTER TABLE apis ADD COLUMN row_version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+
+ALTER TABLE apis DROP COLUMN row_version;
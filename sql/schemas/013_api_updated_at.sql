@@ -0,0 +1,14 @@
+-- +goose Up
+
+-- updated_at is maintained here rather than by each statement that writes to
+-- apis, so that no write can leave it stale or set it to a client's value.
+-- A statement that sets updated_at itself, such as a data fix, keeps its value.
+CREATE TRIGGER apis_updated_at AFTER UPDATE ON apis
+WHEN new.updated_at IS old.updated_at
+BEGIN
+    UPDATE apis SET updated_at = CURRENT_TIMESTAMP WHERE id = new.id;
+END;
+
+-- +goose Down
+
+DROP TRIGGER IF EXISTS apis_updated_at;
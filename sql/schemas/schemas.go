@@ -0,0 +1,6 @@
+package schemas
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS